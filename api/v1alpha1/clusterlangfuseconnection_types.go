@@ -0,0 +1,66 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ClusterLangfuseConnectionSpec defines the desired state of ClusterLangfuseConnection
+type ClusterLangfuseConnectionSpec struct {
+	LangfuseConnectionSpec `json:",inline"`
+
+	// NamespaceSelector selects the namespaces whose resources may use the
+	// connection. Resources in a selected namespace without a connectionRef
+	// use it too. An empty selector selects all namespaces.
+	// +required
+	NamespaceSelector metav1.LabelSelector `json:"namespaceSelector"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+
+// ClusterLangfuseConnection is the Schema for the clusterlangfuseconnections API
+type ClusterLangfuseConnection struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitzero"`
+
+	// spec defines the desired state of ClusterLangfuseConnection
+	// +required
+	Spec ClusterLangfuseConnectionSpec `json:"spec"`
+
+	// status defines the observed state of ClusterLangfuseConnection
+	// +optional
+	Status LangfuseConnectionStatus `json:"status,omitzero"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterLangfuseConnectionList contains a list of ClusterLangfuseConnection
+type ClusterLangfuseConnectionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitzero"`
+	Items           []ClusterLangfuseConnection `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterLangfuseConnection{}, &ClusterLangfuseConnectionList{})
+}
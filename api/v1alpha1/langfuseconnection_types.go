@@ -0,0 +1,156 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// LangfuseConnectionSpec defines the desired state of LangfuseConnection
+// +kubebuilder:validation:XValidation:rule="has(self.host) != has(self.serverRef)",message="exactly one of host and serverRef must be set"
+type LangfuseConnectionSpec struct {
+	// Host is the base URL of the Langfuse instance, e.g. https://eu.cloud.langfuse.com.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^https?://`
+	Host string `json:"host,omitempty"`
+
+	// ServerRef selects a LangfuseServer whose in-cluster endpoint is used
+	// instead of Host. It is read from the same namespace as the credentials
+	// Secret.
+	// +optional
+	ServerRef *corev1.LocalObjectReference `json:"serverRef,omitempty"`
+
+	// CredentialsSecretRef references the Secret that holds the organization
+	// API keys of the instance. Secrets are read from the namespace of a
+	// LangfuseConnection, or from the operator's namespace for a
+	// ClusterLangfuseConnection.
+	// +required
+	CredentialsSecretRef CredentialsSecretReference `json:"credentialsSecretRef"`
+
+	// TLS configures how the server certificate is verified and which client
+	// certificate is presented.
+	// +optional
+	TLS *ConnectionTLS `json:"tls,omitempty"`
+}
+
+// CredentialsSecretReference selects the keys of a Secret that hold a
+// Langfuse key pair.
+type CredentialsSecretReference struct {
+	// Name of the Secret.
+	// +required
+	Name string `json:"name"`
+
+	// PublicKeyKey is the key of the public API key in the Secret.
+	// +optional
+	// +kubebuilder:default=LANGFUSE_PUBLIC_KEY
+	PublicKeyKey string `json:"publicKeyKey,omitempty"`
+
+	// SecretKeyKey is the key of the secret API key in the Secret.
+	// +optional
+	// +kubebuilder:default=LANGFUSE_SECRET_KEY
+	SecretKeyKey string `json:"secretKeyKey,omitempty"`
+}
+
+// ConnectionTLS configures TLS for a LangfuseConnection. Secrets are read
+// from the same namespace as the credentials Secret.
+type ConnectionTLS struct {
+	// CASecretRef selects a PEM bundle of CAs trusted in addition to the
+	// system pool, for instances behind a private PKI.
+	// +optional
+	CASecretRef *corev1.SecretKeySelector `json:"caSecretRef,omitempty"`
+
+	// ClientCertSecretRef references a kubernetes.io/tls Secret whose
+	// certificate and key are presented for mutual TLS.
+	// +optional
+	ClientCertSecretRef *corev1.LocalObjectReference `json:"clientCertSecretRef,omitempty"`
+
+	// InsecureSkipVerify disables server certificate verification. Only
+	// use it in development.
+	// +optional
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+}
+
+// Kinds of connection a ConnectionReference can select.
+const (
+	LangfuseConnectionKind        = "LangfuseConnection"
+	ClusterLangfuseConnectionKind = "ClusterLangfuseConnection"
+)
+
+// ConnectionReference selects the Langfuse instance a resource is managed
+// in. Resources without one use the ClusterLangfuseConnection that selects
+// their namespace, if any, or else the instance the operator is configured
+// with.
+type ConnectionReference struct {
+	// Kind of the connection.
+	// +optional
+	// +kubebuilder:validation:Enum=LangfuseConnection;ClusterLangfuseConnection
+	// +kubebuilder:default=LangfuseConnection
+	Kind string `json:"kind,omitempty"`
+
+	// Name of the LangfuseConnection in the namespace of the resource, or
+	// of the ClusterLangfuseConnection.
+	// +required
+	Name string `json:"name"`
+}
+
+// LangfuseConnectionStatus defines the observed state of LangfuseConnection.
+type LangfuseConnectionStatus struct {
+	// Version is the Langfuse server version reported by the instance.
+	// +optional
+	Version string `json:"version,omitempty"`
+
+	// conditions represent the current state of the LangfuseConnection resource.
+	// "Ready" is True while the instance is reachable and accepts the credentials.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// LangfuseConnection is the Schema for the langfuseconnections API
+type LangfuseConnection struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitzero"`
+
+	// spec defines the desired state of LangfuseConnection
+	// +required
+	Spec LangfuseConnectionSpec `json:"spec"`
+
+	// status defines the observed state of LangfuseConnection
+	// +optional
+	Status LangfuseConnectionStatus `json:"status,omitzero"`
+}
+
+// +kubebuilder:object:root=true
+
+// LangfuseConnectionList contains a list of LangfuseConnection
+type LangfuseConnectionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitzero"`
+	Items           []LangfuseConnection `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&LangfuseConnection{}, &LangfuseConnectionList{})
+}
@@ -25,22 +25,43 @@ import (
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
 // LangfuseLlmConnectionSpec defines the desired state of LangfuseLlmConnection
+// +kubebuilder:validation:XValidation:rule="has(self.adapter) || self.provider in ['anthropic', 'openai', 'azure', 'bedrock', 'google-vertex-ai', 'google-ai-studio']",message="adapter is required unless provider is the name of an adapter"
 type LangfuseLlmConnectionSpec struct {
 	// ProjectRef is the name of the LangfuseProject CR this connection belongs to.
 	// +required
 	ProjectRef string `json:"projectRef"`
 
-	// Provider is the LLM provider (e.g., openai, anthropic).
+	// Provider is the LLM provider (e.g., openai, anthropic). Langfuse
+	// keeps one connection per provider and project.
 	// +required
 	Provider string `json:"provider"`
 
-	// SecretRef references a K8s Secret containing the API key/credentials.
+	// Adapter is the API Langfuse calls the provider with. It defaults to
+	// the provider, which must then be one of the adapters.
+	// +optional
+	// +kubebuilder:validation:Enum=anthropic;openai;azure;bedrock;google-vertex-ai;google-ai-studio
+	Adapter string `json:"adapter,omitempty"`
+
+	// SecretRef references the Secret that holds the provider's API key.
+	// The Secret is read from the namespace of the LangfuseLlmConnection;
+	// namespace must be empty or that namespace.
 	// +required
 	SecretRef corev1.SecretReference `json:"secretRef"`
+
+	// SecretKey is the key of the API key in the Secret.
+	// +optional
+	// +kubebuilder:default=apiKey
+	SecretKey string `json:"secretKey,omitempty"`
 }
 
 // LangfuseLlmConnectionStatus defines the observed state of LangfuseLlmConnection.
 type LangfuseLlmConnectionStatus struct {
+	// ID is the Langfuse ID of the LLM connection this
+	// LangfuseLlmConnection created. A connection of the same provider with
+	// another ID is not replaced.
+	// +optional
+	ID string `json:"id,omitempty"`
+
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
 
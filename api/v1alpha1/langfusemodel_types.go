@@ -29,6 +29,12 @@ type LangfuseModelSpec struct {
 	// +required
 	ModelName string `json:"modelName"`
 
+	// ConnectionRef selects the Langfuse instance the model is managed in.
+	// Defaults to the ClusterLangfuseConnection that selects the namespace,
+	// if any, or else the instance the operator is configured with.
+	// +optional
+	ConnectionRef *ConnectionReference `json:"connectionRef,omitempty"`
+
 	// MatchPattern is a regex pattern to match model names.
 	// +required
 	MatchPattern string `json:"matchPattern"`
@@ -64,6 +70,18 @@ type LangfuseModelSpec struct {
 
 // LangfuseModelStatus defines the observed state of LangfuseModel.
 type LangfuseModelStatus struct {
+	// ID is the Langfuse ID of the model this LangfuseModel created or
+	// adopted. Later reconciles look the model up by this ID.
+	// +optional
+	ID string `json:"id,omitempty"`
+
+	// Connection is the connection the model was created or adopted in,
+	// like the connection of a LangfuseProject. The model stays in this
+	// instance even if connectionRef or the ClusterLangfuseConnections
+	// selecting the namespace change.
+	// +optional
+	Connection string `json:"connection,omitempty"`
+
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
 
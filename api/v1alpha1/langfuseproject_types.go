@@ -28,18 +28,116 @@ type LangfuseProjectSpec struct {
 	// Name is the name of the project in Langfuse.
 	// +required
 	Name string `json:"name"`
+
+	// ProjectID is the ID of an existing Langfuse project to manage, e.g.
+	// one created in the UI, instead of creating a new one. The project is
+	// renamed to Name. It cannot be changed or removed once set, nor added
+	// once the LangfuseProject has created a project.
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	ProjectID string `json:"projectID,omitempty"`
+
+	// RetentionDays is how many days Langfuse keeps the traces and other
+	// data of the project. 0 keeps them forever. Values other than 0
+	// require the data-retention entitlement. The retention is not managed
+	// if unset; removing the field leaves the retention in Langfuse as it
+	// is, so set it to 0 first to remove the retention.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:XValidation:rule="self == 0 || self >= 3",message="retentionDays must be 0 or at least 3"
+	RetentionDays *int32 `json:"retentionDays,omitempty"`
+
+	// Metadata is the metadata of the project. The operator adds the keys
+	// it marks the project with. The metadata is not managed if unset.
+	// +optional
+	// +kubebuilder:validation:XValidation:rule="!('managedBy' in self) && !('owner' in self)",message="managedBy and owner are reserved for the operator"
+	Metadata map[string]string `json:"metadata,omitempty"`
+
+	// ConnectionRef selects the Langfuse instance the project is managed in.
+	// Defaults to the ClusterLangfuseConnection that selects the namespace,
+	// if any, or else the instance the operator is configured with.
+	// +optional
+	ConnectionRef *ConnectionReference `json:"connectionRef,omitempty"`
+
+	// DeletionPolicy decides what happens to the project in Langfuse when
+	// the LangfuseProject is deleted.
+	// +optional
+	// +kubebuilder:default=Retain
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+
+	// RemoteDeletionPolicy decides what happens when the project is deleted
+	// in Langfuse while the LangfuseProject still exists.
+	// +optional
+	// +kubebuilder:default=Report
+	RemoteDeletionPolicy RemoteDeletionPolicy `json:"remoteDeletionPolicy,omitempty"`
 }
 
+// DeletionPolicy decides what happens to a Langfuse object when the
+// resource that manages it is deleted.
+// +kubebuilder:validation:Enum=Delete;Retain;Orphan
+type DeletionPolicy string
+
+const (
+	// DeletionPolicyDelete deletes the object, with all of its data, once
+	// no other resource refers to the deleted one. If the connection of the
+	// resource is gone, the object is kept and a Warning event is emitted.
+	DeletionPolicyDelete DeletionPolicy = "Delete"
+	// DeletionPolicyRetain keeps the object without calling Langfuse, so
+	// credentials the operator created for itself stay valid. A new
+	// resource of the same name adopts the object.
+	DeletionPolicyRetain DeletionPolicy = "Retain"
+	// DeletionPolicyOrphan keeps the object but hands it back: the owner is
+	// removed from its ownership marker, so that only a resource that
+	// adopts it by ID manages it again, and credentials the operator created
+	// for itself are revoked. If the connection of the resource is gone, the
+	// object is kept as is and a Warning event is emitted.
+	DeletionPolicyOrphan DeletionPolicy = "Orphan"
+)
+
+// RemoteDeletionPolicy decides what happens when a managed object is
+// deleted in Langfuse, e.g. in the UI, instead of through its resource.
+// +kubebuilder:validation:Enum=Recreate;Report
+type RemoteDeletionPolicy string
+
+const (
+	// RemoteDeletionPolicyRecreate creates the object again. Its data, and
+	// the objects that belonged to it, are not restored. Objects adopted by
+	// ID are never recreated.
+	RemoteDeletionPolicyRecreate RemoteDeletionPolicy = "Recreate"
+	// RemoteDeletionPolicyReport only reports the deletion in the Synced
+	// condition, so that a person can decide what to do.
+	RemoteDeletionPolicyReport RemoteDeletionPolicy = "Report"
+)
+
 // LangfuseProjectStatus defines the observed state of LangfuseProject.
 type LangfuseProjectStatus struct {
 	// ID is the unique identifier of the project in Langfuse.
 	// +optional
 	ID string `json:"id,omitempty"`
 
+	// Connection is the connection the project was created or adopted in:
+	// LangfuseConnection/<name>, ClusterLangfuseConnection/<name>, or
+	// default for the instance the operator is configured with. The project
+	// and its resources stay in this instance even if connectionRef or the
+	// ClusterLangfuseConnections selecting the namespace change.
+	// +optional
+	Connection string `json:"connection,omitempty"`
+
 	// State represents the current state of the project (e.g., Ready, Error).
 	// +optional
 	State string `json:"state,omitempty"`
 
+	// RetentionDays is the data retention of the project as Langfuse last
+	// reported it. Unset if the project has no retention, or if it was
+	// adopted and neither updated nor read with a key of the project.
+	// +optional
+	RetentionDays *int32 `json:"retentionDays,omitempty"`
+
+	// Metadata is the metadata of the project in Langfuse. Values that are
+	// not strings are JSON-encoded.
+	// +optional
+	Metadata map[string]string `json:"metadata,omitempty"`
+
 	// Conditions represent the latest available observations of an object's state
 	// +optional
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
@@ -47,6 +145,8 @@ type LangfuseProjectStatus struct {
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:validation:XValidation:rule="!has(oldSelf.spec.projectID) || has(self.spec.projectID) && self.spec.projectID == oldSelf.spec.projectID",message="projectID is immutable"
+// +kubebuilder:validation:XValidation:rule="has(oldSelf.spec.projectID) || !has(self.spec.projectID) || !has(oldSelf.status) || !has(oldSelf.status.id)",message="projectID cannot be added once the project exists"
 
 // LangfuseProject is the Schema for the langfuseprojects API
 type LangfuseProject struct {
@@ -33,8 +33,10 @@ type LangfuseScoreConfigSpec struct {
 	// +required
 	Name string `json:"name"`
 
-	// DataType is the type of the score (e.g., NUMERIC, BOOLEAN).
+	// DataType is the type of the score (e.g., NUMERIC, BOOLEAN). Langfuse
+	// cannot change the type of a score config, so it is immutable.
 	// +required
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="dataType is immutable"
 	DataType string `json:"dataType"`
 
 	// MinValue is the minimum value for numeric scores.
@@ -52,6 +54,11 @@ type LangfuseScoreConfigSpec struct {
 
 // LangfuseScoreConfigStatus defines the observed state of LangfuseScoreConfig.
 type LangfuseScoreConfigStatus struct {
+	// ID is the Langfuse ID of the score config this LangfuseScoreConfig
+	// created or adopted.
+	// +optional
+	ID string `json:"id,omitempty"`
+
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
 
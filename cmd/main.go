@@ -17,10 +17,12 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"flag"
 	"os"
 	"strings"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -31,6 +33,7 @@ import (
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/config"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
@@ -40,6 +43,7 @@ import (
 	langfusev1alpha1 "github.com/sqaisar/langfuse-controller/api/v1alpha1"
 	"github.com/sqaisar/langfuse-controller/internal/controller"
 	"github.com/sqaisar/langfuse-controller/internal/langfuse"
+	"github.com/sqaisar/langfuse-controller/internal/tracing"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -64,6 +68,26 @@ func main() {
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var langfuseRequestTimeout time.Duration
+	var reconcileTimeout time.Duration
+	var langfuseMaxRetries int
+	var langfuseRateLimit float64
+	var langfuseRateBurst int
+	var langfuseTransport langfuse.TransportOptions
+	var langfusePublicKeyFile, langfuseSecretKeyFile string
+	var langfuseCredentialsReloadInterval time.Duration
+	var langfuseCapabilityProbeInterval time.Duration
+	var langfuseSyncInterval time.Duration
+	var langfuseAuditLog string
+	var langfuseBreakerThreshold int
+	var langfuseReadinessCheck bool
+	var langfuseReadinessCacheTTL, langfuseReadinessTimeout time.Duration
+	var langfuseBreakerProbeInterval time.Duration
+	var langfuseAuditLogMaxSize, langfuseAuditLogMaxBackups int
+	var clusterResourceNamespace string
+	var otlpEndpoint string
+	var otlpInsecure bool
+	var traceSampleRatio float64
 	var tlsOpts []func(*tls.Config)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
@@ -82,6 +106,68 @@ func main() {
 	flag.StringVar(&metricsCertKey, "metrics-cert-key", "tls.key", "The name of the metrics server key file.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.DurationVar(&langfuseRequestTimeout, "langfuse-request-timeout", langfuse.DefaultRequestTimeout,
+		"Timeout for a single HTTP request to the Langfuse API.")
+	flag.IntVar(&langfuseMaxRetries, "langfuse-max-retries", langfuse.DefaultMaxRetries,
+		"Maximum number of retries for transient Langfuse API failures. Set to 0 to disable retries.")
+	flag.Float64Var(&langfuseRateLimit, "langfuse-rate-limit", langfuse.DefaultRateLimit,
+		"Sustained rate of Langfuse API requests per second shared by all controllers. "+
+			"Set to a negative value to disable client-side rate limiting.")
+	flag.IntVar(&langfuseRateBurst, "langfuse-rate-burst", langfuse.DefaultRateBurst,
+		"Maximum number of Langfuse API requests sent in a burst.")
+	flag.DurationVar(&reconcileTimeout, "reconcile-timeout", 2*time.Minute,
+		"Overall deadline for a single Reconcile call, including all Langfuse API requests it makes. "+
+			"Set to 0 to disable.")
+	flag.StringVar(&langfuseTransport.CAFile, "langfuse-ca-file", "",
+		"PEM bundle of additional CAs to trust for the Langfuse API, e.g. mounted from a Secret.")
+	flag.StringVar(&langfuseTransport.CertFile, "langfuse-client-cert-file", "",
+		"PEM client certificate presented to the Langfuse API for mutual TLS.")
+	flag.StringVar(&langfuseTransport.KeyFile, "langfuse-client-key-file", "",
+		"PEM private key of --langfuse-client-cert-file.")
+	flag.BoolVar(&langfuseTransport.InsecureSkipVerify, "langfuse-insecure-skip-verify", false,
+		"If set, the Langfuse API server certificate is not verified. Only use for development.")
+	flag.StringVar(&langfuseTransport.ProxyURL, "langfuse-proxy-url", "",
+		"Proxy for Langfuse API requests. Defaults to HTTPS_PROXY/HTTP_PROXY.")
+	flag.StringVar(&langfuseTransport.NoProxy, "langfuse-no-proxy", "",
+		"Comma-separated hosts, domains and CIDRs that bypass the proxy. Defaults to NO_PROXY.")
+	flag.StringVar(&langfusePublicKeyFile, "langfuse-public-key-file", "",
+		"File containing the Langfuse public key, e.g. from a mounted Secret. Overrides LANGFUSE_PUBLIC_KEY "+
+			"and is reloaded when it changes.")
+	flag.StringVar(&langfuseSecretKeyFile, "langfuse-secret-key-file", "",
+		"File containing the Langfuse secret key. Must be set together with --langfuse-public-key-file.")
+	flag.DurationVar(&langfuseCredentialsReloadInterval, "langfuse-credentials-reload-interval",
+		langfuse.DefaultCredentialsReloadInterval, "How often the Langfuse key files are checked for changes.")
+	flag.DurationVar(&langfuseCapabilityProbeInterval, "langfuse-capability-probe-interval",
+		langfuse.DefaultCapabilityProbeInterval,
+		"How often the Langfuse server version and endpoints are probed again after startup.")
+	flag.DurationVar(&langfuseSyncInterval, "langfuse-sync-interval", 10*time.Minute,
+		"How often managed Langfuse projects are compared with their spec. 0 disables the comparison.")
+	flag.StringVar(&langfuseAuditLog, "langfuse-audit-log", "",
+		"Where to write a JSON audit line for every change made to Langfuse: \"stdout\" or a file path. "+
+			"Disabled when empty.")
+	flag.IntVar(&langfuseAuditLogMaxSize, "langfuse-audit-log-max-size", 100,
+		"Size in megabytes at which the audit log file is rotated.")
+	flag.IntVar(&langfuseAuditLogMaxBackups, "langfuse-audit-log-max-backups", 5,
+		"Number of rotated audit log files to keep.")
+	flag.IntVar(&langfuseBreakerThreshold, "langfuse-circuit-breaker-threshold", langfuse.DefaultBreakerThreshold,
+		"Consecutive failed Langfuse API requests after which reconciles are paused until Langfuse is healthy "+
+			"again. Negative disables the circuit breaker.")
+	flag.DurationVar(&langfuseBreakerProbeInterval, "langfuse-circuit-breaker-probe-interval",
+		langfuse.DefaultBreakerProbeInterval, "How often Langfuse health is checked while the circuit breaker is open.")
+	flag.BoolVar(&langfuseReadinessCheck, "langfuse-readiness-check", true,
+		"If set, the readiness probe fails unless Langfuse is reachable and accepts the credentials.")
+	flag.DurationVar(&langfuseReadinessCacheTTL, "langfuse-readiness-cache-ttl", langfuse.DefaultReadinessCacheTTL,
+		"How long the result of the Langfuse readiness check is reused.")
+	flag.DurationVar(&langfuseReadinessTimeout, "langfuse-readiness-timeout", langfuse.DefaultReadinessTimeout,
+		"Timeout of the Langfuse readiness check.")
+	flag.StringVar(&clusterResourceNamespace, "cluster-resource-namespace", "",
+		"Namespace the Secrets of ClusterLangfuseConnections are read from. Defaults to the namespace the "+
+			"manager runs in.")
+	flag.StringVar(&otlpEndpoint, "otlp-endpoint", "",
+		"host:port of an OTLP/gRPC collector to export traces to. Tracing is disabled when empty.")
+	flag.BoolVar(&otlpInsecure, "otlp-insecure", false, "If set, traces are exported without TLS.")
+	flag.Float64Var(&traceSampleRatio, "trace-sample-ratio", 1,
+		"Fraction of reconciles that are traced, between 0 and 1.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -90,6 +176,16 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Options{
+		Endpoint:    otlpEndpoint,
+		Insecure:    otlpInsecure,
+		SampleRatio: traceSampleRatio,
+	})
+	if err != nil {
+		setupLog.Error(err, "unable to set up tracing")
+		os.Exit(1)
+	}
+
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
 	// due to its vulnerabilities. More specifically, disabling http/2 will
 	// prevent from being vulnerable to the HTTP/2 Stream Cancellation and
@@ -166,6 +262,13 @@ func main() {
 			nsMap[ns] = cache.Config{}
 		}
 	}
+	if clusterResourceNamespace == "" {
+		clusterResourceNamespace = managerNamespace()
+	}
+	if len(nsMap) > 0 && clusterResourceNamespace != "" {
+		// The Secrets of ClusterLangfuseConnections are read through the cache.
+		nsMap[clusterResourceNamespace] = cache.Config{}
+	}
 
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
@@ -177,6 +280,9 @@ func main() {
 		Cache: cache.Options{
 			DefaultNamespaces: nsMap,
 		},
+		Controller: config.Controller{
+			ReconciliationTimeout: reconcileTimeout,
+		},
 		// LeaderElectionReleaseOnCancel defines if the leader should step down voluntarily
 		// when the Manager ends. This requires the binary to immediately end when the
 		// Manager is stopped, otherwise, this setting is unsafe. Setting this significantly
@@ -194,12 +300,120 @@ func main() {
 		os.Exit(1)
 	}
 
-	lfClient := langfuse.NewClient()
+	transport, err := langfuse.NewTransport(langfuseTransport)
+	if err != nil {
+		setupLog.Error(err, "unable to configure the Langfuse transport")
+		os.Exit(1)
+	}
+	if langfuseTransport.InsecureSkipVerify {
+		setupLog.Info("WARNING: Langfuse API server certificate verification is disabled")
+	}
+
+	if (langfusePublicKeyFile == "") != (langfuseSecretKeyFile == "") {
+		setupLog.Error(nil, "--langfuse-public-key-file and --langfuse-secret-key-file must be set together")
+		os.Exit(1)
+	}
+	var credentials langfuse.Credentials
+	if langfusePublicKeyFile != "" {
+		if credentials, err = langfuse.LoadCredentials(langfusePublicKeyFile, langfuseSecretKeyFile); err != nil {
+			setupLog.Error(err, "unable to load Langfuse credentials")
+			os.Exit(1)
+		}
+	}
+
+	var auditLog *langfuse.AuditLog
+	var auditFile *langfuse.RotatingFile
+	switch langfuseAuditLog {
+	case "":
+	case "stdout", "-":
+		auditLog = langfuse.NewAuditLog(os.Stdout)
+	default:
+		auditFile, err = langfuse.OpenRotatingFile(langfuseAuditLog,
+			int64(langfuseAuditLogMaxSize)<<20, langfuseAuditLogMaxBackups)
+		if err != nil {
+			setupLog.Error(err, "unable to open the Langfuse audit log")
+			os.Exit(1)
+		}
+		auditLog = langfuse.NewAuditLog(auditFile)
+	}
+
+	if langfuseMaxRetries == 0 {
+		// Options treat 0 as the default.
+		langfuseMaxRetries = -1
+	}
+	// Project-level calls are made with each project's internal key.
+	projectCredentials := &controller.ProjectCredentials{Reader: mgr.GetClient()}
+	clientOpts := langfuse.Options{
+		RequestTimeout:     langfuseRequestTimeout,
+		MaxRetries:         langfuseMaxRetries,
+		RateLimit:          langfuseRateLimit,
+		RateBurst:          langfuseRateBurst,
+		ProjectCredentials: projectCredentials,
+		AuditLog:           auditLog,
+		BreakerThreshold:   langfuseBreakerThreshold,
+	}
+	lfOpts := clientOpts
+	lfOpts.PublicKey, lfOpts.SecretKey = credentials.PublicKey, credentials.SecretKey
+	lfOpts.Transport = transport
+	lfClient := langfuse.NewClient(lfOpts)
+	// Resources with a connectionRef are managed in the Langfuse instance of
+	// their LangfuseConnection, each with a client of its own.
+	connections := &controller.Connections{
+		Reader:                   mgr.GetClient(),
+		ClusterResourceNamespace: clusterResourceNamespace,
+		Options:                  clientOpts,
+		Transport: langfuse.TransportOptions{
+			ProxyURL: langfuseTransport.ProxyURL,
+			NoProxy:  langfuseTransport.NoProxy,
+		},
+		BreakerProbeInterval: langfuseBreakerProbeInterval,
+	}
+	if langfusePublicKeyFile != "" {
+		if err := mgr.Add(&langfuse.CredentialsWatcher{
+			Client:        lfClient,
+			PublicKeyFile: langfusePublicKeyFile,
+			SecretKeyFile: langfuseSecretKeyFile,
+			Interval:      langfuseCredentialsReloadInterval,
+		}); err != nil {
+			setupLog.Error(err, "unable to set up Langfuse credentials reloading")
+			os.Exit(1)
+		}
+	}
+
+	// While Langfuse is down, reconciles are paused with the condition
+	// LangfuseUnavailable and the manager reports not ready.
+	if lfClient.Breaker != nil {
+		if err := mgr.Add(&langfuse.BreakerProbe{
+			Client:   lfClient,
+			Interval: langfuseBreakerProbeInterval,
+		}); err != nil {
+			setupLog.Error(err, "unable to set up the Langfuse circuit breaker probe")
+			os.Exit(1)
+		}
+		if err := mgr.AddReadyzCheck("langfuse-circuit-breaker", lfClient.Breaker.Check); err != nil {
+			setupLog.Error(err, "unable to set up the Langfuse circuit breaker ready check")
+			os.Exit(1)
+		}
+	}
+
+	// Resources whose kind the server lacks endpoints for are marked
+	// Unsupported instead of failing on 404s.
+	if err := mgr.Add(&langfuse.CapabilityDetector{
+		Client:   lfClient,
+		Interval: langfuseCapabilityProbeInterval,
+	}); err != nil {
+		setupLog.Error(err, "unable to set up Langfuse capability detection")
+		os.Exit(1)
+	}
 
 	if err := (&controller.LangfuseProjectReconciler{
-		Client:         mgr.GetClient(),
-		Scheme:         mgr.GetScheme(),
-		LangfuseClient: lfClient,
+		Client:             mgr.GetClient(),
+		Scheme:             mgr.GetScheme(),
+		LangfuseClient:     lfClient,
+		ProjectCredentials: projectCredentials,
+		Connections:        connections,
+		SyncInterval:       langfuseSyncInterval,
+		Recorder:           mgr.GetEventRecorderFor("langfuseproject-controller"),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "LangfuseProject")
 		os.Exit(1)
@@ -208,6 +422,7 @@ func main() {
 		Client:         mgr.GetClient(),
 		Scheme:         mgr.GetScheme(),
 		LangfuseClient: lfClient,
+		Connections:    connections,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "LangfuseAPIKey")
 		os.Exit(1)
@@ -216,6 +431,7 @@ func main() {
 		Client:         mgr.GetClient(),
 		Scheme:         mgr.GetScheme(),
 		LangfuseClient: lfClient,
+		Connections:    connections,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "LangfuseModel")
 		os.Exit(1)
@@ -224,6 +440,7 @@ func main() {
 		Client:         mgr.GetClient(),
 		Scheme:         mgr.GetScheme(),
 		LangfuseClient: lfClient,
+		Connections:    connections,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "LangfuseLlmConnection")
 		os.Exit(1)
@@ -232,6 +449,7 @@ func main() {
 		Client:         mgr.GetClient(),
 		Scheme:         mgr.GetScheme(),
 		LangfuseClient: lfClient,
+		Connections:    connections,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "LangfusePrompt")
 		os.Exit(1)
@@ -240,10 +458,34 @@ func main() {
 		Client:         mgr.GetClient(),
 		Scheme:         mgr.GetScheme(),
 		LangfuseClient: lfClient,
+		Connections:    connections,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "LangfuseScoreConfig")
 		os.Exit(1)
 	}
+	if err := (&controller.LangfuseConnectionReconciler{
+		Client:      mgr.GetClient(),
+		Scheme:      mgr.GetScheme(),
+		Connections: connections,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "LangfuseConnection")
+		os.Exit(1)
+	}
+	if err := (&controller.ClusterLangfuseConnectionReconciler{
+		Client:      mgr.GetClient(),
+		Scheme:      mgr.GetScheme(),
+		Connections: connections,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ClusterLangfuseConnection")
+		os.Exit(1)
+	}
+	if err := (&controller.LangfuseServerReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "LangfuseServer")
+		os.Exit(1)
+	}
 	// +kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -254,10 +496,47 @@ func main() {
 		setupLog.Error(err, "unable to set up ready check")
 		os.Exit(1)
 	}
+	if langfuseReadinessCheck {
+		// Keeps a rollout from going green with unreachable Langfuse or
+		// wrong credentials.
+		if err := mgr.AddReadyzCheck("langfuse", (&langfuse.ReadinessChecker{
+			Client:   lfClient,
+			CacheTTL: langfuseReadinessCacheTTL,
+			Timeout:  langfuseReadinessTimeout,
+		}).Check); err != nil {
+			setupLog.Error(err, "unable to set up the Langfuse ready check")
+			os.Exit(1)
+		}
+	}
 
 	setupLog.Info("starting manager")
-	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+	err = mgr.Start(ctrl.SetupSignalHandler())
+
+	flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	if flushErr := shutdownTracing(flushCtx); flushErr != nil {
+		setupLog.Error(flushErr, "failed to flush traces")
+	}
+	cancel()
+	if auditFile != nil {
+		if closeErr := auditFile.Close(); closeErr != nil {
+			setupLog.Error(closeErr, "failed to close the Langfuse audit log")
+		}
+	}
+	if err != nil {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
 }
+
+// managerNamespace returns the namespace the manager runs in, or "" when it
+// runs outside of a cluster.
+func managerNamespace() string {
+	if ns := os.Getenv("POD_NAMESPACE"); ns != "" {
+		return ns
+	}
+	ns, err := os.ReadFile("/var/run/secrets/kubernetes.io/serviceaccount/namespace")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(ns))
+}
@@ -0,0 +1,464 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// langfuse-gen generates the Langfuse API types and request functions of
+// internal/langfuse from the vendored OpenAPI specification.
+//
+// For every schema under components/schemas it emits a Go struct, and for
+// every operation an unexported *Client method named after its operationId
+// that builds the request path and calls Client.do with it and the path
+// template, which labels metrics. Authentication, retries
+// and pagination stay in the hand-written part of the package.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+
+	"sigs.k8s.io/yaml"
+)
+
+type spec struct {
+	Paths      map[string]map[string]*operation `json:"paths"`
+	Components struct {
+		Schemas map[string]*schema `json:"schemas"`
+	} `json:"components"`
+}
+
+type operation struct {
+	OperationID string       `json:"operationId"`
+	Description string       `json:"description"`
+	Parameters  []*parameter `json:"parameters"`
+	RequestBody *struct {
+		Content map[string]struct {
+			Schema *schema `json:"schema"`
+		} `json:"content"`
+	} `json:"requestBody"`
+	Responses map[string]struct {
+		Content map[string]struct {
+			Schema *schema `json:"schema"`
+		} `json:"content"`
+	} `json:"responses"`
+}
+
+type parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *schema `json:"schema"`
+}
+
+type schema struct {
+	Ref                  string             `json:"$ref"`
+	Type                 string             `json:"type"`
+	Format               string             `json:"format"`
+	Description          string             `json:"description"`
+	Nullable             bool               `json:"nullable"`
+	Properties           map[string]*schema `json:"properties"`
+	Required             []string           `json:"required"`
+	Items                *schema            `json:"items"`
+	AdditionalProperties interface{}        `json:"additionalProperties"`
+	GoName               string             `json:"x-go-name"`
+}
+
+// initialisms are rendered in upper case in Go identifiers.
+var initialisms = map[string]string{"Id": "ID", "Api": "API", "Url": "URL", "Json": "JSON"}
+
+var methods = []string{"get", "post", "put", "patch", "delete"}
+
+func main() {
+	specPath := flag.String("spec", "openapi/langfuse.yml", "path to the OpenAPI specification")
+	out := flag.String("out", "zz_generated.openapi.go", "output file")
+	pkg := flag.String("package", "langfuse", "package name of the generated file")
+	flag.Parse()
+
+	data, err := os.ReadFile(*specPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var s spec
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		log.Fatalf("parsing %s: %v", *specPath, err)
+	}
+
+	g := &generator{spec: &s}
+	src, err := g.generate(*pkg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*out, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+type generator struct {
+	spec *spec
+	buf  bytes.Buffer
+	// usesStrconv records whether an integer path parameter was emitted.
+	usesStrconv bool
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+func (g *generator) generate(pkg string) ([]byte, error) {
+	names := sortedKeys(g.spec.Components.Schemas)
+	sort.Slice(names, func(i, j int) bool {
+		return g.typeName(names[i]) < g.typeName(names[j])
+	})
+	for _, name := range names {
+		if err := g.genType(name, g.spec.Components.Schemas[name]); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, path := range sortedKeys(g.spec.Paths) {
+		for _, method := range methods {
+			op, ok := g.spec.Paths[path][method]
+			if !ok {
+				continue
+			}
+			if err := g.genOperation(path, method, op); err != nil {
+				return nil, fmt.Errorf("%s %s: %w", strings.ToUpper(method), path, err)
+			}
+		}
+	}
+
+	var file bytes.Buffer
+	fmt.Fprintf(&file, "// Code generated by hack/langfuse-gen from openapi/langfuse.yml. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&file, "package %s\n\n", pkg)
+	imports := []string{"context", "net/http", "net/url"}
+	if g.usesStrconv {
+		imports = append(imports, "strconv")
+	}
+	fmt.Fprintf(&file, "import (\n")
+	for _, imp := range imports {
+		fmt.Fprintf(&file, "\t%q\n", imp)
+	}
+	fmt.Fprintf(&file, ")\n\n")
+	file.Write(g.buf.Bytes())
+
+	src, err := format.Source(file.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %w\n%s", err, file.Bytes())
+	}
+	return src, nil
+}
+
+func (g *generator) typeName(schemaName string) string {
+	if s := g.spec.Components.Schemas[schemaName]; s != nil && s.GoName != "" {
+		return s.GoName
+	}
+	return exported(schemaName)
+}
+
+func (g *generator) genType(name string, s *schema) error {
+	if s.Type != "object" {
+		return fmt.Errorf("schema %s: only object schemas are supported, got %q", name, s.Type)
+	}
+	goName := g.typeName(name)
+	g.comment(goName, name, s.Description, "")
+	g.printf("type %s struct {\n", goName)
+	required := map[string]bool{}
+	for _, r := range s.Required {
+		required[r] = true
+	}
+	for _, prop := range sortedKeys(s.Properties) {
+		p := s.Properties[prop]
+		if p.Description != "" {
+			g.comment("", "", p.Description, "\t")
+		}
+		typ, err := g.goType(p, !required[prop])
+		if err != nil {
+			return fmt.Errorf("schema %s property %s: %w", name, prop, err)
+		}
+		tag := prop
+		if !required[prop] {
+			tag += ",omitempty"
+		}
+		g.printf("\t%s %s `json:%q`\n", exported(prop), typ, tag)
+	}
+	g.printf("}\n\n")
+	return nil
+}
+
+// goType maps a schema to a Go type. Optional numbers and booleans become
+// pointers so that an explicit zero can be sent; schemas without a type
+// (unions such as a prompt's text or chat messages) become interface{}.
+func (g *generator) goType(s *schema, optional bool) (string, error) {
+	if s.Ref != "" {
+		return g.typeName(strings.TrimPrefix(s.Ref, "#/components/schemas/")), nil
+	}
+	ptr := ""
+	if optional {
+		ptr = "*"
+	}
+	switch s.Type {
+	case "":
+		return "interface{}", nil
+	case "string":
+		return "string", nil
+	case "integer":
+		return ptr + "int", nil
+	case "number":
+		return ptr + "float64", nil
+	case "boolean":
+		return ptr + "bool", nil
+	case "array":
+		item, err := g.goType(s.Items, false)
+		if err != nil {
+			return "", err
+		}
+		return "[]" + item, nil
+	case "object":
+		if ap, ok := s.AdditionalProperties.(map[string]interface{}); ok && ap["type"] == "string" {
+			return "map[string]string", nil
+		}
+		return "map[string]interface{}", nil
+	}
+	return "", fmt.Errorf("unsupported type %q", s.Type)
+}
+
+func (g *generator) genOperation(path, method string, op *operation) error {
+	name := unexported(op.OperationID)
+	args := []string{"ctx context.Context"}
+	var pathParams, queryParams []*parameter
+	for _, p := range op.Parameters {
+		switch p.In {
+		case "path":
+			typ, err := g.goType(p.Schema, false)
+			if err != nil {
+				return err
+			}
+			args = append(args, unexported(p.Name)+" "+typ)
+			pathParams = append(pathParams, p)
+		case "query":
+			queryParams = append(queryParams, p)
+		default:
+			return fmt.Errorf("unsupported parameter location %q", p.In)
+		}
+	}
+	if len(queryParams) > 0 {
+		args = append(args, "query url.Values")
+	}
+	body := "nil"
+	if op.RequestBody != nil {
+		s := op.RequestBody.Content["application/json"].Schema
+		typ, err := g.goType(s, false)
+		if err != nil {
+			return err
+		}
+		args = append(args, "body "+typ)
+		body = "body"
+	}
+
+	result := ""
+	for _, code := range sortedKeys(op.Responses) {
+		if !strings.HasPrefix(code, "2") {
+			continue
+		}
+		if c, ok := op.Responses[code].Content["application/json"]; ok {
+			typ, err := g.goType(c.Schema, false)
+			if err != nil {
+				return err
+			}
+			result = typ
+		}
+	}
+
+	g.printf("// %s calls %s %s.\n", name, strings.ToUpper(method), path)
+	if op.Description != "" {
+		g.printf("//\n")
+		g.comment("", "", op.Description, "")
+	}
+	if len(queryParams) > 0 {
+		var qs []string
+		for _, p := range queryParams {
+			qs = append(qs, p.Name)
+		}
+		g.printf("//\n// Supported query parameters: %s.\n", strings.Join(qs, ", "))
+	}
+	if result == "" {
+		g.printf("func (c *Client) %s(%s) error {\n", name, strings.Join(args, ", "))
+	} else {
+		g.printf("func (c *Client) %s(%s) (*%s, error) {\n", name, strings.Join(args, ", "), result)
+	}
+	expr, usesStrconv := pathExpr(path, pathParams)
+	g.usesStrconv = g.usesStrconv || usesStrconv
+	g.printf("\tpath := %s\n", expr)
+	if len(queryParams) > 0 {
+		g.printf("\tif len(query) > 0 {\n\t\tpath += \"?\" + query.Encode()\n\t}\n")
+	}
+	httpMethod := "http.Method" + exported(method)
+	if result == "" {
+		g.printf("\treturn c.do(ctx, %s, %q, path, %s, nil)\n}\n\n", httpMethod, path, body)
+		return nil
+	}
+	g.printf("\tvar out %s\n", result)
+	g.printf("\tif err := c.do(ctx, %s, %q, path, %s, &out); err != nil {\n\t\treturn nil, err\n\t}\n",
+		httpMethod, path, body)
+	g.printf("\treturn &out, nil\n}\n\n")
+	return nil
+}
+
+// pathExpr renders a Go expression that expands the path template with
+// escaped path parameters. It reports whether integer parameters are
+// formatted with strconv.
+func pathExpr(path string, params []*parameter) (string, bool) {
+	types := map[string]string{}
+	for _, p := range params {
+		types[p.Name] = p.Schema.Type
+	}
+	var parts []string
+	usesStrconv := false
+	for {
+		start := strings.Index(path, "{")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(path[start:], "}") + start
+		if start > 0 {
+			parts = append(parts, fmt.Sprintf("%q", path[:start]))
+		}
+		name := path[start+1 : end]
+		if types[name] == "integer" {
+			parts = append(parts, "strconv.Itoa("+unexported(name)+")")
+			usesStrconv = true
+		} else {
+			parts = append(parts, "url.PathEscape("+unexported(name)+")")
+		}
+		path = path[end+1:]
+	}
+	if path != "" {
+		parts = append(parts, fmt.Sprintf("%q", path))
+	}
+	return strings.Join(parts, " + "), usesStrconv
+}
+
+// comment emits text as a doc comment. For type comments, name is the Go
+// type and schemaName the upstream schema it was generated from.
+func (g *generator) comment(name, schemaName, text, indent string) {
+	text = sentence(text)
+	if name != "" {
+		g.printf("%s// %s is the Langfuse %s schema.\n", indent, name, schemaName)
+		if text == "" {
+			return
+		}
+		g.printf("%s//\n", indent)
+	}
+	for _, line := range wrap(text, 76) {
+		g.printf("%s// %s\n", indent, line)
+	}
+}
+
+// sentence trims text and terminates it with a period so that gofmt does not
+// mistake a short description for a doc comment heading.
+func sentence(text string) string {
+	text = strings.TrimSpace(text)
+	if text != "" && !strings.ContainsAny(text[len(text)-1:], ".!?") {
+		text += "."
+	}
+	return text
+}
+
+func wrap(text string, width int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		if line != "" && len(line)+1+len(word) > width {
+			lines = append(lines, line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// exported converts an OpenAPI identifier such as "projectId",
+// "scoreConfigs_get-by-id" or "utilsMetaResponse" into an exported Go name.
+func exported(s string) string {
+	var words []string
+	word := []rune{}
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, string(word))
+			word = word[:0]
+		}
+	}
+	for _, r := range s {
+		switch {
+		case r == '_' || r == '-' || r == ' ':
+			flush()
+		case unicode.IsUpper(r) && len(word) > 0 && !unicode.IsUpper(word[len(word)-1]):
+			flush()
+			word = append(word, r)
+		default:
+			word = append(word, r)
+		}
+	}
+	flush()
+	var out strings.Builder
+	for _, w := range words {
+		w = strings.ToUpper(w[:1]) + w[1:]
+		if upper, ok := initialisms[w]; ok {
+			w = upper
+		}
+		out.WriteString(w)
+	}
+	return out.String()
+}
+
+// unexported converts an OpenAPI identifier into an unexported Go name.
+func unexported(s string) string {
+	name := exported(s)
+	for i, r := range name {
+		if !unicode.IsUpper(r) {
+			if i > 1 {
+				// Lower an initialism prefix except for the first letter of
+				// the following word, e.g. "APIKeys" -> "apiKeys".
+				i--
+			}
+			if i == 0 {
+				i = 1
+			}
+			return strings.ToLower(name[:i]) + name[i:]
+		}
+	}
+	return strings.ToLower(name)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+
+	langfusev1alpha1 "github.com/sqaisar/langfuse-controller/api/v1alpha1"
+)
+
+// ClusterLangfuseConnectionReconciler reconciles a ClusterLangfuseConnection object
+type ClusterLangfuseConnectionReconciler struct {
+	client.Client
+	Scheme      *runtime.Scheme
+	Connections *Connections
+}
+
+// +kubebuilder:rbac:groups=langfuse.io,resources=clusterlangfuseconnections,verbs=get;list;watch
+// +kubebuilder:rbac:groups=langfuse.io,resources=clusterlangfuseconnections/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
+
+// Reconcile checks the Langfuse instance of a ClusterLangfuseConnection like
+// LangfuseConnectionReconciler does.
+func (r *ClusterLangfuseConnectionReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	var conn langfusev1alpha1.ClusterLangfuseConnection
+	if err := r.Get(ctx, req.NamespacedName, &conn); err != nil {
+		if client.IgnoreNotFound(err) == nil {
+			r.Connections.Forget(langfusev1alpha1.ClusterLangfuseConnectionKind, types.NamespacedName{Name: req.Name})
+		}
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	lf, err := r.Connections.ClusterClient(ctx, conn.Name)
+	changed, requeueAfter, err := checkConnection(ctx, lf, err, conn.Generation, &conn.Status)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if changed {
+		if err := r.Status().Update(ctx, &conn); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ClusterLangfuseConnectionReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&langfusev1alpha1.ClusterLangfuseConnection{}).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: 1, // avoids event storms
+		}).
+		Named("clusterlangfuseconnection").
+		Complete(traced("ClusterLangfuseConnection", r))
+}
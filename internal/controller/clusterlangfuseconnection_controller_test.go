@@ -0,0 +1,206 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	langfusev1alpha1 "github.com/sqaisar/langfuse-controller/api/v1alpha1"
+	"github.com/sqaisar/langfuse-controller/internal/langfuse"
+	"github.com/sqaisar/langfuse-controller/internal/langfuse/fake"
+	"github.com/sqaisar/langfuse-controller/pkg/langfusetest"
+)
+
+var _ = Describe("ClusterLangfuseConnection Controller", func() {
+	ctx := context.Background()
+	const regionLabel = "langfuse.io/region"
+	connectionKey := types.NamespacedName{Name: "us"}
+	secretKey := types.NamespacedName{Name: "us-credentials", Namespace: "default"}
+
+	var server *langfusetest.Server
+	var defaultClient *fake.Client
+	var connections *Connections
+	var projectKey types.NamespacedName
+
+	// createNamespace creates a namespace with the given labels. envtest
+	// never finishes deleting namespaces, so every spec gets a new one.
+	createNamespace := func(labels map[string]string) string {
+		ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{GenerateName: "cluster-connection-", Labels: labels}}
+		Expect(k8sClient.Create(ctx, ns)).To(Succeed())
+		return ns.Name
+	}
+
+	BeforeEach(func() {
+		server = langfusetest.NewServer()
+		defaultClient = fake.NewClient()
+		connections = &Connections{
+			Reader:                   k8sClient,
+			ClusterResourceNamespace: secretKey.Namespace,
+			Options: langfuse.Options{
+				RetryWaitMin:       time.Millisecond,
+				RetryWaitMax:       10 * time.Millisecond,
+				ProjectCredentials: &ProjectCredentials{Reader: k8sClient},
+			},
+		}
+
+		By("creating the credentials Secret and the ClusterLangfuseConnection")
+		Expect(k8sClient.Create(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: secretKey.Name, Namespace: secretKey.Namespace},
+			StringData: map[string]string{
+				"LANGFUSE_PUBLIC_KEY": server.PublicKey,
+				"LANGFUSE_SECRET_KEY": server.SecretKey,
+			},
+		})).To(Succeed())
+		Expect(k8sClient.Create(ctx, &langfusev1alpha1.ClusterLangfuseConnection{
+			ObjectMeta: metav1.ObjectMeta{Name: connectionKey.Name},
+			Spec: langfusev1alpha1.ClusterLangfuseConnectionSpec{
+				LangfuseConnectionSpec: langfusev1alpha1.LangfuseConnectionSpec{
+					Host:                 server.URL,
+					CredentialsSecretRef: langfusev1alpha1.CredentialsSecretReference{Name: secretKey.Name},
+				},
+				NamespaceSelector: metav1.LabelSelector{MatchLabels: map[string]string{regionLabel: "us"}},
+			},
+		})).To(Succeed())
+	})
+
+	AfterEach(func() {
+		server.Close()
+		for _, obj := range []struct {
+			key types.NamespacedName
+			obj client.Object
+		}{
+			{projectKey, &langfusev1alpha1.LangfuseProject{}},
+			{types.NamespacedName{Name: projectKey.Name + projectKeySecretSuffix, Namespace: projectKey.Namespace},
+				&corev1.Secret{}},
+			{connectionKey, &langfusev1alpha1.ClusterLangfuseConnection{}},
+			{secretKey, &corev1.Secret{}},
+		} {
+			if err := k8sClient.Get(ctx, obj.key, obj.obj); err == nil {
+				deleteAndRelease(ctx, obj.obj)
+			}
+		}
+	})
+
+	reconcileProject := func(namespace string, ref *langfusev1alpha1.ConnectionReference) (
+		*langfusev1alpha1.LangfuseProject, reconcile.Result) {
+		projectKey = types.NamespacedName{Name: "shared-project", Namespace: namespace}
+		Expect(k8sClient.Create(ctx, &langfusev1alpha1.LangfuseProject{
+			ObjectMeta: metav1.ObjectMeta{Name: projectKey.Name, Namespace: projectKey.Namespace},
+			Spec:       langfusev1alpha1.LangfuseProjectSpec{Name: "Shared Project", ConnectionRef: ref},
+		})).To(Succeed())
+		reconciler := &LangfuseProjectReconciler{
+			Client:         k8sClient,
+			Scheme:         k8sClient.Scheme(),
+			LangfuseClient: defaultClient,
+			Connections:    connections,
+		}
+		result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: projectKey})
+		Expect(err).NotTo(HaveOccurred())
+		project := &langfusev1alpha1.LangfuseProject{}
+		Expect(k8sClient.Get(ctx, projectKey, project)).To(Succeed())
+		return project, result
+	}
+
+	It("should report a reachable instance as Ready", func() {
+		reconciler := &ClusterLangfuseConnectionReconciler{
+			Client:      k8sClient,
+			Scheme:      k8sClient.Scheme(),
+			Connections: connections,
+		}
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: connectionKey})
+		Expect(err).NotTo(HaveOccurred())
+
+		conn := &langfusev1alpha1.ClusterLangfuseConnection{}
+		Expect(k8sClient.Get(ctx, connectionKey, conn)).To(Succeed())
+		Expect(meta.IsStatusConditionTrue(conn.Status.Conditions, ConditionReady)).To(BeTrue())
+		Expect(conn.Status.Version).To(Equal(langfusetest.DefaultVersion))
+	})
+
+	It("should manage resources without a connectionRef in a selected namespace", func() {
+		project, _ := reconcileProject(createNamespace(map[string]string{regionLabel: "us"}), nil)
+		Expect(project.Status.ID).NotTo(BeEmpty())
+		Expect(project.Status.Connection).To(Equal("ClusterLangfuseConnection/us"))
+		Expect(server.Projects()).To(HaveLen(1))
+		Expect(defaultClient.Projects()).To(BeEmpty())
+	})
+
+	It("should leave resources in other namespaces to the operator's instance", func() {
+		project, _ := reconcileProject(createNamespace(map[string]string{regionLabel: "eu"}), nil)
+		Expect(project.Status.ID).NotTo(BeEmpty())
+		Expect(server.Projects()).To(BeEmpty())
+		Expect(defaultClient.Projects()).To(HaveLen(1))
+	})
+
+	It("should keep existing projects in their instance when another connection selects the namespace", func() {
+		project, _ := reconcileProject(createNamespace(map[string]string{regionLabel: "eu"}), nil)
+		Expect(project.Status.Connection).To(Equal(defaultConnection))
+
+		By("adding a ClusterLangfuseConnection that selects every namespace")
+		everywhere := &langfusev1alpha1.ClusterLangfuseConnection{
+			ObjectMeta: metav1.ObjectMeta{Name: "everywhere"},
+			Spec: langfusev1alpha1.ClusterLangfuseConnectionSpec{
+				LangfuseConnectionSpec: langfusev1alpha1.LangfuseConnectionSpec{
+					Host:                 server.URL,
+					CredentialsSecretRef: langfusev1alpha1.CredentialsSecretReference{Name: secretKey.Name},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, everywhere)).To(Succeed())
+		defer deleteAndRelease(ctx, everywhere)
+
+		reconciler := &LangfuseProjectReconciler{
+			Client:         k8sClient,
+			Scheme:         k8sClient.Scheme(),
+			LangfuseClient: defaultClient,
+			Connections:    connections,
+		}
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: projectKey})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k8sClient.Get(ctx, projectKey, project)).To(Succeed())
+		Expect(project.Status.Connection).To(Equal(defaultConnection))
+		cond := meta.FindStatusCondition(project.Status.Conditions, ConditionConnectionMismatch)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+		Expect(cond.Reason).To(Equal(ReasonConnectionChanged))
+		Expect(server.Projects()).To(BeEmpty())
+		Expect(defaultClient.Projects()).To(HaveLen(1))
+	})
+
+	It("should refuse references from namespaces that are not selected", func() {
+		project, result := reconcileProject(createNamespace(nil), &langfusev1alpha1.ConnectionReference{
+			Kind: langfusev1alpha1.ClusterLangfuseConnectionKind,
+			Name: connectionKey.Name,
+		})
+		Expect(result.RequeueAfter).To(Equal(connectionRequeueAfter))
+		cond := meta.FindStatusCondition(project.Status.Conditions, ConditionConnectionNotAllowed)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Reason).To(Equal(ReasonNamespaceNotSelected))
+		Expect(project.Status.ID).To(BeEmpty())
+		Expect(server.Projects()).To(BeEmpty())
+		Expect(defaultClient.Projects()).To(BeEmpty())
+	})
+})
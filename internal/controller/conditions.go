@@ -0,0 +1,342 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	langfusev1alpha1 "github.com/sqaisar/langfuse-controller/api/v1alpha1"
+	"github.com/sqaisar/langfuse-controller/internal/langfuse"
+)
+
+const (
+	// ConditionAuthenticated reports whether Langfuse accepted the operator's
+	// credentials on the last call made for the resource. It turns False when
+	// the admin keys expire or are revoked, e.g. after a missed rotation.
+	ConditionAuthenticated = "Authenticated"
+
+	ReasonAuthenticated        = "Authenticated"
+	ReasonAuthenticationFailed = "AuthenticationFailed"
+
+	// ConditionUnsupported is True on resources whose kind the Langfuse
+	// server cannot handle because it lacks the endpoints, e.g. LLM
+	// connections on an older self-hosted version.
+	ConditionUnsupported = "Unsupported"
+
+	ReasonCapabilityMissing = "CapabilityMissing"
+
+	// ConditionLangfuseUnavailable is True while reconciles of the resource
+	// are paused because the Langfuse client's circuit breaker is open.
+	ConditionLangfuseUnavailable = "LangfuseUnavailable"
+
+	ReasonCircuitOpen = "CircuitOpen"
+
+	// ConditionConnectionFailed is True while the connection the resource
+	// is managed through cannot be used, e.g. because it or its credentials
+	// Secret does not exist, or more than one ClusterLangfuseConnection
+	// selects the namespace of a resource without a connectionRef.
+	ConditionConnectionFailed = "ConnectionFailed"
+
+	ReasonConnectionNotFound  = "ConnectionNotFound"
+	ReasonInvalidConnection   = "InvalidConnection"
+	ReasonAmbiguousConnection = "AmbiguousConnection"
+
+	// ConditionConnectionNotAllowed is True on resources that reference a
+	// ClusterLangfuseConnection whose namespaceSelector does not select
+	// their namespace.
+	ConditionConnectionNotAllowed = "ConnectionNotAllowed"
+
+	ReasonNamespaceNotSelected = "NamespaceNotSelected"
+
+	// ConditionConnectionMismatch is True on resources whose connectionRef
+	// or the ClusterLangfuseConnections selecting their namespace now point
+	// to another connection than the one their Langfuse object was created
+	// in. They stay in the instance they were created in.
+	ConditionConnectionMismatch = "ConnectionMismatch"
+
+	ReasonConnectionChanged = "ConnectionChanged"
+
+	// ConditionRejected is True on resources whose last change Langfuse
+	// rejected in a way that retrying does not fix, e.g. because an object
+	// of the same name exists that the resource does not manage.
+	ConditionRejected = "Rejected"
+
+	ReasonConflict = "Conflict"
+	ReasonInvalid  = "Invalid"
+	// ReasonNotManaged is set by the controller itself, before calling
+	// Langfuse, when the object to change exists and is not managed by the
+	// resource.
+	ReasonNotManaged = "NotManaged"
+)
+
+// unsupportedRequeueAfter is how long to wait before checking an
+// unsupported resource again, in case the server was upgraded.
+const unsupportedRequeueAfter = 10 * time.Minute
+
+// unavailableRequeueAfter is how long to wait before reconciling a resource
+// again while Langfuse is unavailable.
+const unavailableRequeueAfter = 30 * time.Second
+
+// connectionRequeueAfter is how long to wait before resolving a failed
+// LangfuseConnection again.
+const connectionRequeueAfter = 30 * time.Second
+
+// rejectedRequeueAfter is how long to wait before trying a rejected
+// resource again, e.g. in case the conflicting object was removed.
+const rejectedRequeueAfter = 5 * time.Minute
+
+// langfuseError records the error of a Langfuse call made for obj in its
+// conditions and returns how to requeue it. Rejected requests and rate
+// limits are requeued after a delay instead of failing, because the client
+// has already retried them or retrying right away fails the same way.
+func langfuseError(ctx context.Context, c client.Client, obj client.Object, conditions *[]metav1.Condition,
+	err error) (ctrl.Result, error) {
+	changed := setAuthenticatedCondition(conditions, obj.GetGeneration(), err)
+	result, retErr := ctrl.Result{}, err
+	reason := ""
+	switch code := langfuse.StatusCodeOf(err); {
+	case langfuse.IsConflict(err):
+		reason = ReasonConflict
+	case code == http.StatusBadRequest || code == http.StatusUnprocessableEntity:
+		reason = ReasonInvalid
+	case langfuse.IsRateLimited(err):
+		result, retErr = ctrl.Result{RequeueAfter: unavailableRequeueAfter}, nil
+	}
+	if reason != "" {
+		changed = meta.SetStatusCondition(conditions, metav1.Condition{
+			Type:               ConditionRejected,
+			Status:             metav1.ConditionTrue,
+			Reason:             reason,
+			Message:            err.Error(),
+			ObservedGeneration: obj.GetGeneration(),
+		}) || changed
+		result, retErr = ctrl.Result{RequeueAfter: rejectedRequeueAfter}, nil
+	}
+	if changed {
+		if err := c.Status().Update(ctx, obj); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	return result, retErr
+}
+
+// setAvailable replaces conditions with an Available condition for
+// generation, as children do once their Langfuse object matches the spec.
+// ConnectionMismatch is kept, as it is set before the object is compared.
+// The transition time is kept if they were Available already. It reports
+// whether conditions changed, so that unchanged status is not written.
+func setAvailable(conditions *[]metav1.Condition, generation int64, reason, message string) bool {
+	available := metav1.Condition{
+		Type:               ConditionAvailable,
+		Status:             metav1.ConditionTrue,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: generation,
+		LastTransitionTime: metav1.Now(),
+	}
+	if old := meta.FindStatusCondition(*conditions, ConditionAvailable); old != nil &&
+		old.Status == metav1.ConditionTrue {
+		available.LastTransitionTime = old.LastTransitionTime
+	}
+	want := []metav1.Condition{available}
+	if mismatch := meta.FindStatusCondition(*conditions, ConditionConnectionMismatch); mismatch != nil {
+		want = append(want, *mismatch)
+	}
+	if len(*conditions) == len(want) && equality.Semantic.DeepEqual(*conditions, want) {
+		return false
+	}
+	*conditions = want
+	return true
+}
+
+// availableGeneration returns the generation the Available condition in
+// conditions was set for, or -1 if it is not True.
+func availableGeneration(conditions []metav1.Condition) int64 {
+	if cond := meta.FindStatusCondition(conditions, ConditionAvailable); cond != nil &&
+		cond.Status == metav1.ConditionTrue {
+		return cond.ObservedGeneration
+	}
+	return -1
+}
+
+// setAuthenticatedCondition records the outcome of a Langfuse call in
+// conditions. Errors other than 401/403 say nothing about the credentials
+// and leave the condition unchanged. It reports whether the condition
+// changed.
+func setAuthenticatedCondition(conditions *[]metav1.Condition, generation int64, err error) bool {
+	cond := metav1.Condition{
+		Type:               ConditionAuthenticated,
+		Status:             metav1.ConditionTrue,
+		Reason:             ReasonAuthenticated,
+		Message:            "Langfuse accepted the operator credentials",
+		ObservedGeneration: generation,
+	}
+	switch {
+	case err == nil:
+	case langfuse.IsUnauthorized(err):
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = ReasonAuthenticationFailed
+		cond.Message = err.Error()
+	default:
+		return false
+	}
+	return meta.SetStatusCondition(conditions, cond)
+}
+
+// requireCapability reports whether the Langfuse server supports
+// capability. If it does not, obj is marked Unsupported and should be
+// requeued after unsupportedRequeueAfter instead of failing on 404s. The
+// condition is removed again once the server supports it.
+func requireCapability(ctx context.Context, c client.Client, lf langfuse.LangfuseAPI,
+	capability langfuse.Capability, obj client.Object, conditions *[]metav1.Condition) (bool, error) {
+	if lf.Supports(capability) {
+		if meta.RemoveStatusCondition(conditions, ConditionUnsupported) {
+			return true, c.Status().Update(ctx, obj)
+		}
+		return true, nil
+	}
+	changed := meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               ConditionUnsupported,
+		Status:             metav1.ConditionTrue,
+		Reason:             ReasonCapabilityMissing,
+		Message:            fmt.Sprintf("The Langfuse server at %s does not support %s", lf.Host(), capability),
+		ObservedGeneration: obj.GetGeneration(),
+	})
+	if changed {
+		return false, c.Status().Update(ctx, obj)
+	}
+	return false, nil
+}
+
+// requireLangfuse reports whether Langfuse is available. While the
+// client's circuit breaker is open, obj is marked LangfuseUnavailable and
+// should be requeued after unavailableRequeueAfter without calling
+// Langfuse. The condition is removed again once the breaker closes.
+func requireLangfuse(ctx context.Context, c client.Client, lf langfuse.LangfuseAPI,
+	obj client.Object, conditions *[]metav1.Condition) (bool, error) {
+	if lf.Available() {
+		if meta.RemoveStatusCondition(conditions, ConditionLangfuseUnavailable) {
+			return true, c.Status().Update(ctx, obj)
+		}
+		return true, nil
+	}
+	changed := meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               ConditionLangfuseUnavailable,
+		Status:             metav1.ConditionTrue,
+		Reason:             ReasonCircuitOpen,
+		Message:            fmt.Sprintf("Langfuse at %s is unavailable, reconciles are paused until it recovers", lf.Host()),
+		ObservedGeneration: obj.GetGeneration(),
+	})
+	if changed {
+		return false, c.Status().Update(ctx, obj)
+	}
+	return false, nil
+}
+
+// requireConnection returns the Langfuse client of the instance a resource
+// in namespace with the connectionRef ref is managed in, and its
+// connection, see langfuseFor. If the connection cannot be used, obj is
+// marked ConnectionFailed, or ConnectionNotAllowed if a
+// ClusterLangfuseConnection does not select the namespace, and should be
+// requeued after connectionRequeueAfter. The condition is removed again
+// once the connection resolves. A resource with a pinned connection that
+// differs from the one ref now resolves to is marked ConnectionMismatch.
+func requireConnection(ctx context.Context, c client.Client, conns *Connections, def langfuse.LangfuseAPI,
+	namespace string, ref *langfusev1alpha1.ConnectionReference, pinned string, obj client.Object,
+	conditions *[]metav1.Condition) (langfuse.LangfuseAPI, string, bool, error) {
+	lf, connection, err := langfuseFor(ctx, conns, def, namespace, ref, pinned)
+	if err == nil {
+		changed := meta.RemoveStatusCondition(conditions, ConditionConnectionFailed)
+		changed = meta.RemoveStatusCondition(conditions, ConditionConnectionNotAllowed) || changed
+		changed = setConnectionMismatch(ctx, conns, namespace, ref, pinned, obj, conditions) || changed
+		if changed {
+			return lf, connection, true, c.Status().Update(ctx, obj)
+		}
+		return lf, connection, true, nil
+	}
+
+	conditionType, stale, reason := ConditionConnectionFailed, ConditionConnectionNotAllowed, ReasonInvalidConnection
+	switch {
+	case errors.Is(err, errConnectionNotFound):
+		reason = ReasonConnectionNotFound
+	case errors.Is(err, errAmbiguousConnection):
+		reason = ReasonAmbiguousConnection
+	case errors.Is(err, errConnectionNotAllowed):
+		conditionType, stale, reason = ConditionConnectionNotAllowed, ConditionConnectionFailed, ReasonNamespaceNotSelected
+	}
+	message := err.Error()
+	if pinned != "" {
+		message = fmt.Sprintf("%s: %v", pinned, err)
+	} else if ref != nil {
+		kind := ref.Kind
+		if kind == "" {
+			kind = langfusev1alpha1.LangfuseConnectionKind
+		}
+		message = fmt.Sprintf("%s %s: %v", kind, ref.Name, err)
+	}
+	logf.FromContext(ctx).Error(err, "Failed to resolve the Langfuse connection", "reason", reason)
+	changed := meta.RemoveStatusCondition(conditions, stale)
+	changed = meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             metav1.ConditionTrue,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: obj.GetGeneration(),
+	}) || changed
+	if changed {
+		return nil, "", false, c.Status().Update(ctx, obj)
+	}
+	return nil, "", false, nil
+}
+
+// setConnectionMismatch sets or removes ConnectionMismatch in conditions,
+// depending on whether a resource in namespace with the connectionRef ref
+// would now be managed in a connection other than pinned. It reports
+// whether conditions changed.
+func setConnectionMismatch(ctx context.Context, conns *Connections, namespace string,
+	ref *langfusev1alpha1.ConnectionReference, pinned string, obj client.Object,
+	conditions *[]metav1.Condition) bool {
+	if pinned == "" {
+		return meta.RemoveStatusCondition(conditions, ConditionConnectionMismatch)
+	}
+	target, err := targetConnection(ctx, conns, namespace, ref)
+	if err == nil && target == pinned {
+		return meta.RemoveStatusCondition(conditions, ConditionConnectionMismatch)
+	}
+	if err != nil {
+		target = err.Error()
+	}
+	return meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:   ConditionConnectionMismatch,
+		Status: metav1.ConditionTrue,
+		Reason: ReasonConnectionChanged,
+		Message: fmt.Sprintf("Managed in %s, which the resource stays in; the connection now resolves to %s. "+
+			"Recreate the resource to move it", pinned, target),
+		ObservedGeneration: obj.GetGeneration(),
+	})
+}
@@ -0,0 +1,481 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	langfusev1alpha1 "github.com/sqaisar/langfuse-controller/api/v1alpha1"
+	"github.com/sqaisar/langfuse-controller/internal/langfuse"
+)
+
+// Connections is the pool of Langfuse clients for LangfuseConnections and
+// ClusterLangfuseConnections. A client is built from the connection and its
+// Secrets on first use and built again once the connection or one of the
+// Secrets has changed, so rotated keys and certificates are picked up by
+// the next reconcile. Each pooled client with a circuit breaker has its own
+// BreakerProbe, which stops when the client is replaced or forgotten.
+type Connections struct {
+	// Reader reads connections, their Secrets and LangfuseServers, and
+	// namespaces.
+	Reader client.Reader
+	// ClusterResourceNamespace is the namespace the Secrets of
+	// ClusterLangfuseConnections are read from, usually the operator's.
+	ClusterResourceNamespace string
+	// Options are the base options of every client in the pool, such as
+	// timeouts, rate limits, the audit log and ProjectCredentials. The host,
+	// keys and TLS settings come from the connection.
+	Options langfuse.Options
+	// Transport holds the proxy settings of the clients in the pool.
+	// Certificate settings come from the connection.
+	Transport langfuse.TransportOptions
+	// BreakerProbeInterval is the Interval of the BreakerProbe of each
+	// pooled client.
+	BreakerProbeInterval time.Duration
+
+	mu      sync.Mutex
+	clients map[connectionKey]*pooledClient
+}
+
+// connectionKey identifies a connection in the pool. The namespace of
+// ClusterLangfuseConnections is empty.
+type connectionKey struct {
+	kind string
+	types.NamespacedName
+}
+
+// pooledClient is a client and the versions of the objects it was built
+// from.
+type pooledClient struct {
+	version string
+	client  *langfuse.Client
+	// stopProbe stops the BreakerProbe of client, if it has one.
+	stopProbe context.CancelFunc
+}
+
+// close stops the BreakerProbe of the client.
+func (p *pooledClient) close() {
+	if p.stopProbe != nil {
+		p.stopProbe()
+	}
+}
+
+// Target returns the connection a resource in namespace with the
+// connectionRef ref is managed in, as "<kind>/<name>". Without ref, it is
+// the ClusterLangfuseConnection that selects the namespace, or "" if none
+// does.
+func (c *Connections) Target(ctx context.Context, namespace string,
+	ref *langfusev1alpha1.ConnectionReference) (string, error) {
+	if ref != nil && ref.Kind != langfusev1alpha1.ClusterLangfuseConnectionKind {
+		return connectionName(langfusev1alpha1.LangfuseConnectionKind, ref.Name), nil
+	}
+
+	var ns corev1.Namespace
+	if err := c.Reader.Get(ctx, types.NamespacedName{Name: namespace}, &ns); err != nil {
+		return "", err
+	}
+	if ref != nil {
+		if _, err := c.selectingConnection(ctx, &ns, ref.Name); err != nil {
+			return "", err
+		}
+		return connectionName(langfusev1alpha1.ClusterLangfuseConnectionKind, ref.Name), nil
+	}
+
+	var conns langfusev1alpha1.ClusterLangfuseConnectionList
+	if err := c.Reader.List(ctx, &conns); err != nil {
+		return "", err
+	}
+	var names []string
+	for i := range conns.Items {
+		selected, err := selectsNamespace(&conns.Items[i], &ns)
+		if err != nil {
+			return "", err
+		}
+		if selected {
+			names = append(names, conns.Items[i].Name)
+		}
+	}
+	switch len(names) {
+	case 0:
+		return "", nil
+	case 1:
+		return connectionName(langfusev1alpha1.ClusterLangfuseConnectionKind, names[0]), nil
+	default:
+		return "", fmt.Errorf("%w: namespace %s is selected by ClusterLangfuseConnections %s, set a connectionRef",
+			errAmbiguousConnection, namespace, strings.Join(names, ", "))
+	}
+}
+
+// ClientOf returns the client of connection, as returned by Target, for a
+// resource in namespace.
+func (c *Connections) ClientOf(ctx context.Context, namespace, connection string) (*langfuse.Client, error) {
+	kind, name, _ := strings.Cut(connection, "/")
+	switch kind {
+	case langfusev1alpha1.LangfuseConnectionKind:
+		return c.Client(ctx, namespace, name)
+	case langfusev1alpha1.ClusterLangfuseConnectionKind:
+		var ns corev1.Namespace
+		if err := c.Reader.Get(ctx, types.NamespacedName{Name: namespace}, &ns); err != nil {
+			return nil, err
+		}
+		conn, err := c.selectingConnection(ctx, &ns, name)
+		if err != nil {
+			return nil, err
+		}
+		return c.clusterClient(ctx, conn)
+	default:
+		return nil, fmt.Errorf("unknown connection %q", connection)
+	}
+}
+
+// selectingConnection returns the ClusterLangfuseConnection name if its
+// namespaceSelector matches ns.
+func (c *Connections) selectingConnection(ctx context.Context, ns *corev1.Namespace,
+	name string) (*langfusev1alpha1.ClusterLangfuseConnection, error) {
+	var conn langfusev1alpha1.ClusterLangfuseConnection
+	if err := c.Reader.Get(ctx, types.NamespacedName{Name: name}, &conn); apierrors.IsNotFound(err) {
+		return nil, errConnectionNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	selected, err := selectsNamespace(&conn, ns)
+	if err != nil {
+		return nil, err
+	}
+	if !selected {
+		return nil, fmt.Errorf("%w: namespace %s is not selected by the namespaceSelector",
+			errConnectionNotAllowed, ns.Name)
+	}
+	return &conn, nil
+}
+
+// connectionName names the connection of kind in status.connection.
+func connectionName(kind, name string) string {
+	return kind + "/" + name
+}
+
+// selectsNamespace reports whether the namespaceSelector of conn matches ns.
+func selectsNamespace(conn *langfusev1alpha1.ClusterLangfuseConnection, ns *corev1.Namespace) (bool, error) {
+	selector, err := metav1.LabelSelectorAsSelector(&conn.Spec.NamespaceSelector)
+	if err != nil {
+		return false, fmt.Errorf("ClusterLangfuseConnection %s has an invalid namespaceSelector: %w", conn.Name, err)
+	}
+	return selector.Matches(labels.Set(ns.Labels)), nil
+}
+
+// Client returns the client of the LangfuseConnection name in namespace.
+func (c *Connections) Client(ctx context.Context, namespace, name string) (*langfuse.Client, error) {
+	key := types.NamespacedName{Namespace: namespace, Name: name}
+	var conn langfusev1alpha1.LangfuseConnection
+	if err := c.Reader.Get(ctx, key, &conn); apierrors.IsNotFound(err) {
+		return nil, errConnectionNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return c.client(ctx, connectionKey{langfusev1alpha1.LangfuseConnectionKind, key}, conn.Generation,
+		&conn.Spec, namespace)
+}
+
+// ClusterClient returns the client of the ClusterLangfuseConnection name.
+// It does not check the namespaceSelector, see Resolve.
+func (c *Connections) ClusterClient(ctx context.Context, name string) (*langfuse.Client, error) {
+	var conn langfusev1alpha1.ClusterLangfuseConnection
+	if err := c.Reader.Get(ctx, types.NamespacedName{Name: name}, &conn); apierrors.IsNotFound(err) {
+		return nil, errConnectionNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return c.clusterClient(ctx, &conn)
+}
+
+func (c *Connections) clusterClient(ctx context.Context,
+	conn *langfusev1alpha1.ClusterLangfuseConnection) (*langfuse.Client, error) {
+	if c.ClusterResourceNamespace == "" {
+		return nil, errors.New("no namespace is configured for the Secrets of ClusterLangfuseConnections")
+	}
+	key := connectionKey{langfusev1alpha1.ClusterLangfuseConnectionKind, types.NamespacedName{Name: conn.Name}}
+	return c.client(ctx, key, conn.Generation, &conn.Spec.LangfuseConnectionSpec, c.ClusterResourceNamespace)
+}
+
+// client returns the pooled client of a connection with spec, whose
+// Secrets are in secretNamespace, building it if it is missing or stale.
+// The capabilities of a new client are detected before it is pooled, so
+// that its resources are not assumed to be supported by older servers.
+func (c *Connections) client(ctx context.Context, key connectionKey, generation int64,
+	spec *langfusev1alpha1.LangfuseConnectionSpec, secretNamespace string) (*langfuse.Client, error) {
+	host, err := c.host(ctx, spec, secretNamespace)
+	if err != nil {
+		return nil, err
+	}
+	secrets, err := c.secrets(ctx, spec, secretNamespace)
+	if err != nil {
+		return nil, err
+	}
+	version := connectionVersion(generation, host, secrets)
+
+	if lf := c.pooled(key, version); lf != nil {
+		return lf, nil
+	}
+	lf, err := c.newClient(spec, host, secrets)
+	if err != nil {
+		return nil, err
+	}
+	// Without an answer the client assumes every capability, like the
+	// default client before its CapabilityDetector ran; the
+	// LangfuseConnection reconciler detects them again later.
+	if _, err := lf.DetectCapabilities(ctx); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to detect Langfuse capabilities", "host", host)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if pooled, ok := c.clients[key]; ok && pooled.version == version {
+		// Another reconcile built the same client in the meantime.
+		return pooled.client, nil
+	}
+	if c.clients == nil {
+		c.clients = map[connectionKey]*pooledClient{}
+	}
+	if stale, ok := c.clients[key]; ok {
+		stale.close()
+	}
+	pooled := &pooledClient{version: version, client: lf}
+	if lf.Breaker != nil {
+		// The probe outlives the reconcile that built the client.
+		var probeCtx context.Context
+		probeCtx, pooled.stopProbe = context.WithCancel(context.WithoutCancel(ctx))
+		probe := &langfuse.BreakerProbe{Client: lf, Interval: c.BreakerProbeInterval}
+		go func() { _ = probe.Start(probeCtx) }()
+	}
+	c.clients[key] = pooled
+	return lf, nil
+}
+
+// pooled returns the pooled client of key if it was built from version.
+func (c *Connections) pooled(key connectionKey, version string) *langfuse.Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if pooled, ok := c.clients[key]; ok && pooled.version == version {
+		return pooled.client
+	}
+	return nil
+}
+
+// Forget drops the client of a deleted connection of kind and stops its
+// BreakerProbe. The namespace of a ClusterLangfuseConnection is empty.
+func (c *Connections) Forget(kind string, key types.NamespacedName) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if pooled, ok := c.clients[connectionKey{kind, key}]; ok {
+		pooled.close()
+		delete(c.clients, connectionKey{kind, key})
+	}
+}
+
+// host returns the base URL of a connection, which is the endpoint of the
+// LangfuseServer in namespace if it has a serverRef.
+func (c *Connections) host(ctx context.Context, spec *langfusev1alpha1.LangfuseConnectionSpec,
+	namespace string) (string, error) {
+	if spec.ServerRef == nil {
+		return strings.TrimSuffix(spec.Host, "/"), nil
+	}
+	var server langfusev1alpha1.LangfuseServer
+	key := types.NamespacedName{Namespace: namespace, Name: spec.ServerRef.Name}
+	if err := c.Reader.Get(ctx, key, &server); err != nil {
+		return "", fmt.Errorf("reading LangfuseServer %s: %w", key, err)
+	}
+	if server.Status.URL == "" {
+		return "", fmt.Errorf("LangfuseServer %s has no endpoint yet", key)
+	}
+	return server.Status.URL, nil
+}
+
+// connectionSecrets are the Secrets a connection refers to.
+type connectionSecrets struct {
+	credentials *corev1.Secret
+	ca          *corev1.Secret
+	clientCert  *corev1.Secret
+}
+
+func (c *Connections) secrets(ctx context.Context, spec *langfusev1alpha1.LangfuseConnectionSpec,
+	namespace string) (*connectionSecrets, error) {
+	get := func(name string) (*corev1.Secret, error) {
+		secret := &corev1.Secret{}
+		if err := c.Reader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, secret); err != nil {
+			return nil, fmt.Errorf("reading Secret %s/%s: %w", namespace, name, err)
+		}
+		return secret, nil
+	}
+	var secrets connectionSecrets
+	var err error
+	if secrets.credentials, err = get(spec.CredentialsSecretRef.Name); err != nil {
+		return nil, err
+	}
+	if tls := spec.TLS; tls != nil {
+		if tls.CASecretRef != nil {
+			if secrets.ca, err = get(tls.CASecretRef.Name); err != nil {
+				return nil, err
+			}
+		}
+		if tls.ClientCertSecretRef != nil {
+			if secrets.clientCert, err = get(tls.ClientCertSecretRef.Name); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &secrets, nil
+}
+
+// connectionVersion changes whenever the connection, its host or one of its
+// Secrets does.
+func connectionVersion(generation int64, host string, secrets *connectionSecrets) string {
+	versions := []string{fmt.Sprint(generation), host}
+	for _, secret := range []*corev1.Secret{secrets.credentials, secrets.ca, secrets.clientCert} {
+		if secret != nil {
+			versions = append(versions, string(secret.UID)+"/"+secret.ResourceVersion)
+		} else {
+			versions = append(versions, "")
+		}
+	}
+	return strings.Join(versions, ",")
+}
+
+func (c *Connections) newClient(spec *langfusev1alpha1.LangfuseConnectionSpec, host string,
+	secrets *connectionSecrets) (*langfuse.Client, error) {
+	ref := spec.CredentialsSecretRef
+	publicKeyKey, secretKeyKey := ref.PublicKeyKey, ref.SecretKeyKey
+	if publicKeyKey == "" {
+		publicKeyKey = "LANGFUSE_PUBLIC_KEY"
+	}
+	if secretKeyKey == "" {
+		secretKeyKey = "LANGFUSE_SECRET_KEY"
+	}
+	publicKey := string(secrets.credentials.Data[publicKeyKey])
+	secretKey := string(secrets.credentials.Data[secretKeyKey])
+	if publicKey == "" || secretKey == "" {
+		return nil, fmt.Errorf("Secret %s has no %s and %s", ref.Name, publicKeyKey, secretKeyKey)
+	}
+
+	transportOpts := langfuse.TransportOptions{ProxyURL: c.Transport.ProxyURL, NoProxy: c.Transport.NoProxy}
+	if tls := spec.TLS; tls != nil {
+		transportOpts.InsecureSkipVerify = tls.InsecureSkipVerify
+		if secrets.ca != nil {
+			transportOpts.CAData = secrets.ca.Data[tls.CASecretRef.Key]
+			if len(transportOpts.CAData) == 0 {
+				return nil, fmt.Errorf("Secret %s has no %s", tls.CASecretRef.Name, tls.CASecretRef.Key)
+			}
+		}
+		if secrets.clientCert != nil {
+			transportOpts.CertData = secrets.clientCert.Data[corev1.TLSCertKey]
+			transportOpts.KeyData = secrets.clientCert.Data[corev1.TLSPrivateKeyKey]
+			if len(transportOpts.CertData) == 0 || len(transportOpts.KeyData) == 0 {
+				return nil, fmt.Errorf("Secret %s has no %s and %s", secrets.clientCert.Name,
+					corev1.TLSCertKey, corev1.TLSPrivateKeyKey)
+			}
+		}
+	}
+	transport, err := langfuse.NewTransport(transportOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := c.Options
+	opts.BaseURL = host
+	opts.PublicKey, opts.SecretKey = publicKey, secretKey
+	opts.Transport = transport
+	opts.SkipServerMetrics = true
+	return langfuse.NewClient(opts), nil
+}
+
+// errConnectionNotFound is returned for references to a LangfuseConnection
+// that does not exist.
+var errConnectionNotFound = errors.New("not found")
+
+// errConnectionNotAllowed is returned for references to a
+// ClusterLangfuseConnection that does not select the resource's namespace.
+var errConnectionNotAllowed = errors.New("not allowed")
+
+// errAmbiguousConnection is returned for resources without a connectionRef
+// whose namespace is selected by more than one ClusterLangfuseConnection.
+var errAmbiguousConnection = errors.New("ambiguous connection")
+
+// errNoConnections is returned for resources with a connectionRef when the
+// manager was started without a Connections pool.
+var errNoConnections = errors.New("LangfuseConnections are not enabled in this manager")
+
+// defaultConnection is recorded in status.connection for resources
+// managed in the instance the manager is configured with.
+const defaultConnection = "default"
+
+// langfuseFor returns the client of the Langfuse instance a resource in
+// namespace with the connectionRef ref is managed in, and its connection,
+// see targetConnection. pinned is the connection recorded in the status of
+// the resource once its Langfuse object was created; if set, it is used
+// instead, so that new or changed connections do not move the resource to
+// another instance.
+func langfuseFor(ctx context.Context, conns *Connections, def langfuse.LangfuseAPI, namespace string,
+	ref *langfusev1alpha1.ConnectionReference, pinned string) (langfuse.LangfuseAPI, string, error) {
+	connection := pinned
+	if connection == "" {
+		var err error
+		if connection, err = targetConnection(ctx, conns, namespace, ref); err != nil {
+			return nil, "", err
+		}
+	}
+	if connection == defaultConnection {
+		return def, connection, nil
+	}
+	if conns == nil {
+		return nil, "", errNoConnections
+	}
+	lf, err := conns.ClientOf(ctx, namespace, connection)
+	if err != nil {
+		return nil, "", err
+	}
+	return lf, connection, nil
+}
+
+// targetConnection returns the connection a resource in namespace with the
+// connectionRef ref is to be managed in, see Connections.Target. It is
+// defaultConnection if there is no connection to use.
+func targetConnection(ctx context.Context, conns *Connections, namespace string,
+	ref *langfusev1alpha1.ConnectionReference) (string, error) {
+	if conns == nil {
+		if ref != nil {
+			return "", errNoConnections
+		}
+		return defaultConnection, nil
+	}
+	connection, err := conns.Target(ctx, namespace, ref)
+	if err != nil || connection != "" {
+		return connection, err
+	}
+	return defaultConnection, nil
+}
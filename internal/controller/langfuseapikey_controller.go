@@ -18,6 +18,7 @@ package controller
 
 import (
 	"context"
+	"fmt"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -28,6 +29,7 @@ import (
 	langfusev1alpha1 "github.com/sqaisar/langfuse-controller/api/v1alpha1"
 	"github.com/sqaisar/langfuse-controller/internal/langfuse"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 )
@@ -36,7 +38,10 @@ import (
 type LangfuseAPIKeyReconciler struct {
 	client.Client
 	Scheme         *runtime.Scheme
-	LangfuseClient *langfuse.Client
+	LangfuseClient langfuse.LangfuseAPI
+	// Connections, if set, provides the clients of resources managed in
+	// a LangfuseConnection. Other resources use LangfuseClient.
+	Connections *Connections
 }
 
 // +kubebuilder:rbac:groups=langfuse.io,resources=langfuseapikeys,verbs=get;list;watch;create;update;patch;delete
@@ -60,7 +65,7 @@ func (r *LangfuseAPIKeyReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
-	if len(apiKey.Status.Conditions) > 0 && apiKey.Status.Conditions[0].Status == metav1.ConditionTrue {
+	if meta.IsStatusConditionTrue(apiKey.Status.Conditions, "Available") {
 		return ctrl.Result{}, nil
 	}
 
@@ -76,34 +81,83 @@ func (r *LangfuseAPIKeyReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		return ctrl.Result{Requeue: true}, nil
 	}
 
-	log.Info("Creating Langfuse API Key", "name", apiKey.Spec.Name, "projectID", project.Status.ID)
-	lfAPIKey, err := r.LangfuseClient.CreateAPIKey(project.Status.ID, apiKey.Spec.Name)
-	if err != nil {
-		log.Error(err, "Failed to create API Key")
-		return ctrl.Result{}, err
+	lf, _, ok, err := requireConnection(ctx, r.Client, r.Connections, r.LangfuseClient, project.Namespace,
+		project.Spec.ConnectionRef, project.Status.Connection, &apiKey, &apiKey.Status.Conditions)
+	if !ok || err != nil {
+		return ctrl.Result{RequeueAfter: connectionRequeueAfter}, err
 	}
-
-	// Create Secret
-	secret := &corev1.Secret{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      apiKey.Spec.SecretName,
-			Namespace: apiKey.Namespace,
-		},
-		StringData: map[string]string{
-			"LANGFUSE_PUBLIC_KEY": lfAPIKey.PublicKey,
-			"LANGFUSE_SECRET_KEY": lfAPIKey.SecretKey,
-			"LANGFUSE_HOST":       r.LangfuseClient.BaseURL,
-		},
+	if ok, err := requireLangfuse(ctx, r.Client, lf, &apiKey, &apiKey.Status.Conditions); !ok || err != nil {
+		return ctrl.Result{RequeueAfter: unavailableRequeueAfter}, err
+	}
+	if ok, err := requireCapability(ctx, r.Client, lf, langfuse.CapabilityOrganizations, &apiKey,
+		&apiKey.Status.Conditions); !ok || err != nil {
+		return ctrl.Result{RequeueAfter: unsupportedRequeueAfter}, err
 	}
-	if err := ctrl.SetControllerReference(&apiKey, secret, r.Scheme); err != nil {
+
+	secret := &corev1.Secret{}
+	secretKey := types.NamespacedName{Name: apiKey.Spec.SecretName, Namespace: apiKey.Namespace}
+	err = r.Get(ctx, secretKey, secret)
+	if client.IgnoreNotFound(err) != nil {
 		return ctrl.Result{}, err
 	}
+	secretExists := err == nil
+	if secretExists && !metav1.IsControlledBy(secret, &apiKey) {
+		return ctrl.Result{}, fmt.Errorf("secret %s already exists and is not managed by this LangfuseAPIKey",
+			apiKey.Spec.SecretName)
+	}
+
+	// Keys created by an earlier, interrupted reconcile carry this note. The
+	// secret key cannot be read back, so one is only adopted if it is the key
+	// stored in the Secret; others are revoked.
+	note := fmt.Sprintf("%s [%s]", apiKey.Spec.Name, ownershipMarker("LangfuseAPIKey", &apiKey))
+	adopted := false
+	for key, err := range lf.ListAPIKeys(ctx, project.Status.ID) {
+		if err != nil {
+			log.Error(err, "Failed to list API Keys")
+			return langfuseError(ctx, r.Client, &apiKey, &apiKey.Status.Conditions, err)
+		}
+		if key.Note != note {
+			continue
+		}
+		if secretExists && string(secret.Data["LANGFUSE_PUBLIC_KEY"]) == key.PublicKey {
+			log.Info("Adopting existing Langfuse API Key", "id", key.ID)
+			adopted = true
+			continue
+		}
+		log.Info("Revoking unstored Langfuse API Key", "id", key.ID)
+		if err := lf.DeleteAPIKey(ctx, project.Status.ID, key.ID); err != nil && !langfuse.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+	}
+
+	if !adopted {
+		log.Info("Creating Langfuse API Key", "name", apiKey.Spec.Name, "projectID", project.Status.ID)
+		lfAPIKey, err := lf.CreateAPIKey(ctx, project.Status.ID, note)
+		if err != nil {
+			log.Error(err, "Failed to create API Key")
+			return langfuseError(ctx, r.Client, &apiKey, &apiKey.Status.Conditions, err)
+		}
 
-	if err := r.Create(ctx, secret); err != nil {
-		if client.IgnoreAlreadyExists(err) != nil {
+		secret.Name, secret.Namespace = secretKey.Name, secretKey.Namespace
+		secret.Annotations = map[string]string{AnnotationAPIKeyID: lfAPIKey.ID}
+		secret.Data = map[string][]byte{
+			"LANGFUSE_PUBLIC_KEY": []byte(lfAPIKey.PublicKey),
+			"LANGFUSE_SECRET_KEY": []byte(lfAPIKey.SecretKey),
+			"LANGFUSE_HOST":       []byte(lf.Host()),
+		}
+		if err := ctrl.SetControllerReference(&apiKey, secret, r.Scheme); err != nil {
+			return ctrl.Result{}, err
+		}
+		if secretExists {
+			err = r.Update(ctx, secret)
+		} else {
+			err = r.Create(ctx, secret)
+		}
+		if err != nil {
+			// Without the Secret nobody can use the key; the next reconcile
+			// revokes it by its note.
 			return ctrl.Result{}, err
 		}
-		// If exists, update? For now assume immutable or manual fix.
 	}
 
 	// Update Status
@@ -131,5 +185,5 @@ func (r *LangfuseAPIKeyReconciler) SetupWithManager(mgr ctrl.Manager) error {
 			MaxConcurrentReconciles: 1, // avoids event storms
 		}).
 		Named("langfuseapikey").
-		Complete(r)
+		Complete(traced("LangfuseAPIKey", r))
 }
@@ -21,6 +21,7 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -28,6 +29,8 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	langfusev1alpha1 "github.com/sqaisar/langfuse-controller/api/v1alpha1"
+	"github.com/sqaisar/langfuse-controller/internal/langfuse"
+	"github.com/sqaisar/langfuse-controller/internal/langfuse/fake"
 )
 
 var _ = Describe("LangfuseAPIKey Controller", func() {
@@ -40,46 +43,109 @@ var _ = Describe("LangfuseAPIKey Controller", func() {
 			Name:      resourceName,
 			Namespace: "default", // TODO(user):Modify as needed
 		}
+		projectNamespacedName := types.NamespacedName{
+			Name:      "langfuseapikey-project",
+			Namespace: "default",
+		}
 		langfuseapikey := &langfusev1alpha1.LangfuseAPIKey{}
 
+		var lfClient *fake.Client
+		var projectID string
+
 		BeforeEach(func() {
+			By("creating the Langfuse project and its LangfuseProject")
+			lfClient = fake.NewClient()
+			lfProject, err := lfClient.CreateProject(ctx, langfuse.CreateProjectRequest{Name: "LangfuseAPIKey Test Project"})
+			Expect(err).NotTo(HaveOccurred())
+			projectID = lfProject.ID
+			createReadyProject(ctx, projectNamespacedName, projectID)
+
 			By("creating the custom resource for the Kind LangfuseAPIKey")
-			err := k8sClient.Get(ctx, typeNamespacedName, langfuseapikey)
+			err = k8sClient.Get(ctx, typeNamespacedName, langfuseapikey)
 			if err != nil && errors.IsNotFound(err) {
 				resource := &langfusev1alpha1.LangfuseAPIKey{
 					ObjectMeta: metav1.ObjectMeta{
 						Name:      resourceName,
 						Namespace: "default",
 					},
-					// TODO(user): Specify other spec details if needed.
+					Spec: langfusev1alpha1.LangfuseAPIKeySpec{
+						ProjectRef: projectNamespacedName.Name,
+						Name:       "test-key",
+						SecretName: "langfuseapikey-credentials",
+					},
 				}
 				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
 			}
 		})
 
 		AfterEach(func() {
-			// TODO(user): Cleanup logic after each test, like removing the resource instance.
 			resource := &langfusev1alpha1.LangfuseAPIKey{}
 			err := k8sClient.Get(ctx, typeNamespacedName, resource)
 			Expect(err).NotTo(HaveOccurred())
 
 			By("Cleanup the specific resource instance LangfuseAPIKey")
 			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+			deleteProject(ctx, projectNamespacedName)
 		})
 		It("should successfully reconcile the resource", func() {
-			Skip("Requires Langfuse API - add mocking for unit tests")
 			By("Reconciling the created resource")
 			controllerReconciler := &LangfuseAPIKeyReconciler{
-				Client: k8sClient,
-				Scheme: k8sClient.Scheme(),
+				Client:         k8sClient,
+				Scheme:         k8sClient.Scheme(),
+				LangfuseClient: lfClient,
 			}
 
 			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
 				NamespacedName: typeNamespacedName,
 			})
 			Expect(err).NotTo(HaveOccurred())
-			// TODO(user): Add more specific assertions depending on your controller's reconciliation logic.
-			// Example: If you expect a certain status condition after reconciliation, verify it here.
+
+			By("Checking the key was created and stored in a Secret")
+			keys := lfClient.APIKeys(projectID)
+			Expect(keys).To(HaveLen(1))
+			secret := &corev1.Secret{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{
+				Name:      "langfuseapikey-credentials",
+				Namespace: "default",
+			}, secret)).To(Succeed())
+			Expect(string(secret.Data["LANGFUSE_PUBLIC_KEY"])).To(Equal(keys[0].PublicKey))
+			Expect(string(secret.Data["LANGFUSE_SECRET_KEY"])).To(Equal(keys[0].SecretKey))
+			Expect(string(secret.Data["LANGFUSE_HOST"])).To(Equal(lfClient.Host()))
+
+			resource := &langfusev1alpha1.LangfuseAPIKey{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.Conditions).To(HaveLen(1))
+			Expect(resource.Status.Conditions[0].Type).To(Equal("Available"))
+			Expect(resource.Status.Conditions[0].Status).To(Equal(metav1.ConditionTrue))
+
+			By("Reconciling again after a lost status update")
+			resource.Status.Conditions = nil
+			Expect(k8sClient.Status().Update(ctx, resource)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lfClient.APIKeys(projectID)).To(HaveLen(1))
+			Expect(lfClient.Calls("CreateAPIKey")).To(Equal(1))
+		})
+
+		It("should requeue while the project is not ready", func() {
+			project := &langfusev1alpha1.LangfuseProject{}
+			Expect(k8sClient.Get(ctx, projectNamespacedName, project)).To(Succeed())
+			project.Status.ID = ""
+			Expect(k8sClient.Status().Update(ctx, project)).To(Succeed())
+
+			controllerReconciler := &LangfuseAPIKeyReconciler{
+				Client:         k8sClient,
+				Scheme:         k8sClient.Scheme(),
+				LangfuseClient: lfClient,
+			}
+			result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Requeue).To(BeTrue())
+			Expect(lfClient.Calls("CreateAPIKey")).To(BeZero())
 		})
 	})
 })
@@ -0,0 +1,148 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	langfusev1alpha1 "github.com/sqaisar/langfuse-controller/api/v1alpha1"
+	"github.com/sqaisar/langfuse-controller/internal/langfuse"
+)
+
+const (
+	// ConditionReady reports whether a LangfuseConnection's instance is
+	// reachable and accepts its credentials.
+	ConditionReady = "Ready"
+
+	ReasonConnected = "Connected"
+)
+
+// connectionResyncInterval is how often a ready LangfuseConnection is
+// checked again and its server capabilities probed. Connections that are
+// not ready are checked every unavailableRequeueAfter.
+const connectionResyncInterval = 5 * time.Minute
+
+// LangfuseConnectionReconciler reconciles a LangfuseConnection object
+type LangfuseConnectionReconciler struct {
+	client.Client
+	Scheme      *runtime.Scheme
+	Connections *Connections
+}
+
+// +kubebuilder:rbac:groups=langfuse.io,resources=langfuseconnections,verbs=get;list;watch
+// +kubebuilder:rbac:groups=langfuse.io,resources=langfuseconnections/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
+
+// Reconcile checks that the Langfuse instance of a LangfuseConnection is
+// reachable and accepts its credentials, and reports the result in the
+// Ready condition. It also probes the server's capabilities, so that
+// resources managed through the connection are marked Unsupported instead
+// of failing, and closes the client's circuit breaker once the instance
+// is back.
+func (r *LangfuseConnectionReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	var conn langfusev1alpha1.LangfuseConnection
+	if err := r.Get(ctx, req.NamespacedName, &conn); err != nil {
+		if client.IgnoreNotFound(err) == nil {
+			r.Connections.Forget(langfusev1alpha1.LangfuseConnectionKind, req.NamespacedName)
+		}
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	lf, err := r.Connections.Client(ctx, conn.Namespace, conn.Name)
+	changed, requeueAfter, err := checkConnection(ctx, lf, err, conn.Generation, &conn.Status)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if changed {
+		if err := r.Status().Update(ctx, &conn); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// checkConnection checks the instance of a connection whose client was
+// built with the error clientErr, and records the result in status. It
+// reports whether status changed and when to check again.
+func checkConnection(ctx context.Context, lf *langfuse.Client, clientErr error, generation int64,
+	status *langfusev1alpha1.LangfuseConnectionStatus) (bool, time.Duration, error) {
+	log := logf.FromContext(ctx)
+	cond := metav1.Condition{
+		Type:               ConditionReady,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: generation,
+	}
+	version := status.Version
+	requeueAfter := unavailableRequeueAfter
+	err := clientErr
+	if err == nil {
+		checkCtx, cancel := context.WithTimeout(ctx, langfuse.DefaultReadinessTimeout)
+		err = lf.Ready(checkCtx)
+		cancel()
+	}
+	var readinessErr *langfuse.ReadinessError
+	switch {
+	case errors.As(err, &readinessErr):
+		cond.Reason, cond.Message = string(readinessErr.Failure), readinessErr.Err.Error()
+	case err != nil:
+		cond.Reason, cond.Message = ReasonInvalidConnection, err.Error()
+	default:
+		caps, err := lf.DetectCapabilities(ctx)
+		if err != nil {
+			log.Error(err, "Failed to detect Langfuse capabilities", "host", lf.Host())
+			return false, 0, err
+		}
+		version = caps.Version
+		cond.Status, cond.Reason = metav1.ConditionTrue, ReasonConnected
+		cond.Message = fmt.Sprintf("Connected to Langfuse %s at %s", version, lf.Host())
+		requeueAfter = connectionResyncInterval
+	}
+
+	previous := meta.FindStatusCondition(status.Conditions, ConditionReady)
+	if previous == nil || previous.Status != cond.Status {
+		if cond.Status == metav1.ConditionTrue {
+			log.Info("Langfuse connection is ready", "host", lf.Host(), "version", version)
+		} else {
+			log.Info("Langfuse connection is not ready", "reason", cond.Reason, "message", cond.Message)
+		}
+	}
+	changed := meta.SetStatusCondition(&status.Conditions, cond) || version != status.Version
+	status.Version = version
+	return changed, requeueAfter, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *LangfuseConnectionReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&langfusev1alpha1.LangfuseConnection{}).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: 1, // avoids event storms
+		}).
+		Named("langfuseconnection").
+		Complete(traced("LangfuseConnection", r))
+}
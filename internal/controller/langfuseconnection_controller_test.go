@@ -0,0 +1,294 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	langfusev1alpha1 "github.com/sqaisar/langfuse-controller/api/v1alpha1"
+	"github.com/sqaisar/langfuse-controller/internal/langfuse"
+	"github.com/sqaisar/langfuse-controller/internal/langfuse/fake"
+	"github.com/sqaisar/langfuse-controller/pkg/langfusetest"
+)
+
+var _ = Describe("LangfuseConnection Controller", func() {
+	ctx := context.Background()
+	connectionKey := types.NamespacedName{Name: "regional", Namespace: "default"}
+	secretKey := types.NamespacedName{Name: "regional-credentials", Namespace: "default"}
+	projectKey := types.NamespacedName{Name: "regional-project", Namespace: "default"}
+
+	var server *langfusetest.Server
+	var defaultClient *fake.Client
+	var connections *Connections
+
+	BeforeEach(func() {
+		server = langfusetest.NewServer()
+		defaultClient = fake.NewClient()
+		connections = &Connections{
+			Reader: k8sClient,
+			Options: langfuse.Options{
+				RetryWaitMin:       time.Millisecond,
+				RetryWaitMax:       10 * time.Millisecond,
+				ProjectCredentials: &ProjectCredentials{Reader: k8sClient},
+			},
+		}
+
+		By("creating the credentials Secret and the LangfuseConnection")
+		Expect(k8sClient.Create(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: secretKey.Name, Namespace: secretKey.Namespace},
+			StringData: map[string]string{
+				"LANGFUSE_PUBLIC_KEY": server.PublicKey,
+				"LANGFUSE_SECRET_KEY": server.SecretKey,
+			},
+		})).To(Succeed())
+		Expect(k8sClient.Create(ctx, &langfusev1alpha1.LangfuseConnection{
+			ObjectMeta: metav1.ObjectMeta{Name: connectionKey.Name, Namespace: connectionKey.Namespace},
+			Spec: langfusev1alpha1.LangfuseConnectionSpec{
+				Host:                 server.URL,
+				CredentialsSecretRef: langfusev1alpha1.CredentialsSecretReference{Name: secretKey.Name},
+			},
+		})).To(Succeed())
+	})
+
+	AfterEach(func() {
+		server.Close()
+		for _, obj := range []struct {
+			key types.NamespacedName
+			obj client.Object
+		}{
+			{projectKey, &langfusev1alpha1.LangfuseProject{}},
+			{connectionKey, &langfusev1alpha1.LangfuseConnection{}},
+			{secretKey, &corev1.Secret{}},
+		} {
+			if err := k8sClient.Get(ctx, obj.key, obj.obj); err == nil {
+				deleteAndRelease(ctx, obj.obj)
+			}
+		}
+	})
+
+	reconcileConnection := func() *langfusev1alpha1.LangfuseConnection {
+		reconciler := &LangfuseConnectionReconciler{
+			Client:      k8sClient,
+			Scheme:      k8sClient.Scheme(),
+			Connections: connections,
+		}
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: connectionKey})
+		Expect(err).NotTo(HaveOccurred())
+		conn := &langfusev1alpha1.LangfuseConnection{}
+		Expect(k8sClient.Get(ctx, connectionKey, conn)).To(Succeed())
+		return conn
+	}
+
+	It("should report a reachable instance as Ready", func() {
+		conn := reconcileConnection()
+		Expect(meta.IsStatusConditionTrue(conn.Status.Conditions, ConditionReady)).To(BeTrue())
+		Expect(conn.Status.Version).To(Equal(langfusetest.DefaultVersion))
+	})
+
+	It("should detect the capabilities of a new client", func() {
+		server.Disable(langfusetest.CapabilityLlmConnections)
+		lf, err := connections.Client(ctx, connectionKey.Namespace, connectionKey.Name)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(lf.Supports(langfuse.CapabilityLlmConnections)).To(BeFalse())
+		Expect(lf.Supports(langfuse.CapabilityPrompts)).To(BeTrue())
+	})
+
+	It("should probe the instance of a pooled client while its circuit breaker is open", func() {
+		connections.Options.MaxRetries = -1
+		connections.Options.BreakerThreshold = 1
+		connections.BreakerProbeInterval = 10 * time.Millisecond
+		lf, err := connections.Client(ctx, connectionKey.Namespace, connectionKey.Name)
+		Expect(err).NotTo(HaveOccurred())
+
+		server.InjectFault(langfusetest.Fault{Path: "/api/public/projects", StatusCode: http.StatusBadGateway, Times: 1})
+		_, err = langfuse.Collect(lf.ListProjects(ctx))
+		Expect(err).To(HaveOccurred())
+		Expect(lf.Available()).To(BeFalse())
+		Eventually(lf.Available).WithTimeout(time.Second).Should(BeTrue())
+
+		By("stopping the probe once the connection is forgotten")
+		connections.Forget(langfusev1alpha1.LangfuseConnectionKind, connectionKey)
+		server.InjectFault(langfusetest.Fault{Path: "/api/public/projects", StatusCode: http.StatusBadGateway, Times: 1})
+		_, err = langfuse.Collect(lf.ListProjects(ctx))
+		Expect(err).To(HaveOccurred())
+		Consistently(lf.Available).WithTimeout(100 * time.Millisecond).Should(BeFalse())
+	})
+
+	It("should report rejected credentials", func() {
+		secret := &corev1.Secret{}
+		Expect(k8sClient.Get(ctx, secretKey, secret)).To(Succeed())
+		secret.Data["LANGFUSE_SECRET_KEY"] = []byte("sk-lf-wrong")
+		Expect(k8sClient.Update(ctx, secret)).To(Succeed())
+
+		conn := reconcileConnection()
+		cond := meta.FindStatusCondition(conn.Status.Conditions, ConditionReady)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+		Expect(cond.Reason).To(Equal(string(langfuse.FailureAuthentication)))
+	})
+
+	It("should manage a project in the instance of its connection", func() {
+		Expect(k8sClient.Create(ctx, &langfusev1alpha1.LangfuseProject{
+			ObjectMeta: metav1.ObjectMeta{Name: projectKey.Name, Namespace: projectKey.Namespace},
+			Spec: langfusev1alpha1.LangfuseProjectSpec{
+				Name:          "Regional Project",
+				ConnectionRef: &langfusev1alpha1.ConnectionReference{Name: connectionKey.Name},
+			},
+		})).To(Succeed())
+		reconciler := &LangfuseProjectReconciler{
+			Client:         k8sClient,
+			Scheme:         k8sClient.Scheme(),
+			LangfuseClient: defaultClient,
+			Connections:    connections,
+		}
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: projectKey})
+		Expect(err).NotTo(HaveOccurred())
+
+		project := &langfusev1alpha1.LangfuseProject{}
+		Expect(k8sClient.Get(ctx, projectKey, project)).To(Succeed())
+		Expect(project.Status.ID).NotTo(BeEmpty())
+		Expect(server.Projects()).To(HaveLen(1))
+		Expect(defaultClient.Projects()).To(BeEmpty())
+
+		By("writing the connection's host into the internal key Secret")
+		secret := &corev1.Secret{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{
+			Name: projectKey.Name + projectKeySecretSuffix, Namespace: projectKey.Namespace,
+		}, secret)).To(Succeed())
+		Expect(string(secret.Data["LANGFUSE_HOST"])).To(Equal(server.URL))
+		Expect(k8sClient.Delete(ctx, secret)).To(Succeed())
+	})
+
+	It("should keep a model in the instance it was created in", func() {
+		modelKey := types.NamespacedName{Name: "regional-model", Namespace: "default"}
+		Expect(k8sClient.Create(ctx, &langfusev1alpha1.LangfuseModel{
+			ObjectMeta: metav1.ObjectMeta{Name: modelKey.Name, Namespace: modelKey.Namespace},
+			Spec: langfusev1alpha1.LangfuseModelSpec{
+				ModelName:     "regional-model",
+				MatchPattern:  "(?i)^(regional-model)$",
+				Unit:          "TOKENS",
+				ConnectionRef: &langfusev1alpha1.ConnectionReference{Name: connectionKey.Name},
+			},
+		})).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, &langfusev1alpha1.LangfuseModel{ObjectMeta: metav1.ObjectMeta{
+				Name: modelKey.Name, Namespace: modelKey.Namespace,
+			}})).To(Succeed())
+		})
+		reconciler := &LangfuseModelReconciler{
+			Client:         k8sClient,
+			Scheme:         k8sClient.Scheme(),
+			LangfuseClient: defaultClient,
+			Connections:    connections,
+		}
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: modelKey})
+		Expect(err).NotTo(HaveOccurred())
+		model := &langfusev1alpha1.LangfuseModel{}
+		Expect(k8sClient.Get(ctx, modelKey, model)).To(Succeed())
+		Expect(model.Status.Connection).To(Equal("LangfuseConnection/" + connectionKey.Name))
+		Expect(server.Models()).To(HaveLen(1))
+
+		By("ignoring a changed connectionRef")
+		model.Spec.ConnectionRef = nil
+		Expect(k8sClient.Update(ctx, model)).To(Succeed())
+		_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: modelKey})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(server.Models()).To(HaveLen(1))
+		Expect(defaultClient.Models()).To(BeEmpty())
+		Expect(k8sClient.Get(ctx, modelKey, model)).To(Succeed())
+		Expect(meta.IsStatusConditionTrue(model.Status.Conditions, ConditionConnectionMismatch)).To(BeTrue())
+		Expect(meta.IsStatusConditionTrue(model.Status.Conditions, ConditionAvailable)).To(BeTrue())
+	})
+
+	It("should release a project to be deleted whose connection was deleted first", func() {
+		Expect(k8sClient.Create(ctx, &langfusev1alpha1.LangfuseProject{
+			ObjectMeta: metav1.ObjectMeta{Name: projectKey.Name, Namespace: projectKey.Namespace},
+			Spec: langfusev1alpha1.LangfuseProjectSpec{
+				Name:           "Regional Project",
+				ConnectionRef:  &langfusev1alpha1.ConnectionReference{Name: connectionKey.Name},
+				DeletionPolicy: langfusev1alpha1.DeletionPolicyDelete,
+			},
+		})).To(Succeed())
+		recorder := record.NewFakeRecorder(10)
+		reconciler := &LangfuseProjectReconciler{
+			Client:         k8sClient,
+			Scheme:         k8sClient.Scheme(),
+			LangfuseClient: defaultClient,
+			Connections:    connections,
+			Recorder:       recorder,
+		}
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: projectKey})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(server.Projects()).To(HaveLen(1))
+
+		By("deleting the connection before the project")
+		Expect(k8sClient.Delete(ctx, &langfusev1alpha1.LangfuseConnection{ObjectMeta: metav1.ObjectMeta{
+			Name: connectionKey.Name, Namespace: connectionKey.Namespace,
+		}})).To(Succeed())
+		project := &langfusev1alpha1.LangfuseProject{}
+		Expect(k8sClient.Get(ctx, projectKey, project)).To(Succeed())
+		Expect(k8sClient.Delete(ctx, project)).To(Succeed())
+
+		_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: projectKey})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k8sClient.Get(ctx, projectKey, project)).To(Satisfy(apierrors.IsNotFound))
+		Expect(server.Projects()).To(HaveLen(1))
+		Expect(recorder.Events).To(Receive(ContainSubstring("Warning " + ReasonConnectionNotFound)))
+		Expect(k8sClient.Delete(ctx, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+			Name: projectKey.Name + projectKeySecretSuffix, Namespace: projectKey.Namespace,
+		}})).To(Succeed())
+	})
+
+	It("should mark resources whose connection does not exist", func() {
+		Expect(k8sClient.Create(ctx, &langfusev1alpha1.LangfuseProject{
+			ObjectMeta: metav1.ObjectMeta{Name: projectKey.Name, Namespace: projectKey.Namespace},
+			Spec: langfusev1alpha1.LangfuseProjectSpec{
+				Name:          "Regional Project",
+				ConnectionRef: &langfusev1alpha1.ConnectionReference{Name: "missing"},
+			},
+		})).To(Succeed())
+		reconciler := &LangfuseProjectReconciler{
+			Client:         k8sClient,
+			Scheme:         k8sClient.Scheme(),
+			LangfuseClient: defaultClient,
+			Connections:    connections,
+		}
+		result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: projectKey})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(connectionRequeueAfter))
+
+		project := &langfusev1alpha1.LangfuseProject{}
+		Expect(k8sClient.Get(ctx, projectKey, project)).To(Succeed())
+		cond := meta.FindStatusCondition(project.Status.Conditions, ConditionConnectionFailed)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Reason).To(Equal(ReasonConnectionNotFound))
+		Expect(defaultClient.Projects()).To(BeEmpty())
+	})
+})
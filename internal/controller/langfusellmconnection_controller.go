@@ -18,7 +18,10 @@ package controller
 
 import (
 	"context"
+	"fmt"
 
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -27,20 +30,35 @@ import (
 
 	langfusev1alpha1 "github.com/sqaisar/langfuse-controller/api/v1alpha1"
 	"github.com/sqaisar/langfuse-controller/internal/langfuse"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 )
 
+const (
+	// ConditionSecretInvalid is True on LangfuseLlmConnections whose API
+	// key cannot be read from the Secret they reference.
+	ConditionSecretInvalid = "SecretInvalid"
+
+	ReasonSecretNotFound       = "SecretNotFound"
+	ReasonSecretKeyMissing     = "SecretKeyMissing"
+	ReasonSecretNotInNamespace = "SecretNotInNamespace"
+)
+
 // LangfuseLlmConnectionReconciler reconciles a LangfuseLlmConnection object
 type LangfuseLlmConnectionReconciler struct {
 	client.Client
 	Scheme         *runtime.Scheme
-	LangfuseClient *langfuse.Client
+	LangfuseClient langfuse.LangfuseAPI
+	// Connections, if set, provides the clients of resources managed in
+	// a LangfuseConnection. Other resources use LangfuseClient.
+	Connections *Connections
 }
 
 // +kubebuilder:rbac:groups=langfuse.io,resources=langfusellmconnections,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=langfuse.io,resources=langfusellmconnections/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=langfuse.io,resources=langfusellmconnections/finalizers,verbs=update
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -59,10 +77,6 @@ func (r *LangfuseLlmConnectionReconciler) Reconcile(ctx context.Context, req ctr
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
-	if len(conn.Status.Conditions) > 0 && conn.Status.Conditions[0].Status == metav1.ConditionTrue {
-		return ctrl.Result{}, nil
-	}
-
 	// Fetch Project
 	var project langfusev1alpha1.LangfuseProject
 	if err := r.Get(ctx, types.NamespacedName{Name: conn.Spec.ProjectRef, Namespace: req.Namespace}, &project); err != nil {
@@ -74,29 +88,135 @@ func (r *LangfuseLlmConnectionReconciler) Reconcile(ctx context.Context, req ctr
 		return ctrl.Result{Requeue: true}, nil
 	}
 
-	log.Info("Creating LLM Connection", "provider", conn.Spec.Provider)
-	// Note: Simplified - actual implementation would read from SecretRef
-	if err := r.LangfuseClient.CreateLlmConnection(project.Status.ID, map[string]interface{}{
-		"provider": conn.Spec.Provider,
-	}); err != nil {
-		log.Error(err, "Failed to create LLM Connection")
-		return ctrl.Result{}, err
+	lf, _, ok, err := requireConnection(ctx, r.Client, r.Connections, r.LangfuseClient, project.Namespace,
+		project.Spec.ConnectionRef, project.Status.Connection, &conn, &conn.Status.Conditions)
+	if !ok || err != nil {
+		return ctrl.Result{RequeueAfter: connectionRequeueAfter}, err
+	}
+	if ok, err := requireLangfuse(ctx, r.Client, lf, &conn, &conn.Status.Conditions); !ok || err != nil {
+		return ctrl.Result{RequeueAfter: unavailableRequeueAfter}, err
+	}
+	if ok, err := requireCapability(ctx, r.Client, lf, langfuse.CapabilityLlmConnections, &conn,
+		&conn.Status.Conditions); !ok || err != nil {
+		return ctrl.Result{RequeueAfter: unsupportedRequeueAfter}, err
 	}
 
-	conn.Status.Conditions = []metav1.Condition{{
-		Type:               "Available",
-		Status:             metav1.ConditionTrue,
-		Reason:             "Created",
-		Message:            "LLM Connection created",
-		LastTransitionTime: metav1.Now(),
-	}}
-	if err := r.Status().Update(ctx, &conn); err != nil {
-		return ctrl.Result{}, err
+	apiKey, ok, err := r.apiKey(ctx, &conn)
+	if !ok || err != nil {
+		return ctrl.Result{RequeueAfter: connectionRequeueAfter}, err
+	}
+
+	adapter := conn.Spec.Adapter
+	if adapter == "" {
+		adapter = conn.Spec.Provider
+	}
+	existing, err := r.findLlmConnection(ctx, lf, project.Status.ID, conn.Spec.Provider)
+	if err != nil {
+		log.Error(err, "Failed to look up LLM Connection")
+		return langfuseError(ctx, r.Client, &conn, &conn.Status.Conditions, err)
+	}
+	// Resources that became Available before their ID was recorded own the
+	// connection of their provider.
+	legacy := conn.Status.ID == "" && meta.IsStatusConditionTrue(conn.Status.Conditions, ConditionAvailable)
+	if existing != nil && existing.ID != conn.Status.ID && !legacy {
+		log.Info("LLM Connection is not managed by this resource", "provider", conn.Spec.Provider, "id", existing.ID)
+		if meta.SetStatusCondition(&conn.Status.Conditions, metav1.Condition{
+			Type:   ConditionRejected,
+			Status: metav1.ConditionTrue,
+			Reason: ReasonNotManaged,
+			Message: fmt.Sprintf("LLM connection %s for provider %s exists and is not managed by this resource",
+				existing.ID, conn.Spec.Provider),
+			ObservedGeneration: conn.Generation,
+		}) {
+			if err := r.Status().Update(ctx, &conn); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		return ctrl.Result{RequeueAfter: rejectedRequeueAfter}, nil
+	}
+
+	id := ""
+	if existing == nil || existing.Adapter != adapter || availableGeneration(conn.Status.Conditions) != conn.Generation {
+		log.Info("Creating LLM Connection", "provider", conn.Spec.Provider, "adapter", adapter)
+		upserted, err := lf.UpsertLlmConnection(ctx, project.Status.ID, langfuse.UpsertLlmConnectionRequest{
+			Provider:  conn.Spec.Provider,
+			Adapter:   adapter,
+			SecretKey: apiKey,
+		})
+		if err != nil {
+			log.Error(err, "Failed to create LLM Connection")
+			return langfuseError(ctx, r.Client, &conn, &conn.Status.Conditions, err)
+		}
+		id = upserted.ID
+	} else {
+		id = existing.ID
+	}
+
+	changed := conn.Status.ID != id
+	conn.Status.ID = id
+	if setAvailable(&conn.Status.Conditions, conn.Generation, "Created", "LLM Connection created") || changed {
+		if err := r.Status().Update(ctx, &conn); err != nil {
+			return ctrl.Result{}, err
+		}
 	}
 
 	return ctrl.Result{}, nil
 }
 
+// findLlmConnection returns the LLM connection of provider in projectID, or
+// nil. Langfuse keeps one per provider.
+func (r *LangfuseLlmConnectionReconciler) findLlmConnection(ctx context.Context, lf langfuse.LangfuseAPI,
+	projectID, provider string) (*langfuse.LlmConnection, error) {
+	for c, err := range lf.ListLlmConnections(ctx, projectID, langfuse.ListOptions{}) {
+		if err != nil {
+			return nil, err
+		}
+		if c.Provider == provider {
+			return &c, nil
+		}
+	}
+	return nil, nil
+}
+
+// apiKey reads the provider's API key from the Secret of conn. If it
+// cannot, it sets SecretInvalid on conn and reports false.
+func (r *LangfuseLlmConnectionReconciler) apiKey(ctx context.Context,
+	conn *langfusev1alpha1.LangfuseLlmConnection) (string, bool, error) {
+	ref := conn.Spec.SecretRef
+	key := conn.Spec.SecretKey
+	if key == "" {
+		key = "apiKey"
+	}
+	reason, message := "", ""
+	var secret corev1.Secret
+	if ref.Namespace != "" && ref.Namespace != conn.Namespace {
+		reason = ReasonSecretNotInNamespace
+		message = fmt.Sprintf("Secret %s/%s is not in namespace %s", ref.Namespace, ref.Name, conn.Namespace)
+	} else if err := r.Get(ctx, types.NamespacedName{Namespace: conn.Namespace, Name: ref.Name},
+		&secret); apierrors.IsNotFound(err) {
+		reason = ReasonSecretNotFound
+		message = fmt.Sprintf("Secret %s does not exist", ref.Name)
+	} else if err != nil {
+		return "", false, err
+	} else if len(secret.Data[key]) == 0 {
+		reason = ReasonSecretKeyMissing
+		message = fmt.Sprintf("Secret %s has no key %s", ref.Name, key)
+	}
+	if reason == "" {
+		return string(secret.Data[key]), true, nil
+	}
+	if meta.SetStatusCondition(&conn.Status.Conditions, metav1.Condition{
+		Type:               ConditionSecretInvalid,
+		Status:             metav1.ConditionTrue,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: conn.Generation,
+	}) {
+		return "", false, r.Status().Update(ctx, conn)
+	}
+	return "", false, nil
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *LangfuseLlmConnectionReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
@@ -105,5 +225,5 @@ func (r *LangfuseLlmConnectionReconciler) SetupWithManager(mgr ctrl.Manager) err
 			MaxConcurrentReconciles: 1, // avoids event storms
 		}).
 		Named("langfusellmconnection").
-		Complete(r)
+		Complete(traced("LangfuseLlmConnection", r))
 }
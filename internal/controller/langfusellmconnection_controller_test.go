@@ -21,13 +21,17 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	langfusev1alpha1 "github.com/sqaisar/langfuse-controller/api/v1alpha1"
+	"github.com/sqaisar/langfuse-controller/internal/langfuse"
+	"github.com/sqaisar/langfuse-controller/internal/langfuse/fake"
 )
 
 var _ = Describe("LangfuseLlmConnection Controller", func() {
@@ -40,46 +44,196 @@ var _ = Describe("LangfuseLlmConnection Controller", func() {
 			Name:      resourceName,
 			Namespace: "default", // TODO(user):Modify as needed
 		}
+		projectNamespacedName := types.NamespacedName{
+			Name:      "langfusellmconnection-project",
+			Namespace: "default",
+		}
 		langfusellmconnection := &langfusev1alpha1.LangfuseLlmConnection{}
 
+		var lfClient *fake.Client
+		var projectID string
+
 		BeforeEach(func() {
+			By("creating the Langfuse project and its LangfuseProject")
+			lfClient = fake.NewClient()
+			lfProject, err := lfClient.CreateProject(ctx, langfuse.CreateProjectRequest{Name: "LangfuseLlmConnection Test Project"})
+			Expect(err).NotTo(HaveOccurred())
+			projectID = lfProject.ID
+			createReadyProject(ctx, projectNamespacedName, projectID)
+
+			By("creating the Secret with the provider's API key")
+			Expect(k8sClient.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "openai-credentials", Namespace: "default"},
+				StringData: map[string]string{"apiKey": "sk-openai"},
+			})).To(Succeed())
+
 			By("creating the custom resource for the Kind LangfuseLlmConnection")
-			err := k8sClient.Get(ctx, typeNamespacedName, langfusellmconnection)
+			err = k8sClient.Get(ctx, typeNamespacedName, langfusellmconnection)
 			if err != nil && errors.IsNotFound(err) {
 				resource := &langfusev1alpha1.LangfuseLlmConnection{
 					ObjectMeta: metav1.ObjectMeta{
 						Name:      resourceName,
 						Namespace: "default",
 					},
-					// TODO(user): Specify other spec details if needed.
+					Spec: langfusev1alpha1.LangfuseLlmConnectionSpec{
+						ProjectRef: projectNamespacedName.Name,
+						Provider:   "openai",
+						SecretRef: corev1.SecretReference{
+							Name: "openai-credentials",
+						},
+					},
 				}
 				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
 			}
 		})
 
 		AfterEach(func() {
-			// TODO(user): Cleanup logic after each test, like removing the resource instance.
 			resource := &langfusev1alpha1.LangfuseLlmConnection{}
 			err := k8sClient.Get(ctx, typeNamespacedName, resource)
 			Expect(err).NotTo(HaveOccurred())
 
 			By("Cleanup the specific resource instance LangfuseLlmConnection")
 			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "openai-credentials", Namespace: "default"},
+			})).To(Succeed())
+			deleteProject(ctx, projectNamespacedName)
 		})
 		It("should successfully reconcile the resource", func() {
-			Skip("Requires Langfuse API - add mocking for unit tests")
 			By("Reconciling the created resource")
 			controllerReconciler := &LangfuseLlmConnectionReconciler{
-				Client: k8sClient,
-				Scheme: k8sClient.Scheme(),
+				Client:         k8sClient,
+				Scheme:         k8sClient.Scheme(),
+				LangfuseClient: lfClient,
 			}
 
 			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
 				NamespacedName: typeNamespacedName,
 			})
 			Expect(err).NotTo(HaveOccurred())
-			// TODO(user): Add more specific assertions depending on your controller's reconciliation logic.
-			// Example: If you expect a certain status condition after reconciliation, verify it here.
+
+			By("Checking the connection was created in Langfuse")
+			request, ok := lfClient.LlmConnection(projectID, "openai")
+			Expect(ok).To(BeTrue())
+			Expect(request.Adapter).To(Equal("openai"))
+			Expect(request.SecretKey).To(Equal("sk-openai"))
+
+			resource := &langfusev1alpha1.LangfuseLlmConnection{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.Conditions).To(HaveLen(1))
+			Expect(resource.Status.Conditions[0].Type).To(Equal("Available"))
+			Expect(resource.Status.Conditions[0].Status).To(Equal(metav1.ConditionTrue))
+			Expect(resource.Status.ID).NotTo(BeEmpty())
+
+			By("Not upserting again while the spec is unchanged")
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lfClient.Calls("UpsertLlmConnection")).To(Equal(1))
+
+			By("Upserting again after a spec change")
+			resource.Spec.Adapter = "azure"
+			Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			request, ok = lfClient.LlmConnection(projectID, "openai")
+			Expect(ok).To(BeTrue())
+			Expect(request.Adapter).To(Equal("azure"))
+		})
+
+		It("should not replace a connection it did not create", func() {
+			existing, err := lfClient.UpsertLlmConnection(ctx, projectID, langfuse.UpsertLlmConnectionRequest{
+				Provider:  "openai",
+				Adapter:   "openai",
+				SecretKey: "sk-by-hand",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			controllerReconciler := &LangfuseLlmConnectionReconciler{
+				Client:         k8sClient,
+				Scheme:         k8sClient.Scheme(),
+				LangfuseClient: lfClient,
+			}
+
+			result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(rejectedRequeueAfter))
+			Expect(lfClient.Calls("UpsertLlmConnection")).To(Equal(1))
+			request, ok := lfClient.LlmConnection(projectID, "openai")
+			Expect(ok).To(BeTrue())
+			Expect(request.SecretKey).To(Equal("sk-by-hand"))
+
+			resource := &langfusev1alpha1.LangfuseLlmConnection{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			cond := meta.FindStatusCondition(resource.Status.Conditions, ConditionRejected)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Reason).To(Equal(ReasonNotManaged))
+			Expect(cond.Message).To(ContainSubstring(existing.ID))
+		})
+
+		It("should not create the connection while the Secret has no API key", func() {
+			secret := &corev1.Secret{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "openai-credentials", Namespace: "default"},
+				secret)).To(Succeed())
+			secret.Data = map[string][]byte{"other": []byte("sk-openai")}
+			Expect(k8sClient.Update(ctx, secret)).To(Succeed())
+
+			controllerReconciler := &LangfuseLlmConnectionReconciler{
+				Client:         k8sClient,
+				Scheme:         k8sClient.Scheme(),
+				LangfuseClient: lfClient,
+			}
+			result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(connectionRequeueAfter))
+			Expect(lfClient.Calls("UpsertLlmConnection")).To(BeZero())
+
+			resource := &langfusev1alpha1.LangfuseLlmConnection{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			cond := meta.FindStatusCondition(resource.Status.Conditions, ConditionSecretInvalid)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Reason).To(Equal(ReasonSecretKeyMissing))
+		})
+
+		It("should requeue while the project is not ready", func() {
+			project := &langfusev1alpha1.LangfuseProject{}
+			Expect(k8sClient.Get(ctx, projectNamespacedName, project)).To(Succeed())
+			project.Status.ID = ""
+			Expect(k8sClient.Status().Update(ctx, project)).To(Succeed())
+
+			controllerReconciler := &LangfuseLlmConnectionReconciler{
+				Client:         k8sClient,
+				Scheme:         k8sClient.Scheme(),
+				LangfuseClient: lfClient,
+			}
+			result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Requeue).To(BeTrue())
+			Expect(lfClient.Calls("UpsertLlmConnection")).To(BeZero())
+		})
+
+		It("should mark the resource Unsupported when the server has no LLM connections API", func() {
+			lfClient.SetUnsupported(langfuse.CapabilityLlmConnections)
+			controllerReconciler := &LangfuseLlmConnectionReconciler{
+				Client:         k8sClient,
+				Scheme:         k8sClient.Scheme(),
+				LangfuseClient: lfClient,
+			}
+			result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(unsupportedRequeueAfter))
+			Expect(lfClient.Calls("UpsertLlmConnection")).To(BeZero())
+
+			resource := &langfusev1alpha1.LangfuseLlmConnection{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			cond := meta.FindStatusCondition(resource.Status.Conditions, ConditionUnsupported)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+			Expect(cond.Reason).To(Equal(ReasonCapabilityMissing))
 		})
 	})
 })
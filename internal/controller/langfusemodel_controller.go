@@ -18,9 +18,9 @@ package controller
 
 import (
 	"context"
+	"encoding/json"
 	"strconv"
 
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -35,7 +35,10 @@ import (
 type LangfuseModelReconciler struct {
 	client.Client
 	Scheme         *runtime.Scheme
-	LangfuseClient *langfuse.Client
+	LangfuseClient langfuse.LangfuseAPI
+	// Connections, if set, provides the clients of resources managed in
+	// a LangfuseConnection. Other resources use LangfuseClient.
+	Connections *Connections
 }
 
 // +kubebuilder:rbac:groups=langfuse.io,resources=langfusemodels,verbs=get;list;watch;create;update;patch;delete
@@ -59,50 +62,147 @@ func (r *LangfuseModelReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
-	if len(model.Status.Conditions) > 0 && model.Status.Conditions[0].Status == metav1.ConditionTrue {
-		return ctrl.Result{}, nil
+	// Once the model exists, it stays in the instance it was created in.
+	pinned := ""
+	if model.Status.ID != "" {
+		pinned = model.Status.Connection
+	}
+	lf, connection, ok, err := requireConnection(ctx, r.Client, r.Connections, r.LangfuseClient, model.Namespace,
+		model.Spec.ConnectionRef, pinned, &model, &model.Status.Conditions)
+	if !ok || err != nil {
+		return ctrl.Result{RequeueAfter: connectionRequeueAfter}, err
+	}
+	if ok, err := requireLangfuse(ctx, r.Client, lf, &model, &model.Status.Conditions); !ok || err != nil {
+		return ctrl.Result{RequeueAfter: unavailableRequeueAfter}, err
+	}
+	if ok, err := requireCapability(ctx, r.Client, lf, langfuse.CapabilityModels, &model,
+		&model.Status.Conditions); !ok || err != nil {
+		return ctrl.Result{RequeueAfter: unsupportedRequeueAfter}, err
 	}
 
-	inputPrice, _ := strconv.ParseFloat(model.Spec.InputPrice, 64)
-	outputPrice, _ := strconv.ParseFloat(model.Spec.OutputPrice, 64)
-	totalPrice, _ := strconv.ParseFloat(model.Spec.TotalPrice, 64)
-
-	lfModel := langfuse.Model{
-		ModelName:       model.Spec.ModelName,
-		MatchPattern:    model.Spec.MatchPattern,
-		StartDate:       model.Spec.StartDate,
-		Unit:            model.Spec.Unit,
-		InputPrice:      inputPrice,
-		OutputPrice:     outputPrice,
-		TotalPrice:      totalPrice,
-		TokenizerId:     model.Spec.TokenizerId,
-		TokenizerConfig: model.Spec.TokenizerConfig,
+	lfModel := langfuse.CreateModelRequest{
+		ModelName:    model.Spec.ModelName,
+		MatchPattern: model.Spec.MatchPattern,
+		StartDate:    model.Spec.StartDate,
+		Unit:         model.Spec.Unit,
+		InputPrice:   parsePrice(model.Spec.InputPrice),
+		OutputPrice:  parsePrice(model.Spec.OutputPrice),
+		TotalPrice:   parsePrice(model.Spec.TotalPrice),
+		TokenizerID:  model.Spec.TokenizerId,
+	}
+	if model.Spec.TokenizerConfig != "" && json.Valid([]byte(model.Spec.TokenizerConfig)) {
+		lfModel.TokenizerConfig = json.RawMessage(model.Spec.TokenizerConfig)
 	}
 
-	log.Info("Creating Langfuse Model", "name", model.Spec.ModelName)
-	_, err := r.LangfuseClient.CreateModel(lfModel)
+	existing, err := r.currentModel(ctx, lf, &model, lfModel)
 	if err != nil {
-		log.Error(err, "Failed to create Model")
-		return ctrl.Result{}, err
+		log.Error(err, "Failed to look up Model")
+		return langfuseError(ctx, r.Client, &model, &model.Status.Conditions, err)
 	}
-
-	// Update Status
-	model.Status.Conditions = []metav1.Condition{
-		{
-			Type:               "Available",
-			Status:             metav1.ConditionTrue,
-			Reason:             "Created",
-			Message:            "Model created successfully",
-			LastTransitionTime: metav1.Now(),
-		},
+	id := ""
+	if existing != nil && modelMatches(existing, lfModel) {
+		id = existing.ID
+	} else {
+		log.Info("Creating Langfuse Model", "name", model.Spec.ModelName)
+		created, err := lf.CreateModel(ctx, lfModel)
+		if err != nil {
+			log.Error(err, "Failed to create Model")
+			return langfuseError(ctx, r.Client, &model, &model.Status.Conditions, err)
+		}
+		id = created.ID
+		// Langfuse models cannot be updated, so a changed spec replaces the
+		// model.
+		if existing != nil {
+			log.Info("Deleting replaced Langfuse Model", "id", existing.ID)
+			if err := lf.DeleteModel(ctx, existing.ID); err != nil && !langfuse.IsNotFound(err) {
+				log.Error(err, "Failed to delete replaced Model", "id", existing.ID)
+			}
+		}
 	}
-	if err := r.Status().Update(ctx, &model); err != nil {
-		return ctrl.Result{}, err
+
+	// Models created before connections were recorded keep the one they
+	// resolve to now.
+	changed := model.Status.ID != id || model.Status.Connection != connection
+	model.Status.ID = id
+	model.Status.Connection = connection
+	if setAvailable(&model.Status.Conditions, model.Generation, "Created", "Model created successfully") || changed {
+		if err := r.Status().Update(ctx, &model); err != nil {
+			return ctrl.Result{}, err
+		}
 	}
 
 	return ctrl.Result{}, nil
 }
 
+// currentModel returns the model of model: the one with the ID in its
+// status, or else one findModel adopts. It returns nil if there is none,
+// e.g. because it was deleted in Langfuse.
+func (r *LangfuseModelReconciler) currentModel(ctx context.Context, lf langfuse.LangfuseAPI,
+	model *langfusev1alpha1.LangfuseModel, want langfuse.CreateModelRequest) (*langfuse.Model, error) {
+	if model.Status.ID == "" {
+		return r.findModel(ctx, lf, model, want)
+	}
+	m, err := lf.GetModel(ctx, model.Status.ID)
+	if langfuse.IsNotFound(err) {
+		return nil, nil
+	}
+	return m, err
+}
+
+// findModel returns a custom model that matches want exactly and that no
+// other LangfuseModel has in its status, or nil. Langfuse models have no
+// field to carry an ownership marker, so every field that the spec sets has
+// to match instead; see ownership.go.
+func (r *LangfuseModelReconciler) findModel(ctx context.Context, lf langfuse.LangfuseAPI,
+	model *langfusev1alpha1.LangfuseModel, want langfuse.CreateModelRequest) (*langfuse.Model, error) {
+	var models langfusev1alpha1.LangfuseModelList
+	if err := r.List(ctx, &models); err != nil {
+		return nil, err
+	}
+	claimed := map[string]bool{}
+	for _, other := range models.Items {
+		if other.UID != model.UID && other.Status.ID != "" {
+			claimed[other.Status.ID] = true
+		}
+	}
+	for m, err := range lf.ListModels(ctx, langfuse.ListOptions{}) {
+		if err != nil {
+			return nil, err
+		}
+		if !m.IsLangfuseManaged && !claimed[m.ID] && modelMatches(&m, want) {
+			logf.FromContext(ctx).Info("Adopting existing Langfuse Model", "name", m.ModelName, "id", m.ID)
+			return &m, nil
+		}
+	}
+	return nil, nil
+}
+
+// modelMatches reports whether m has every field of want.
+func modelMatches(m *langfuse.Model, want langfuse.CreateModelRequest) bool {
+	return m.ModelName == want.ModelName && m.MatchPattern == want.MatchPattern &&
+		m.StartDate == want.StartDate && m.Unit == want.Unit && m.TokenizerID == want.TokenizerID &&
+		samePrice(m.InputPrice, want.InputPrice) && samePrice(m.OutputPrice, want.OutputPrice) &&
+		samePrice(m.TotalPrice, want.TotalPrice) && sameJSON(m.TokenizerConfig, want.TokenizerConfig)
+}
+
+// samePrice reports whether two optional prices are equal.
+func samePrice(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// parsePrice parses a decimal price from the spec. Empty or invalid prices
+// are left unset.
+func parsePrice(s string) *float64 {
+	price, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil
+	}
+	return &price
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *LangfuseModelReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
@@ -111,5 +211,5 @@ func (r *LangfuseModelReconciler) SetupWithManager(mgr ctrl.Manager) error {
 			MaxConcurrentReconciles: 1, // avoids event storms
 		}).
 		Named("langfusemodel").
-		Complete(r)
+		Complete(traced("LangfuseModel", r))
 }
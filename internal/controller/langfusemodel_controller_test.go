@@ -22,12 +22,14 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	langfusev1alpha1 "github.com/sqaisar/langfuse-controller/api/v1alpha1"
+	"github.com/sqaisar/langfuse-controller/internal/langfuse/fake"
 )
 
 var _ = Describe("LangfuseModel Controller", func() {
@@ -51,14 +53,19 @@ var _ = Describe("LangfuseModel Controller", func() {
 						Name:      resourceName,
 						Namespace: "default",
 					},
-					// TODO(user): Specify other spec details if needed.
+					Spec: langfusev1alpha1.LangfuseModelSpec{
+						ModelName:    "gpt-test",
+						MatchPattern: "(?i)^(gpt-test)$",
+						Unit:         "TOKENS",
+						InputPrice:   "0.000001",
+						OutputPrice:  "0.000002",
+					},
 				}
 				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
 			}
 		})
 
 		AfterEach(func() {
-			// TODO(user): Cleanup logic after each test, like removing the resource instance.
 			resource := &langfusev1alpha1.LangfuseModel{}
 			err := k8sClient.Get(ctx, typeNamespacedName, resource)
 			Expect(err).NotTo(HaveOccurred())
@@ -67,19 +74,127 @@ var _ = Describe("LangfuseModel Controller", func() {
 			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
 		})
 		It("should successfully reconcile the resource", func() {
-			Skip("Requires Langfuse API - add mocking for unit tests")
 			By("Reconciling the created resource")
+			lfClient := fake.NewClient()
 			controllerReconciler := &LangfuseModelReconciler{
-				Client: k8sClient,
-				Scheme: k8sClient.Scheme(),
+				Client:         k8sClient,
+				Scheme:         k8sClient.Scheme(),
+				LangfuseClient: lfClient,
 			}
 
 			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
 				NamespacedName: typeNamespacedName,
 			})
 			Expect(err).NotTo(HaveOccurred())
-			// TODO(user): Add more specific assertions depending on your controller's reconciliation logic.
-			// Example: If you expect a certain status condition after reconciliation, verify it here.
+
+			By("Checking the model was created in Langfuse")
+			models := lfClient.Models()
+			Expect(models).To(HaveLen(1))
+			Expect(models[0].ModelName).To(Equal("gpt-test"))
+			Expect(models[0].InputPrice).To(BeNumerically("==", 0.000001))
+
+			resource := &langfusev1alpha1.LangfuseModel{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.Conditions).To(HaveLen(1))
+			Expect(resource.Status.Conditions[0].Status).To(Equal(metav1.ConditionTrue))
+
+			Expect(resource.Status.ID).To(Equal(models[0].ID))
+
+			By("Reconciling again after a lost status update")
+			resource.Status = langfusev1alpha1.LangfuseModelStatus{}
+			Expect(k8sClient.Status().Update(ctx, resource)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lfClient.Models()).To(HaveLen(1))
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.ID).To(Equal(models[0].ID))
+
+			By("Recreating the model once it is deleted in Langfuse")
+			Expect(lfClient.DeleteModel(ctx, models[0].ID)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			recreated := lfClient.Models()
+			Expect(recreated).To(HaveLen(1))
+			Expect(recreated[0].ID).NotTo(Equal(models[0].ID))
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.ID).To(Equal(recreated[0].ID))
+
+			By("Replacing the model when the spec changes")
+			resource.Spec.OutputPrice = "0.000003"
+			Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			replaced := lfClient.Models()
+			Expect(replaced).To(HaveLen(1))
+			Expect(replaced[0].OutputPrice).To(HaveValue(BeNumerically("==", 0.000003)))
+		})
+
+		It("should not adopt a model that another LangfuseModel recorded", func() {
+			lfClient := fake.NewClient()
+			controllerReconciler := &LangfuseModelReconciler{
+				Client:         k8sClient,
+				Scheme:         k8sClient.Scheme(),
+				LangfuseClient: lfClient,
+			}
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			resource := &langfusev1alpha1.LangfuseModel{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			twinKey := types.NamespacedName{Name: "twin-model", Namespace: "default"}
+			twin := &langfusev1alpha1.LangfuseModel{
+				ObjectMeta: metav1.ObjectMeta{Name: twinKey.Name, Namespace: twinKey.Namespace},
+				Spec:       resource.Spec,
+			}
+			Expect(k8sClient.Create(ctx, twin)).To(Succeed())
+			defer func() { Expect(k8sClient.Delete(ctx, twin)).To(Succeed()) }()
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: twinKey})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lfClient.Models()).To(HaveLen(2))
+			Expect(k8sClient.Get(ctx, twinKey, twin)).To(Succeed())
+			Expect(twin.Status.ID).NotTo(BeEmpty())
+			Expect(twin.Status.ID).NotTo(Equal(resource.Status.ID))
+		})
+
+		It("should pause while Langfuse is unavailable", func() {
+			lfClient := fake.NewClient()
+			lfClient.SetUnavailable(true)
+			controllerReconciler := &LangfuseModelReconciler{
+				Client:         k8sClient,
+				Scheme:         k8sClient.Scheme(),
+				LangfuseClient: lfClient,
+			}
+
+			result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(unavailableRequeueAfter))
+			Expect(lfClient.Calls("CreateModel")).To(BeZero())
+
+			resource := &langfusev1alpha1.LangfuseModel{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			cond := meta.FindStatusCondition(resource.Status.Conditions, ConditionLangfuseUnavailable)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+			Expect(cond.Reason).To(Equal(ReasonCircuitOpen))
+
+			By("Resuming once the circuit breaker closes")
+			lfClient.SetUnavailable(false)
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lfClient.Models()).To(HaveLen(1))
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(meta.FindStatusCondition(resource.Status.Conditions, ConditionLangfuseUnavailable)).To(BeNil())
 		})
 	})
 })
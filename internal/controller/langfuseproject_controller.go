@@ -18,27 +18,92 @@ package controller
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	langfusev1alpha1 "github.com/sqaisar/langfuse-controller/api/v1alpha1"
 	"github.com/sqaisar/langfuse-controller/internal/langfuse"
 )
 
+const (
+	// ProjectFinalizer keeps a LangfuseProject until its deletionPolicy has
+	// been applied to the project in Langfuse.
+	ProjectFinalizer = "langfuse.io/finalizer"
+
+	// ConditionDeletionBlocked is True while a LangfuseProject with the
+	// Delete policy waits for the resources that refer to it to be deleted.
+	ConditionDeletionBlocked = "DeletionBlocked"
+
+	ReasonChildResourcesExist = "ChildResourcesExist"
+
+	// ConditionSynced reports whether the project in Langfuse matched the
+	// spec of its LangfuseProject when it was last compared.
+	ConditionSynced = "Synced"
+
+	ReasonSynced        = "Synced"
+	ReasonRemoteDeleted = "RemoteDeleted"
+	ReasonNotFound      = "NotFound"
+	ReasonSyncFailed    = "SyncFailed"
+	ReasonAlreadyOwned  = "AlreadyOwned"
+
+	// ReasonSecretNotOwned is the reason of SecretInvalid when the Secret
+	// for the internal project key belongs to something else.
+	ReasonSecretNotOwned = "SecretNotOwned"
+)
+
+// childRequeueAfter is how often a LangfuseProject blocked on its child
+// resources checks them again.
+const childRequeueAfter = 10 * time.Second
+
+// adoptRequeueAfter is how often a LangfuseProject whose project cannot be
+// adopted tries again.
+const adoptRequeueAfter = time.Minute
+
 // LangfuseProjectReconciler reconciles a LangfuseProject object
 type LangfuseProjectReconciler struct {
 	client.Client
 	Scheme         *runtime.Scheme
-	LangfuseClient *langfuse.Client
+	LangfuseClient langfuse.LangfuseAPI
+	// Connections, if set, provides the clients of resources managed in
+	// a LangfuseConnection. Other resources use LangfuseClient.
+	Connections *Connections
+	// ProjectCredentials, if set, is told about the internal project keys
+	// as soon as they are created.
+	ProjectCredentials *ProjectCredentials
+	// SyncInterval is how often a project is compared with its spec, so
+	// that changes made in Langfuse are noticed. Zero disables the
+	// periodic comparison.
+	SyncInterval time.Duration
+	// Recorder, if set, receives events about projects that were released
+	// without applying their deletionPolicy.
+	Recorder record.EventRecorder
 }
 
 // +kubebuilder:rbac:groups=langfuse.io,resources=langfuseprojects,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=langfuse.io,resources=langfuseprojects/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=langfuse.io,resources=langfuseprojects/finalizers,verbs=update
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -51,47 +116,658 @@ type LangfuseProjectReconciler struct {
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.22.4/pkg/reconcile
 func (r *LangfuseProjectReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	log := logf.FromContext(ctx)
+	// Every project-scoped resource waits for the project ID in the status.
+	ctx = langfuse.WithPriority(ctx, langfuse.PriorityHigh)
 
 	var project langfusev1alpha1.LangfuseProject
 	if err := r.Get(ctx, req.NamespacedName, &project); err != nil {
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
+	if !project.DeletionTimestamp.IsZero() {
+		return r.finalize(ctx, &project)
+	}
+	if controllerutil.AddFinalizer(&project, ProjectFinalizer) {
+		if err := r.Update(ctx, &project); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	// Once the project exists, it stays in the instance it was created in.
+	pinned := ""
+	if project.Status.ID != "" {
+		pinned = project.Status.Connection
+	}
+	lf, connection, ok, err := requireConnection(ctx, r.Client, r.Connections, r.LangfuseClient,
+		project.Namespace, project.Spec.ConnectionRef, pinned, &project, &project.Status.Conditions)
+	if !ok || err != nil {
+		return ctrl.Result{RequeueAfter: connectionRequeueAfter}, err
+	}
+	if ok, err := requireLangfuse(ctx, r.Client, lf, &project, &project.Status.Conditions); !ok || err != nil {
+		return ctrl.Result{RequeueAfter: unavailableRequeueAfter}, err
+	}
+	if ok, err := requireCapability(ctx, r.Client, lf, langfuse.CapabilityOrganizations, &project,
+		&project.Status.Conditions); !ok || err != nil {
+		return ctrl.Result{RequeueAfter: unsupportedRequeueAfter}, err
+	}
 
+	if project.Spec.ProjectID != "" && project.Status.ID != project.Spec.ProjectID {
+		if ok, result, err := r.adoptProject(ctx, lf, connection, &project); !ok || err != nil {
+			return result, err
+		}
+	}
+	synced := project.Status.DeepCopy()
 	if project.Status.ID != "" {
-		// Project already exists, maybe check if it still exists?
-		// For now, assume it's synced.
-		return ctrl.Result{}, nil
+		if ok, result, err := r.syncProject(ctx, lf, &project); !ok || err != nil {
+			return result, err
+		}
+		// Projects created before connections were recorded.
+		if project.Status.Connection == "" {
+			project.Status.Connection = connection
+		}
 	}
+	if project.Status.ID == "" {
+		lfProject, err := r.findProject(ctx, lf, &project)
+		if err == nil && lfProject != nil {
+			log.Info("Adopting existing Langfuse Project", "name", project.Spec.Name, "id", lfProject.ID)
+		} else if err == nil {
+			log.Info("Creating Langfuse Project", "name", project.Spec.Name)
+			lfProject, err = lf.CreateProject(ctx, langfuse.CreateProjectRequest{
+				Name:      project.Spec.Name,
+				Metadata:  projectMetadata(&project),
+				Retention: int(ptr.Deref(project.Spec.RetentionDays, 0)),
+			})
+		}
+		if err != nil {
+			log.Error(err, "Failed to create Langfuse Project")
+			project.Status.State = "Error"
+			if err := r.Status().Update(ctx, &project); err != nil {
+				return ctrl.Result{}, err
+			}
+			return langfuseError(ctx, r.Client, &project, &project.Status.Conditions, err)
+		}
 
-	log.Info("Creating Langfuse Project", "name", project.Spec.Name)
-	lfProject, err := r.LangfuseClient.CreateProject(project.Spec.Name)
-	if err != nil {
-		log.Error(err, "Failed to create Langfuse Project")
-		project.Status.State = "Error"
+		project.Status.ID = lfProject.ID
+		project.Status.Connection = connection
+		project.Status.State = "Ready"
+		setProjectSettings(&project.Status, lfProject)
+		setAuthenticatedCondition(&project.Status.Conditions, project.Generation, nil)
 		if err := r.Status().Update(ctx, &project); err != nil {
+			log.Error(err, "Failed to update LangfuseProject status")
 			return ctrl.Result{}, err
 		}
+		log.Info("Langfuse Project created successfully", "id", lfProject.ID)
+	}
+
+	if ok, err := r.ensureProjectKey(ctx, lf, &project); err != nil {
+		log.Error(err, "Failed to provision the internal project API key")
 		return ctrl.Result{}, err
+	} else if !ok {
+		log.Info("Internal project API key Secret is not managed by this LangfuseProject")
+		return ctrl.Result{RequeueAfter: connectionRequeueAfter}, r.Status().Update(ctx, &project)
+	}
+	changed := r.setSyncedCondition(&project, metav1.ConditionTrue, ReasonSynced,
+		"The Langfuse project matches the spec")
+	changed = meta.RemoveStatusCondition(&project.Status.Conditions, ConditionRejected) || changed
+	if changed || !equality.Semantic.DeepEqual(synced, &project.Status) {
+		if err := r.Status().Update(ctx, &project); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	return ctrl.Result{RequeueAfter: r.SyncInterval}, nil
+}
+
+// adoptProject binds project to the existing Langfuse project with the ID
+// in its spec and the instance of connection, replacing any project it
+// managed before. It reports whether reconciling should go on, which it
+// should not if there is no such project, and otherwise how to requeue.
+func (r *LangfuseProjectReconciler) adoptProject(ctx context.Context, lf langfuse.LangfuseAPI, connection string,
+	project *langfusev1alpha1.LangfuseProject) (bool, ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+	id := project.Spec.ProjectID
+	lfProject, err := lf.GetProject(ctx, id)
+	if langfuse.IsNotFound(err) {
+		log.Info("Langfuse Project to adopt does not exist", "id", id)
+		return false, ctrl.Result{RequeueAfter: adoptRequeueAfter},
+			r.refuseAdoption(ctx, project, ReasonNotFound, fmt.Sprintf("Project %s does not exist in Langfuse", id))
+	} else if err != nil {
+		log.Error(err, "Failed to get Langfuse Project to adopt")
+		result, err := r.reportSyncError(ctx, project, err)
+		return false, result, err
+	}
+	owner, err := r.projectOwner(ctx, project, lfProject)
+	if err != nil {
+		return false, ctrl.Result{}, err
+	}
+	if owner != "" {
+		log.Info("Langfuse Project to adopt is managed by another resource", "id", id, "owner", owner)
+		return false, ctrl.Result{RequeueAfter: adoptRequeueAfter},
+			r.refuseAdoption(ctx, project, ReasonAlreadyOwned, fmt.Sprintf("Project %s is managed by %s", id, owner))
 	}
 
-	project.Status.ID = lfProject.ID
+	log.Info("Adopting existing Langfuse Project by ID", "id", id, "previousID", project.Status.ID)
+	if project.Status.ID != "" && r.ProjectCredentials != nil {
+		r.ProjectCredentials.forget(project.Status.ID)
+	}
+	project.Status.ID = id
+	project.Status.Connection = connection
 	project.Status.State = "Ready"
-	if err := r.Status().Update(ctx, &project); err != nil {
-		log.Error(err, "Failed to update LangfuseProject status")
+	setAuthenticatedCondition(&project.Status.Conditions, project.Generation, nil)
+	return true, ctrl.Result{}, r.Status().Update(ctx, project)
+}
+
+// refuseAdoption marks project as not Synced because its project cannot be
+// adopted for reason.
+func (r *LangfuseProjectReconciler) refuseAdoption(ctx context.Context, project *langfusev1alpha1.LangfuseProject,
+	reason, message string) error {
+	changed := project.Status.State != "Error"
+	project.Status.State = "Error"
+	if r.setSyncedCondition(project, metav1.ConditionFalse, reason, message) || changed {
+		return r.Status().Update(ctx, project)
+	}
+	return nil
+}
+
+// projectOwner returns the other LangfuseProject that manages lfProject,
+// as "LangfuseProject/<namespace>/<name>", or "" if there is none. That is
+// one with lfProject's ID in its status, or the one named in the ownership
+// marker of lfProject if it still exists.
+func (r *LangfuseProjectReconciler) projectOwner(ctx context.Context, project *langfusev1alpha1.LangfuseProject,
+	lfProject *langfuse.Project) (string, error) {
+	var projects langfusev1alpha1.LangfuseProjectList
+	if err := r.List(ctx, &projects); err != nil {
+		return "", err
+	}
+	for i := range projects.Items {
+		other := &projects.Items[i]
+		if other.Status.ID == lfProject.ID && other.UID != project.UID {
+			return ownerOf("LangfuseProject", other), nil
+		}
+	}
+
+	owner, _ := lfProject.Metadata[metadataOwner].(string)
+	if lfProject.Metadata[metadataManagedBy] != managedBy || owner == ownerOf("LangfuseProject", project) {
+		return "", nil
+	}
+	parts := strings.Split(owner, "/")
+	if len(parts) != 3 || parts[0] != "LangfuseProject" {
+		return "", nil
+	}
+	err := r.Get(ctx, types.NamespacedName{Namespace: parts[1], Name: parts[2]}, &langfusev1alpha1.LangfuseProject{})
+	if apierrors.IsNotFound(err) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	return owner, nil
+}
+
+// syncProject compares the Langfuse project of project with its spec and
+// updates its name, metadata and retention if needed. If the project was
+// deleted in Langfuse, the remoteDeletionPolicy decides: Recreate clears
+// the ID in status so that the caller creates it again, Report marks
+// project as not Synced. Projects adopted by ID are never recreated. It
+// reports whether reconciling should go on, and otherwise how to requeue.
+func (r *LangfuseProjectReconciler) syncProject(ctx context.Context, lf langfuse.LangfuseAPI,
+	project *langfusev1alpha1.LangfuseProject) (bool, ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+	lfProject, err := lf.GetProject(ctx, project.Status.ID)
+	switch {
+	case langfuse.IsNotFound(err):
+		if project.Spec.RemoteDeletionPolicy == langfusev1alpha1.RemoteDeletionPolicyRecreate &&
+			project.Spec.ProjectID == "" {
+			log.Info("Langfuse Project was deleted in Langfuse, recreating it", "id", project.Status.ID)
+			if r.ProjectCredentials != nil {
+				r.ProjectCredentials.forget(project.Status.ID)
+			}
+			project.Status.ID = ""
+			return true, ctrl.Result{}, nil
+		}
+		log.Info("Langfuse Project was deleted in Langfuse", "id", project.Status.ID)
+		project.Status.State = "Error"
+		r.setSyncedCondition(project, metav1.ConditionFalse, ReasonRemoteDeleted,
+			fmt.Sprintf("Project %s was deleted in Langfuse", project.Status.ID))
+		return false, ctrl.Result{RequeueAfter: r.SyncInterval}, r.Status().Update(ctx, project)
+	case err != nil:
+		log.Error(err, "Failed to get Langfuse Project")
+		result, err := r.reportSyncError(ctx, project, err)
+		return false, result, err
+	}
+
+	update, drifted := projectUpdate(project, lfProject)
+	if drifted {
+		log.Info("Updating Langfuse Project", "id", project.Status.ID, "name", update.Name,
+			"retention", update.Retention, "metadata", update.Metadata != nil)
+		lfProject, err = lf.UpdateProject(ctx, project.Status.ID, update)
+		if err != nil {
+			log.Error(err, "Failed to update Langfuse Project")
+			result, err := r.reportSyncError(ctx, project, err)
+			return false, result, err
+		}
+		if lfProject.RetentionDays == nil {
+			// Langfuse omits the retention when there is none.
+			lfProject.RetentionDays = update.Retention
+		}
+	}
+	setProjectSettings(&project.Status, lfProject)
+	return true, ctrl.Result{}, nil
+}
+
+// projectUpdate returns the update that makes lfProject match the spec of
+// project, and whether it differs at all. Metadata and retention are only
+// included if they are set in the spec, except for the ownership marker,
+// which is added to the metadata of adopted projects. The retention is
+// compared with status if lfProject does not have it because the project
+// has no key to read it with yet.
+func projectUpdate(project *langfusev1alpha1.LangfuseProject,
+	lfProject *langfuse.Project) (langfuse.UpdateProjectRequest, bool) {
+	update := langfuse.UpdateProjectRequest{Name: project.Spec.Name}
+	drifted := lfProject.Name != project.Spec.Name
+	if project.Spec.Metadata != nil {
+		update.Metadata = projectMetadata(project)
+		drifted = drifted || !sameJSON(update.Metadata, lfProject.Metadata)
+	} else if !ownedByMetadata(lfProject.Metadata, "LangfuseProject", project) {
+		update.Metadata = ownershipMetadata("LangfuseProject", project)
+		for k, v := range lfProject.Metadata {
+			if _, reserved := update.Metadata[k]; !reserved {
+				update.Metadata[k] = v
+			}
+		}
+		drifted = true
+	}
+	if project.Spec.RetentionDays != nil {
+		days := int(*project.Spec.RetentionDays)
+		update.Retention = &days
+		current := ptr.Deref(project.Status.RetentionDays, 0)
+		if lfProject.RetentionDays != nil {
+			current = int32(*lfProject.RetentionDays)
+		}
+		drifted = drifted || current != *project.Spec.RetentionDays
+	}
+	return update, drifted
+}
+
+// projectMetadata returns the metadata of the Langfuse project of project:
+// the metadata in its spec and the ownership marker.
+func projectMetadata(project *langfusev1alpha1.LangfuseProject) map[string]interface{} {
+	metadata := ownershipMetadata("LangfuseProject", project)
+	for k, v := range project.Spec.Metadata {
+		if _, reserved := metadata[k]; !reserved {
+			metadata[k] = v
+		}
+	}
+	return metadata
+}
+
+// setProjectSettings records the settings of lfProject in status. The
+// retention is kept if lfProject does not have it, and cleared if it is 0.
+func setProjectSettings(status *langfusev1alpha1.LangfuseProjectStatus, lfProject *langfuse.Project) {
+	status.Metadata = nil
+	for k, v := range lfProject.Metadata {
+		s, ok := v.(string)
+		if !ok {
+			b, err := json.Marshal(v)
+			if err != nil {
+				continue
+			}
+			s = string(b)
+		}
+		if status.Metadata == nil {
+			status.Metadata = map[string]string{}
+		}
+		status.Metadata[k] = s
+	}
+	switch {
+	case lfProject.RetentionDays == nil:
+	case *lfProject.RetentionDays == 0:
+		status.RetentionDays = nil
+	default:
+		status.RetentionDays = ptr.To(int32(*lfProject.RetentionDays))
+	}
+}
+
+// reportSyncError marks project as not Synced because of err, and records
+// err like langfuseError.
+func (r *LangfuseProjectReconciler) reportSyncError(ctx context.Context,
+	project *langfusev1alpha1.LangfuseProject, err error) (ctrl.Result, error) {
+	r.setSyncedCondition(project, metav1.ConditionFalse, ReasonSyncFailed, err.Error())
+	if err := r.Status().Update(ctx, project); err != nil {
 		return ctrl.Result{}, err
 	}
+	return langfuseError(ctx, r.Client, project, &project.Status.Conditions, err)
+}
 
-	log.Info("Langfuse Project created successfully", "id", lfProject.ID)
-	return ctrl.Result{}, nil
+// setSyncedCondition sets the Synced condition of project and reports
+// whether it changed.
+func (r *LangfuseProjectReconciler) setSyncedCondition(project *langfusev1alpha1.LangfuseProject,
+	status metav1.ConditionStatus, reason, message string) bool {
+	return meta.SetStatusCondition(&project.Status.Conditions, metav1.Condition{
+		Type:               ConditionSynced,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: project.Generation,
+	})
+}
+
+// finalize applies the deletionPolicy of a deleted LangfuseProject and
+// removes its finalizer. With the Delete policy the project is deleted once
+// no API key, prompt, score config or LLM connection refers to it any more.
+// With the Orphan policy the ownership marker is removed from the project
+// and its internal API key is revoked. Retain releases it without calling
+// Langfuse.
+func (r *LangfuseProjectReconciler) finalize(ctx context.Context,
+	project *langfusev1alpha1.LangfuseProject) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+	if !controllerutil.ContainsFinalizer(project, ProjectFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	policy := project.Spec.DeletionPolicy
+	if policy != langfusev1alpha1.DeletionPolicyDelete && policy != langfusev1alpha1.DeletionPolicyOrphan ||
+		project.Status.ID == "" {
+		if project.Status.ID != "" {
+			log.Info("Retaining Langfuse Project", "id", project.Status.ID)
+		}
+		return r.release(ctx, project)
+	}
+
+	// Without its connection the project cannot be deleted or orphaned ever,
+	// so it is left in Langfuse as is rather than blocking the deletion.
+	if _, _, err := langfuseFor(ctx, r.Connections, r.LangfuseClient, project.Namespace,
+		project.Spec.ConnectionRef, project.Status.Connection); errors.Is(err, errConnectionNotFound) ||
+		apierrors.IsNotFound(err) {
+		log.Info("Connection is gone, leaving Langfuse Project in place", "id", project.Status.ID, "error", err.Error())
+		if r.Recorder != nil {
+			r.Recorder.Eventf(project, corev1.EventTypeWarning, ReasonConnectionNotFound,
+				"Langfuse project %s was left as is because its connection is gone: %v", project.Status.ID, err)
+		}
+		return r.release(ctx, project)
+	}
+	lf, _, ok, err := requireConnection(ctx, r.Client, r.Connections, r.LangfuseClient, project.Namespace,
+		project.Spec.ConnectionRef, project.Status.Connection, project, &project.Status.Conditions)
+	if !ok || err != nil {
+		return ctrl.Result{RequeueAfter: connectionRequeueAfter}, err
+	}
+	if ok, err := requireLangfuse(ctx, r.Client, lf, project, &project.Status.Conditions); !ok || err != nil {
+		return ctrl.Result{RequeueAfter: unavailableRequeueAfter}, err
+	}
+	if policy == langfusev1alpha1.DeletionPolicyOrphan {
+		if err := r.orphanProject(ctx, lf, project); err != nil {
+			return ctrl.Result{}, err
+		}
+		return r.release(ctx, project)
+	}
+
+	children, err := r.childResources(ctx, project)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if len(children) > 0 {
+		log.Info("Waiting for child resources to be deleted before deleting the Langfuse Project",
+			"children", children)
+		if meta.SetStatusCondition(&project.Status.Conditions, metav1.Condition{
+			Type:               ConditionDeletionBlocked,
+			Status:             metav1.ConditionTrue,
+			Reason:             ReasonChildResourcesExist,
+			Message:            "Waiting for " + strings.Join(children, ", ") + " to be deleted",
+			ObservedGeneration: project.Generation,
+		}) {
+			if err := r.Status().Update(ctx, project); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		return ctrl.Result{RequeueAfter: childRequeueAfter}, nil
+	}
+	log.Info("Deleting Langfuse Project", "id", project.Status.ID)
+	if err := lf.DeleteProject(ctx, project.Status.ID); err != nil && !langfuse.IsNotFound(err) {
+		log.Error(err, "Failed to delete Langfuse Project")
+		return ctrl.Result{}, err
+	}
+	return r.release(ctx, project)
+}
+
+// orphanProject removes the owner from the ownership marker of the Langfuse
+// project of project, so that no new LangfuseProject adopts it by name, and
+// revokes its internal API key. Both are skipped if already gone.
+func (r *LangfuseProjectReconciler) orphanProject(ctx context.Context, lf langfuse.LangfuseAPI,
+	project *langfusev1alpha1.LangfuseProject) error {
+	log := logf.FromContext(ctx)
+	lfProject, err := lf.GetProject(ctx, project.Status.ID)
+	if err != nil && !langfuse.IsNotFound(err) {
+		return err
+	}
+	if err == nil && ownedByMetadata(lfProject.Metadata, "LangfuseProject", project) {
+		log.Info("Orphaning Langfuse Project", "id", project.Status.ID)
+		// Metadata is only replaced when it is not empty, so the managedBy
+		// entry stays. Without an owner it does not mark the project.
+		metadata := map[string]interface{}{}
+		for k, v := range lfProject.Metadata {
+			if k != metadataOwner {
+				metadata[k] = v
+			}
+		}
+		_, err := lf.UpdateProject(ctx, project.Status.ID, langfuse.UpdateProjectRequest{
+			Name:     lfProject.Name,
+			Metadata: metadata,
+		})
+		if err != nil && !langfuse.IsNotFound(err) {
+			return fmt.Errorf("removing ownership marker: %w", err)
+		}
+	}
+
+	secret := &corev1.Secret{}
+	err = r.Get(ctx, types.NamespacedName{Name: project.Name + projectKeySecretSuffix, Namespace: project.Namespace},
+		secret)
+	if client.IgnoreNotFound(err) != nil {
+		return err
+	}
+	if err != nil || !metav1.IsControlledBy(secret, project) || secret.Labels[LabelProjectID] != project.Status.ID {
+		return nil
+	}
+	if keyID := secret.Annotations[AnnotationAPIKeyID]; keyID != "" {
+		log.Info("Revoking internal project API key", "projectID", project.Status.ID, "keyID", keyID)
+		if err := lf.DeleteAPIKey(ctx, project.Status.ID, keyID); err != nil && !langfuse.IsNotFound(err) {
+			return fmt.Errorf("revoking internal project API key: %w", err)
+		}
+	}
+	return nil
+}
+
+// release removes the finalizer of project and forgets its internal key.
+func (r *LangfuseProjectReconciler) release(ctx context.Context,
+	project *langfusev1alpha1.LangfuseProject) (ctrl.Result, error) {
+	if project.Status.ID != "" && r.ProjectCredentials != nil {
+		r.ProjectCredentials.forget(project.Status.ID)
+	}
+	controllerutil.RemoveFinalizer(project, ProjectFinalizer)
+	return ctrl.Result{}, r.Update(ctx, project)
+}
+
+// childResources returns the resources in the namespace of project that
+// refer to it, as Kind/name.
+func (r *LangfuseProjectReconciler) childResources(ctx context.Context,
+	project *langfusev1alpha1.LangfuseProject) ([]string, error) {
+	var children []string
+	inNamespace := client.InNamespace(project.Namespace)
+
+	var apiKeys langfusev1alpha1.LangfuseAPIKeyList
+	if err := r.List(ctx, &apiKeys, inNamespace); err != nil {
+		return nil, err
+	}
+	for _, c := range apiKeys.Items {
+		if c.Spec.ProjectRef == project.Name {
+			children = append(children, "LangfuseAPIKey/"+c.Name)
+		}
+	}
+	var llmConnections langfusev1alpha1.LangfuseLlmConnectionList
+	if err := r.List(ctx, &llmConnections, inNamespace); err != nil {
+		return nil, err
+	}
+	for _, c := range llmConnections.Items {
+		if c.Spec.ProjectRef == project.Name {
+			children = append(children, "LangfuseLlmConnection/"+c.Name)
+		}
+	}
+	var prompts langfusev1alpha1.LangfusePromptList
+	if err := r.List(ctx, &prompts, inNamespace); err != nil {
+		return nil, err
+	}
+	for _, c := range prompts.Items {
+		if c.Spec.ProjectRef == project.Name {
+			children = append(children, "LangfusePrompt/"+c.Name)
+		}
+	}
+	var scoreConfigs langfusev1alpha1.LangfuseScoreConfigList
+	if err := r.List(ctx, &scoreConfigs, inNamespace); err != nil {
+		return nil, err
+	}
+	for _, c := range scoreConfigs.Items {
+		if c.Spec.ProjectRef == project.Name {
+			children = append(children, "LangfuseScoreConfig/"+c.Name)
+		}
+	}
+	return children, nil
+}
+
+// findProject returns the Langfuse project previously created for project,
+// or nil if there is none. Status is the only other record of it, so this
+// keeps a lost status update from creating a duplicate.
+func (r *LangfuseProjectReconciler) findProject(ctx context.Context, lf langfuse.LangfuseAPI,
+	project *langfusev1alpha1.LangfuseProject) (*langfuse.Project, error) {
+	for p, err := range lf.ListProjects(ctx) {
+		if err != nil {
+			return nil, err
+		}
+		if p.Name == project.Spec.Name && ownedByMetadata(p.Metadata, "LangfuseProject", project) {
+			return &p, nil
+		}
+	}
+	return nil, nil
+}
+
+// ensureProjectKey makes sure the project has an internal API key, stored in
+// a Secret owned by the LangfuseProject. The other controllers make their
+// project-level calls with this key, see ProjectCredentials. A key that was
+// revoked in Langfuse, or that Langfuse rejected, is replaced and revoked.
+// It returns false if the Secret exists but is not controlled by project;
+// the SecretInvalid condition then says so.
+func (r *LangfuseProjectReconciler) ensureProjectKey(ctx context.Context, lf langfuse.LangfuseAPI,
+	project *langfusev1alpha1.LangfuseProject) (bool, error) {
+	log := logf.FromContext(ctx)
+	secret := &corev1.Secret{}
+	key := types.NamespacedName{Name: project.Name + projectKeySecretSuffix, Namespace: project.Namespace}
+	err := r.Get(ctx, key, secret)
+	if client.IgnoreNotFound(err) != nil {
+		return false, err
+	}
+	exists := err == nil
+	if exists && !metav1.IsControlledBy(secret, project) {
+		meta.SetStatusCondition(&project.Status.Conditions, metav1.Condition{
+			Type:               ConditionSecretInvalid,
+			Status:             metav1.ConditionTrue,
+			Reason:             ReasonSecretNotOwned,
+			Message:            fmt.Sprintf("Secret %s exists and is not managed by this LangfuseProject", key.Name),
+			ObservedGeneration: project.Generation,
+		})
+		return false, nil
+	}
+	meta.RemoveStatusCondition(&project.Status.Conditions, ConditionSecretInvalid)
+
+	// The key to revoke once it is replaced.
+	oldKeyID := ""
+	if exists && secret.Labels[LabelProjectID] == project.Status.ID {
+		oldKeyID = secret.Annotations[AnnotationAPIKeyID]
+		creds, ok := credentialsFromSecret(secret)
+		rejected := r.ProjectCredentials != nil && r.ProjectCredentials.Rejected(project.Status.ID)
+		if ok && !rejected {
+			listed, err := apiKeyListed(ctx, lf, project.Status.ID, oldKeyID)
+			if err != nil {
+				return false, err
+			}
+			if listed {
+				if r.ProjectCredentials != nil {
+					r.ProjectCredentials.set(project, creds)
+				}
+				return true, nil
+			}
+		}
+		log.Info("Replacing internal project API key", "projectID", project.Status.ID, "keyID", oldKeyID,
+			"rejected", rejected)
+	} else {
+		log.Info("Creating internal project API key", "projectID", project.Status.ID)
+	}
+	lfKey, err := lf.CreateAPIKey(ctx, project.Status.ID, projectKeyNote)
+	if err != nil {
+		return false, err
+	}
+
+	secret.Name, secret.Namespace = key.Name, key.Namespace
+	secret.Labels = map[string]string{LabelProjectID: project.Status.ID}
+	secret.Annotations = map[string]string{AnnotationAPIKeyID: lfKey.ID}
+	secret.Data = map[string][]byte{
+		"LANGFUSE_PUBLIC_KEY": []byte(lfKey.PublicKey),
+		"LANGFUSE_SECRET_KEY": []byte(lfKey.SecretKey),
+		"LANGFUSE_HOST":       []byte(lf.Host()),
+	}
+	if err := ctrl.SetControllerReference(project, secret, r.Scheme); err != nil {
+		return false, err
+	}
+	if exists {
+		err = r.Update(ctx, secret)
+	} else {
+		err = r.Create(ctx, secret)
+	}
+	if err != nil {
+		// The secret key cannot be read back, so do not leave an unusable
+		// key behind.
+		if delErr := lf.DeleteAPIKey(ctx, project.Status.ID, lfKey.ID); delErr != nil {
+			log.Error(delErr, "Failed to revoke unstored internal project API key", "keyID", lfKey.ID)
+		}
+		return false, fmt.Errorf("storing internal project API key: %w", err)
+	}
+
+	if r.ProjectCredentials != nil {
+		r.ProjectCredentials.set(project, langfuse.Credentials{
+			PublicKey: lfKey.PublicKey,
+			SecretKey: lfKey.SecretKey,
+		})
+	}
+	if oldKeyID != "" {
+		if err := lf.DeleteAPIKey(ctx, project.Status.ID, oldKeyID); err != nil && !langfuse.IsNotFound(err) {
+			log.Error(err, "Failed to revoke replaced internal project API key", "keyID", oldKeyID)
+		}
+	}
+	return true, nil
+}
+
+// apiKeyListed reports whether Langfuse still lists the API key keyID of
+// projectID.
+func apiKeyListed(ctx context.Context, lf langfuse.LangfuseAPI, projectID, keyID string) (bool, error) {
+	if keyID == "" {
+		return false, nil
+	}
+	for key, err := range lf.ListAPIKeys(ctx, projectID) {
+		if err != nil {
+			return false, err
+		}
+		if key.ID == keyID {
+			return true, nil
+		}
+	}
+	return false, nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *LangfuseProjectReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	b := ctrl.NewControllerManagedBy(mgr).
 		For(&langfusev1alpha1.LangfuseProject{}).
-		WithOptions(controller.Options{
-			MaxConcurrentReconciles: 1, // avoids event storms
-		}).
+		Owns(&corev1.Secret{})
+	if r.ProjectCredentials != nil {
+		// Replace internal keys as soon as Langfuse rejects them.
+		b = b.WatchesRawSource(source.Channel(r.ProjectCredentials.Events(), &handler.EnqueueRequestForObject{}))
+	}
+	return b.WithOptions(controller.Options{
+		MaxConcurrentReconciles: 1, // avoids event storms
+	}).
 		Named("langfuseproject").
-		Complete(r)
+		Complete(traced("LangfuseProject", r))
 }
@@ -18,16 +18,23 @@ package controller
 
 import (
 	"context"
+	"net/http"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	langfusev1alpha1 "github.com/sqaisar/langfuse-controller/api/v1alpha1"
+	"github.com/sqaisar/langfuse-controller/internal/langfuse"
+	"github.com/sqaisar/langfuse-controller/internal/langfuse/fake"
 )
 
 var _ = Describe("LangfuseProject Controller", func() {
@@ -66,24 +73,692 @@ var _ = Describe("LangfuseProject Controller", func() {
 			Expect(err).NotTo(HaveOccurred())
 
 			By("Cleanup the specific resource instance LangfuseProject")
-			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+			deleteAndRelease(ctx, resource)
 		})
 		It("should successfully reconcile the resource", func() {
-			// Skip this test as it requires a real Langfuse instance
-			// TODO: Add mock Langfuse client for testing
-			Skip("Requires Langfuse API - add mocking for unit tests")
-
 			By("Reconciling the created resource")
+			lfClient := fake.NewClient()
 			controllerReconciler := &LangfuseProjectReconciler{
-				Client: k8sClient,
-				Scheme: k8sClient.Scheme(),
-				// LangfuseClient would need to be mocked here
+				Client:         k8sClient,
+				Scheme:         k8sClient.Scheme(),
+				LangfuseClient: lfClient,
 			}
 
 			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
 				NamespacedName: typeNamespacedName,
 			})
 			Expect(err).NotTo(HaveOccurred())
+
+			By("Checking the project was created in Langfuse")
+			projects := lfClient.Projects()
+			Expect(projects).To(HaveLen(1))
+			Expect(projects[0].Name).To(Equal("Test Project"))
+
+			resource := &langfusev1alpha1.LangfuseProject{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.ID).To(Equal(projects[0].ID))
+			Expect(resource.Status.State).To(Equal("Ready"))
+			Expect(meta.IsStatusConditionTrue(resource.Status.Conditions, ConditionAuthenticated)).To(BeTrue())
+
+			By("Storing an internal project API key in an owned Secret")
+			secret := &corev1.Secret{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{
+				Name:      resourceName + projectKeySecretSuffix,
+				Namespace: "default",
+			}, secret)).To(Succeed())
+			Expect(secret.Labels).To(HaveKeyWithValue(LabelProjectID, projects[0].ID))
+			Expect(metav1.IsControlledBy(secret, resource)).To(BeTrue())
+			keys, err := langfuse.Collect(lfClient.ListAPIKeys(ctx, projects[0].ID))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(keys).To(HaveLen(1))
+			Expect(string(secret.Data["LANGFUSE_PUBLIC_KEY"])).To(Equal(keys[0].PublicKey))
+
+			creds, ok, err := (&ProjectCredentials{Reader: k8sClient}).ProjectCredentials(ctx, projects[0].ID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(creds.PublicKey).To(Equal(keys[0].PublicKey))
+
+			By("Reconciling again without creating a duplicate")
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lfClient.Projects()).To(HaveLen(1))
+			Expect(lfClient.Calls("CreateAPIKey")).To(Equal(1))
+		})
+
+		It("should replace internal project keys that were revoked or rejected", func() {
+			lfClient := fake.NewClient()
+			creds := &ProjectCredentials{Reader: k8sClient}
+			controllerReconciler := &LangfuseProjectReconciler{
+				Client:             k8sClient,
+				Scheme:             k8sClient.Scheme(),
+				LangfuseClient:     lfClient,
+				ProjectCredentials: creds,
+			}
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			id := lfClient.Projects()[0].ID
+			secretKey := types.NamespacedName{Name: resourceName + projectKeySecretSuffix, Namespace: "default"}
+			// storedKey returns the only key of the project, which must be
+			// the one in the Secret.
+			storedKey := func() langfuse.APIKeySummary {
+				keys, err := langfuse.Collect(lfClient.ListAPIKeys(ctx, id))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(keys).To(HaveLen(1))
+				secret := &corev1.Secret{}
+				Expect(k8sClient.Get(ctx, secretKey, secret)).To(Succeed())
+				Expect(secret.Annotations).To(HaveKeyWithValue(AnnotationAPIKeyID, keys[0].ID))
+				Expect(string(secret.Data["LANGFUSE_PUBLIC_KEY"])).To(Equal(keys[0].PublicKey))
+				return keys[0]
+			}
+			original := storedKey()
+
+			By("replacing a key revoked in Langfuse")
+			Expect(lfClient.DeleteAPIKey(ctx, id, original.ID)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			replaced := storedKey()
+			Expect(replaced.ID).NotTo(Equal(original.ID))
+
+			By("replacing and revoking a key Langfuse rejected")
+			creds.ProjectCredentialsRejected(id)
+			Expect(creds.Events()).To(Receive())
+			_, ok, err := creds.ProjectCredentials(ctx, id)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeFalse())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(storedKey().ID).NotTo(Equal(replaced.ID))
+			current, ok, err := creds.ProjectCredentials(ctx, id)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(current.PublicKey).To(Equal(storedKey().PublicKey))
+		})
+
+		It("should not take over a key Secret it does not control", func() {
+			lfClient := fake.NewClient()
+			controllerReconciler := &LangfuseProjectReconciler{
+				Client:         k8sClient,
+				Scheme:         k8sClient.Scheme(),
+				LangfuseClient: lfClient,
+			}
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName + projectKeySecretSuffix, Namespace: "default"},
+				StringData: map[string]string{"LANGFUSE_PUBLIC_KEY": "pk-user"},
+			}
+			Expect(k8sClient.Create(ctx, secret)).To(Succeed())
+			defer func() { Expect(k8sClient.Delete(ctx, secret)).To(Succeed()) }()
+
+			result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(connectionRequeueAfter))
+			Expect(lfClient.Calls("CreateAPIKey")).To(BeZero())
+
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}, secret)).To(Succeed())
+			Expect(string(secret.Data["LANGFUSE_PUBLIC_KEY"])).To(Equal("pk-user"))
+			Expect(secret.OwnerReferences).To(BeEmpty())
+			resource := &langfusev1alpha1.LangfuseProject{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			cond := meta.FindStatusCondition(resource.Status.Conditions, ConditionSecretInvalid)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+			Expect(cond.Reason).To(Equal(ReasonSecretNotOwned))
+		})
+
+		It("should not serve project keys from Secrets the project does not control", func() {
+			other := types.NamespacedName{Name: "uncontrolled-key-project", Namespace: "default"}
+			createReadyProject(ctx, other, "p-uncontrolled")
+			defer deleteProject(ctx, other)
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      other.Name + projectKeySecretSuffix,
+					Namespace: other.Namespace,
+					Labels:    map[string]string{LabelProjectID: "p-uncontrolled"},
+				},
+				StringData: map[string]string{
+					"LANGFUSE_PUBLIC_KEY": "pk-planted",
+					"LANGFUSE_SECRET_KEY": "sk-planted",
+				},
+			}
+			Expect(k8sClient.Create(ctx, secret)).To(Succeed())
+			defer func() { Expect(k8sClient.Delete(ctx, secret)).To(Succeed()) }()
+
+			_, ok, err := (&ProjectCredentials{Reader: k8sClient}).ProjectCredentials(ctx, "p-uncontrolled")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeFalse())
+		})
+
+		It("should adopt a project it created before losing the status update", func() {
+			lfClient := fake.NewClient()
+			existing, err := lfClient.CreateProject(ctx, langfuse.CreateProjectRequest{
+				Name: "Test Project",
+				Metadata: map[string]interface{}{
+					"managedBy": "langfuse-controller",
+					"owner":     "LangfuseProject/default/" + resourceName,
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			controllerReconciler := &LangfuseProjectReconciler{
+				Client:         k8sClient,
+				Scheme:         k8sClient.Scheme(),
+				LangfuseClient: lfClient,
+			}
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lfClient.Calls("CreateProject")).To(Equal(1))
+
+			resource := &langfusev1alpha1.LangfuseProject{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.ID).To(Equal(existing.ID))
+		})
+
+		It("should report an error state when Langfuse rejects the request", func() {
+			lfClient := fake.NewClient()
+			lfClient.SetError("CreateProject", &langfuse.APIError{StatusCode: http.StatusUnauthorized})
+			controllerReconciler := &LangfuseProjectReconciler{
+				Client:         k8sClient,
+				Scheme:         k8sClient.Scheme(),
+				LangfuseClient: lfClient,
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(langfuse.IsUnauthorized(err)).To(BeTrue())
+
+			resource := &langfusev1alpha1.LangfuseProject{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.State).To(Equal("Error"))
+			Expect(resource.Status.ID).To(BeEmpty())
+			cond := meta.FindStatusCondition(resource.Status.Conditions, ConditionAuthenticated)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+			Expect(cond.Reason).To(Equal(ReasonAuthenticationFailed))
+		})
+	})
+
+	Context("When adopting a project by ID", func() {
+		ctx := context.Background()
+		key := types.NamespacedName{Name: "adopted-project", Namespace: "default"}
+
+		var lfClient *fake.Client
+		var reconciler *LangfuseProjectReconciler
+
+		BeforeEach(func() {
+			lfClient = fake.NewClient()
+			reconciler = &LangfuseProjectReconciler{
+				Client:         k8sClient,
+				Scheme:         k8sClient.Scheme(),
+				LangfuseClient: lfClient,
+			}
+		})
+
+		AfterEach(func() {
+			project := &langfusev1alpha1.LangfuseProject{}
+			if err := k8sClient.Get(ctx, key, project); err == nil {
+				deleteAndRelease(ctx, project)
+			}
+			secret := &corev1.Secret{}
+			if err := k8sClient.Get(ctx, types.NamespacedName{
+				Name: key.Name + projectKeySecretSuffix, Namespace: key.Namespace,
+			}, secret); err == nil {
+				Expect(k8sClient.Delete(ctx, secret)).To(Succeed())
+			}
+		})
+
+		It("should take over the project without creating one", func() {
+			existing, err := lfClient.CreateProject(ctx, langfuse.CreateProjectRequest{Name: "Created In UI"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Create(ctx, &langfusev1alpha1.LangfuseProject{
+				ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+				Spec:       langfusev1alpha1.LangfuseProjectSpec{Name: "Adopted Project", ProjectID: existing.ID},
+			})).To(Succeed())
+
+			_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lfClient.Calls("CreateProject")).To(Equal(1))
+			projects := lfClient.Projects()
+			Expect(projects).To(HaveLen(1))
+			Expect(projects[0].Name).To(Equal("Adopted Project"))
+
+			project := &langfusev1alpha1.LangfuseProject{}
+			Expect(k8sClient.Get(ctx, key, project)).To(Succeed())
+			Expect(ownedByMetadata(projects[0].Metadata, "LangfuseProject", project)).To(BeTrue())
+			Expect(project.Status.ID).To(Equal(existing.ID))
+			Expect(project.Status.State).To(Equal("Ready"))
+			Expect(meta.IsStatusConditionTrue(project.Status.Conditions, ConditionSynced)).To(BeTrue())
+			keys, err := langfuse.Collect(lfClient.ListAPIKeys(ctx, existing.ID))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(keys).To(HaveLen(1))
+		})
+
+		It("should keep projectID from being changed, removed or added later", func() {
+			Expect(k8sClient.Create(ctx, &langfusev1alpha1.LangfuseProject{
+				ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+				Spec:       langfusev1alpha1.LangfuseProjectSpec{Name: "Adopted Project", ProjectID: "p-adopted"},
+			})).To(Succeed())
+			project := &langfusev1alpha1.LangfuseProject{}
+			Expect(k8sClient.Get(ctx, key, project)).To(Succeed())
+
+			By("rejecting a changed projectID")
+			changed := project.DeepCopy()
+			changed.Spec.ProjectID = "p-other"
+			err := k8sClient.Update(ctx, changed)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("projectID is immutable"))
+
+			By("rejecting a removed projectID")
+			removed := project.DeepCopy()
+			removed.Spec.ProjectID = ""
+			err = k8sClient.Update(ctx, removed)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("projectID is immutable"))
+			deleteAndRelease(ctx, project)
+
+			By("rejecting a projectID added once the project was created")
+			Expect(k8sClient.Create(ctx, &langfusev1alpha1.LangfuseProject{
+				ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+				Spec:       langfusev1alpha1.LangfuseProjectSpec{Name: "Created Project"},
+			})).To(Succeed())
+			_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			project = &langfusev1alpha1.LangfuseProject{}
+			Expect(k8sClient.Get(ctx, key, project)).To(Succeed())
+			Expect(project.Status.ID).NotTo(BeEmpty())
+			project.Spec.ProjectID = "p-adopted"
+			err = k8sClient.Update(ctx, project)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("projectID cannot be added once the project exists"))
+		})
+
+		It("should report a project ID that does not exist", func() {
+			Expect(k8sClient.Create(ctx, &langfusev1alpha1.LangfuseProject{
+				ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+				Spec: langfusev1alpha1.LangfuseProjectSpec{
+					Name:                 "Adopted Project",
+					ProjectID:            "missing",
+					RemoteDeletionPolicy: langfusev1alpha1.RemoteDeletionPolicyRecreate,
+				},
+			})).To(Succeed())
+
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lfClient.Projects()).To(BeEmpty())
+
+			project := &langfusev1alpha1.LangfuseProject{}
+			Expect(k8sClient.Get(ctx, key, project)).To(Succeed())
+			Expect(project.Status.ID).To(BeEmpty())
+			Expect(project.Status.State).To(Equal("Error"))
+			cond := meta.FindStatusCondition(project.Status.Conditions, ConditionSynced)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+			Expect(cond.Reason).To(Equal(ReasonNotFound))
+		})
+
+		It("should refuse a project that another resource manages", func() {
+			existing, err := lfClient.CreateProject(ctx, langfuse.CreateProjectRequest{Name: "Managed"})
+			Expect(err).NotTo(HaveOccurred())
+			ownerKey := types.NamespacedName{Name: "owning-project", Namespace: "default"}
+			owner := &langfusev1alpha1.LangfuseProject{
+				ObjectMeta: metav1.ObjectMeta{Name: ownerKey.Name, Namespace: ownerKey.Namespace},
+				Spec:       langfusev1alpha1.LangfuseProjectSpec{Name: "Managed"},
+			}
+			Expect(k8sClient.Create(ctx, owner)).To(Succeed())
+			DeferCleanup(func() { Expect(k8sClient.Delete(ctx, owner)).To(Succeed()) })
+			owner.Status.ID = existing.ID
+			Expect(k8sClient.Status().Update(ctx, owner)).To(Succeed())
+
+			Expect(k8sClient.Create(ctx, &langfusev1alpha1.LangfuseProject{
+				ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+				Spec:       langfusev1alpha1.LangfuseProjectSpec{Name: "Adopted Project", ProjectID: existing.ID},
+			})).To(Succeed())
+
+			result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(adoptRequeueAfter))
+			Expect(lfClient.Projects()[0].Name).To(Equal("Managed"))
+
+			project := &langfusev1alpha1.LangfuseProject{}
+			Expect(k8sClient.Get(ctx, key, project)).To(Succeed())
+			Expect(project.Status.ID).To(BeEmpty())
+			Expect(project.Status.State).To(Equal("Error"))
+			cond := meta.FindStatusCondition(project.Status.Conditions, ConditionSynced)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Reason).To(Equal(ReasonAlreadyOwned))
+			Expect(cond.Message).To(ContainSubstring("LangfuseProject/default/owning-project"))
+		})
+	})
+
+	Context("When deleting a resource", func() {
+		ctx := context.Background()
+		key := types.NamespacedName{Name: "deleted-project", Namespace: "default"}
+		promptKey := types.NamespacedName{Name: "deleted-project-prompt", Namespace: "default"}
+
+		var lfClient *fake.Client
+		var reconciler *LangfuseProjectReconciler
+
+		// createAndDelete creates a LangfuseProject with policy, reconciles it
+		// so that the Langfuse project exists and deletes it.
+		createAndDelete := func(policy langfusev1alpha1.DeletionPolicy) {
+			Expect(k8sClient.Create(ctx, &langfusev1alpha1.LangfuseProject{
+				ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+				Spec:       langfusev1alpha1.LangfuseProjectSpec{Name: "Deleted Project", DeletionPolicy: policy},
+			})).To(Succeed())
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lfClient.Projects()).To(HaveLen(1))
+
+			project := &langfusev1alpha1.LangfuseProject{}
+			Expect(k8sClient.Get(ctx, key, project)).To(Succeed())
+			Expect(project.Finalizers).To(ContainElement(ProjectFinalizer))
+			Expect(k8sClient.Delete(ctx, project)).To(Succeed())
+		}
+
+		BeforeEach(func() {
+			lfClient = fake.NewClient()
+			reconciler = &LangfuseProjectReconciler{
+				Client:         k8sClient,
+				Scheme:         k8sClient.Scheme(),
+				LangfuseClient: lfClient,
+			}
+		})
+
+		AfterEach(func() {
+			prompt := &langfusev1alpha1.LangfusePrompt{}
+			if err := k8sClient.Get(ctx, promptKey, prompt); err == nil {
+				Expect(k8sClient.Delete(ctx, prompt)).To(Succeed())
+			}
+			project := &langfusev1alpha1.LangfuseProject{}
+			if err := k8sClient.Get(ctx, key, project); err == nil {
+				deleteAndRelease(ctx, project)
+			}
+			secret := &corev1.Secret{}
+			if err := k8sClient.Get(ctx, types.NamespacedName{
+				Name: key.Name + projectKeySecretSuffix, Namespace: key.Namespace,
+			}, secret); err == nil {
+				Expect(k8sClient.Delete(ctx, secret)).To(Succeed())
+			}
+		})
+
+		It("should delete the project once its child resources are gone", func() {
+			Expect(k8sClient.Create(ctx, &langfusev1alpha1.LangfusePrompt{
+				ObjectMeta: metav1.ObjectMeta{Name: promptKey.Name, Namespace: promptKey.Namespace},
+				Spec: langfusev1alpha1.LangfusePromptSpec{
+					ProjectRef: key.Name,
+					Name:       "greeting",
+					Prompt:     "Hello",
+					Type:       "text",
+				},
+			})).To(Succeed())
+			createAndDelete(langfusev1alpha1.DeletionPolicyDelete)
+
+			By("waiting for the prompt that refers to the project")
+			result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(childRequeueAfter))
+			Expect(lfClient.Projects()).To(HaveLen(1))
+			project := &langfusev1alpha1.LangfuseProject{}
+			Expect(k8sClient.Get(ctx, key, project)).To(Succeed())
+			cond := meta.FindStatusCondition(project.Status.Conditions, ConditionDeletionBlocked)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Message).To(ContainSubstring("LangfusePrompt/" + promptKey.Name))
+
+			By("deleting the project once the prompt is gone")
+			Expect(k8sClient.Delete(ctx, &langfusev1alpha1.LangfusePrompt{ObjectMeta: metav1.ObjectMeta{
+				Name: promptKey.Name, Namespace: promptKey.Namespace,
+			}})).To(Succeed())
+			_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lfClient.Projects()).To(BeEmpty())
+			Expect(k8sClient.Get(ctx, key, project)).To(Satisfy(errors.IsNotFound))
+		})
+
+		It("should retain the project without calling Langfuse", func() {
+			createAndDelete(langfusev1alpha1.DeletionPolicyRetain)
+			lfClient.SetUnavailable(true)
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, key, &langfusev1alpha1.LangfuseProject{})).To(Satisfy(errors.IsNotFound))
+
+			lfClient.SetUnavailable(false)
+			projects := lfClient.Projects()
+			Expect(projects).To(HaveLen(1))
+			Expect(lfClient.Calls("DeleteAPIKey")).To(BeZero())
+			keys, err := langfuse.Collect(lfClient.ListAPIKeys(ctx, projects[0].ID))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(keys).To(HaveLen(1))
+		})
+
+		It("should unmark the project and revoke its internal key when orphaning it", func() {
+			createAndDelete(langfusev1alpha1.DeletionPolicyOrphan)
+			projects := lfClient.Projects()
+			Expect(projects[0].Metadata).To(HaveKeyWithValue(metadataOwner, "LangfuseProject/default/"+key.Name))
+			keys, err := langfuse.Collect(lfClient.ListAPIKeys(ctx, projects[0].ID))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(keys).To(HaveLen(1))
+
+			By("keeping the project while Langfuse is unavailable")
+			lfClient.SetUnavailable(true)
+			_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, key, &langfusev1alpha1.LangfuseProject{})).To(Succeed())
+
+			By("orphaning the project once Langfuse is back")
+			lfClient.SetUnavailable(false)
+			_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, key, &langfusev1alpha1.LangfuseProject{})).To(Satisfy(errors.IsNotFound))
+
+			projects = lfClient.Projects()
+			Expect(projects).To(HaveLen(1))
+			Expect(projects[0].Metadata).NotTo(HaveKey(metadataOwner))
+			Expect(lfClient.Calls("DeleteAPIKey")).To(Equal(1))
+			keys, err = langfuse.Collect(lfClient.ListAPIKeys(ctx, projects[0].ID))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(keys).To(BeEmpty())
+		})
+	})
+	Context("When the project changes in Langfuse", func() {
+		ctx := context.Background()
+		key := types.NamespacedName{Name: "drifted-project", Namespace: "default"}
+
+		var lfClient *fake.Client
+		var reconciler *LangfuseProjectReconciler
+
+		// createProject creates a LangfuseProject with policy and reconciles
+		// it, returning the ID of the Langfuse project.
+		createProject := func(policy langfusev1alpha1.RemoteDeletionPolicy) string {
+			Expect(k8sClient.Create(ctx, &langfusev1alpha1.LangfuseProject{
+				ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+				Spec: langfusev1alpha1.LangfuseProjectSpec{
+					Name:                 "Drifted Project",
+					RemoteDeletionPolicy: policy,
+				},
+			})).To(Succeed())
+			result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(reconciler.SyncInterval))
+
+			project := &langfusev1alpha1.LangfuseProject{}
+			Expect(k8sClient.Get(ctx, key, project)).To(Succeed())
+			cond := meta.FindStatusCondition(project.Status.Conditions, ConditionSynced)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+			Expect(cond.ObservedGeneration).To(Equal(project.Generation))
+			return project.Status.ID
+		}
+
+		BeforeEach(func() {
+			lfClient = fake.NewClient()
+			reconciler = &LangfuseProjectReconciler{
+				Client:         k8sClient,
+				Scheme:         k8sClient.Scheme(),
+				LangfuseClient: lfClient,
+				SyncInterval:   time.Minute,
+			}
+		})
+
+		AfterEach(func() {
+			project := &langfusev1alpha1.LangfuseProject{}
+			if err := k8sClient.Get(ctx, key, project); err == nil {
+				deleteAndRelease(ctx, project)
+			}
+			secret := &corev1.Secret{}
+			if err := k8sClient.Get(ctx, types.NamespacedName{
+				Name: key.Name + projectKeySecretSuffix, Namespace: key.Namespace,
+			}, secret); err == nil {
+				Expect(k8sClient.Delete(ctx, secret)).To(Succeed())
+			}
+		})
+
+		It("should push renames to Langfuse", func() {
+			id := createProject(langfusev1alpha1.RemoteDeletionPolicyReport)
+
+			By("renaming the project back after it was renamed in the UI")
+			_, err := lfClient.UpdateProject(ctx, id, langfuse.UpdateProjectRequest{Name: "Renamed In UI"})
+			Expect(err).NotTo(HaveOccurred())
+			_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lfClient.Projects()[0].Name).To(Equal("Drifted Project"))
+
+			By("renaming the project after spec.name changed")
+			project := &langfusev1alpha1.LangfuseProject{}
+			Expect(k8sClient.Get(ctx, key, project)).To(Succeed())
+			project.Spec.Name = "Renamed Project"
+			Expect(k8sClient.Update(ctx, project)).To(Succeed())
+			_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			projects := lfClient.Projects()
+			Expect(projects).To(HaveLen(1))
+			Expect(projects[0].ID).To(Equal(id))
+			Expect(projects[0].Name).To(Equal("Renamed Project"))
+
+			Expect(k8sClient.Get(ctx, key, project)).To(Succeed())
+			cond := meta.FindStatusCondition(project.Status.Conditions, ConditionSynced)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+			Expect(cond.ObservedGeneration).To(Equal(project.Generation))
+		})
+
+		It("should reconcile the project settings", func() {
+			By("rejecting invalid settings")
+			invalid := &langfusev1alpha1.LangfuseProject{
+				ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+				Spec: langfusev1alpha1.LangfuseProjectSpec{
+					Name:          "Drifted Project",
+					RetentionDays: ptr.To[int32](1),
+					Metadata:      map[string]string{"owner": "someone"},
+				},
+			}
+			err := k8sClient.Create(ctx, invalid)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("retentionDays must be 0 or at least 3"))
+			Expect(err.Error()).To(ContainSubstring("managedBy and owner are reserved"))
+
+			By("creating the project with its settings")
+			Expect(k8sClient.Create(ctx, &langfusev1alpha1.LangfuseProject{
+				ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+				Spec: langfusev1alpha1.LangfuseProjectSpec{
+					Name:          "Drifted Project",
+					RetentionDays: ptr.To[int32](30),
+					Metadata:      map[string]string{"team": "search"},
+				},
+			})).To(Succeed())
+			_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			projects := lfClient.Projects()
+			Expect(projects).To(HaveLen(1))
+			Expect(projects[0].RetentionDays).To(HaveValue(Equal(30)))
+			Expect(projects[0].Metadata).To(HaveKeyWithValue("team", "search"))
+			Expect(projects[0].Metadata).To(HaveKeyWithValue("managedBy", "langfuse-controller"))
+
+			project := &langfusev1alpha1.LangfuseProject{}
+			Expect(k8sClient.Get(ctx, key, project)).To(Succeed())
+			Expect(project.Status.RetentionDays).To(HaveValue(BeEquivalentTo(30)))
+			Expect(project.Status.Metadata).To(HaveKeyWithValue("team", "search"))
+
+			By("restoring metadata changed in the UI")
+			id := projects[0].ID
+			_, err = lfClient.UpdateProject(ctx, id, langfuse.UpdateProjectRequest{
+				Name:     "Drifted Project",
+				Metadata: map[string]interface{}{"team": "other"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lfClient.Projects()[0].Metadata).To(HaveKeyWithValue("team", "search"))
+
+			By("restoring a retention changed in the UI")
+			_, err = lfClient.UpdateProject(ctx, id, langfuse.UpdateProjectRequest{
+				Name:      "Drifted Project",
+				Retention: ptr.To(7),
+			})
+			Expect(err).NotTo(HaveOccurred())
+			_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lfClient.Projects()[0].RetentionDays).To(HaveValue(Equal(30)))
+
+			By("pushing a new retention")
+			Expect(k8sClient.Get(ctx, key, project)).To(Succeed())
+			project.Spec.RetentionDays = ptr.To[int32](0)
+			Expect(k8sClient.Update(ctx, project)).To(Succeed())
+			_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lfClient.Projects()[0].RetentionDays).To(BeNil())
+			Expect(k8sClient.Get(ctx, key, project)).To(Succeed())
+			Expect(project.Status.RetentionDays).To(BeNil())
+			Expect(meta.IsStatusConditionTrue(project.Status.Conditions, ConditionSynced)).To(BeTrue())
+
+			By("not updating a project that matches the spec")
+			updates := lfClient.Calls("UpdateProject")
+			_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lfClient.Calls("UpdateProject")).To(Equal(updates))
+		})
+
+		It("should report a project deleted in Langfuse", func() {
+			id := createProject(langfusev1alpha1.RemoteDeletionPolicyReport)
+			Expect(lfClient.DeleteProject(ctx, id)).To(Succeed())
+
+			result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(reconciler.SyncInterval))
+			Expect(lfClient.Projects()).To(BeEmpty())
+
+			project := &langfusev1alpha1.LangfuseProject{}
+			Expect(k8sClient.Get(ctx, key, project)).To(Succeed())
+			Expect(project.Status.ID).To(Equal(id))
+			Expect(project.Status.State).To(Equal("Error"))
+			cond := meta.FindStatusCondition(project.Status.Conditions, ConditionSynced)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+			Expect(cond.Reason).To(Equal(ReasonRemoteDeleted))
+		})
+
+		It("should recreate a project deleted in Langfuse", func() {
+			id := createProject(langfusev1alpha1.RemoteDeletionPolicyRecreate)
+			Expect(lfClient.DeleteProject(ctx, id)).To(Succeed())
+
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			projects := lfClient.Projects()
+			Expect(projects).To(HaveLen(1))
+			Expect(projects[0].ID).NotTo(Equal(id))
+			Expect(projects[0].Name).To(Equal("Drifted Project"))
+
+			project := &langfusev1alpha1.LangfuseProject{}
+			Expect(k8sClient.Get(ctx, key, project)).To(Succeed())
+			Expect(project.Status.ID).To(Equal(projects[0].ID))
+			Expect(meta.IsStatusConditionTrue(project.Status.Conditions, ConditionSynced)).To(BeTrue())
+			secret := &corev1.Secret{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{
+				Name: key.Name + projectKeySecretSuffix, Namespace: key.Namespace,
+			}, secret)).To(Succeed())
+			Expect(secret.Labels).To(HaveKeyWithValue(LabelProjectID, projects[0].ID))
 		})
 	})
 })
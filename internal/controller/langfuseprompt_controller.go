@@ -18,6 +18,7 @@ package controller
 
 import (
 	"context"
+	"slices"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -27,6 +28,7 @@ import (
 
 	langfusev1alpha1 "github.com/sqaisar/langfuse-controller/api/v1alpha1"
 	"github.com/sqaisar/langfuse-controller/internal/langfuse"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 )
@@ -35,7 +37,10 @@ import (
 type LangfusePromptReconciler struct {
 	client.Client
 	Scheme         *runtime.Scheme
-	LangfuseClient *langfuse.Client
+	LangfuseClient langfuse.LangfuseAPI
+	// Connections, if set, provides the clients of resources managed in
+	// a LangfuseConnection. Other resources use LangfuseClient.
+	Connections *Connections
 }
 
 // +kubebuilder:rbac:groups=langfuse.io,resources=langfuseprompts,verbs=get;list;watch;create;update;patch;delete
@@ -53,13 +58,15 @@ type LangfusePromptReconciler struct {
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.22.4/pkg/reconcile
 func (r *LangfusePromptReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	log := logf.FromContext(ctx)
+	// Prompts are synced in bulk; let other Langfuse calls go first.
+	ctx = langfuse.WithPriority(ctx, langfuse.PriorityLow)
 
 	var prompt langfusev1alpha1.LangfusePrompt
 	if err := r.Get(ctx, req.NamespacedName, &prompt); err != nil {
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
-	if len(prompt.Status.Conditions) > 0 && prompt.Status.Conditions[0].Status == metav1.ConditionTrue {
+	if meta.IsStatusConditionTrue(prompt.Status.Conditions, "Available") {
 		return ctrl.Result{}, nil
 	}
 
@@ -73,16 +80,42 @@ func (r *LangfusePromptReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		return ctrl.Result{Requeue: true}, nil
 	}
 
-	log.Info("Creating Prompt", "name", prompt.Spec.Name)
-	if err := r.LangfuseClient.CreatePrompt(project.Status.ID, map[string]interface{}{
-		"name":   prompt.Spec.Name,
-		"prompt": prompt.Spec.Prompt,
-		"type":   prompt.Spec.Type,
-		"config": prompt.Spec.Config,
-		"labels": prompt.Spec.Labels,
-	}); err != nil {
-		log.Error(err, "Failed to create Prompt")
-		return ctrl.Result{}, err
+	lf, _, ok, err := requireConnection(ctx, r.Client, r.Connections, r.LangfuseClient, project.Namespace,
+		project.Spec.ConnectionRef, project.Status.Connection, &prompt, &prompt.Status.Conditions)
+	if !ok || err != nil {
+		return ctrl.Result{RequeueAfter: connectionRequeueAfter}, err
+	}
+	if ok, err := requireLangfuse(ctx, r.Client, lf, &prompt, &prompt.Status.Conditions); !ok || err != nil {
+		return ctrl.Result{RequeueAfter: unavailableRequeueAfter}, err
+	}
+	if ok, err := requireCapability(ctx, r.Client, lf, langfuse.CapabilityPrompts, &prompt,
+		&prompt.Status.Conditions); !ok || err != nil {
+		return ctrl.Result{RequeueAfter: unsupportedRequeueAfter}, err
+	}
+
+	lfPrompt := langfuse.CreatePromptRequest{
+		Name:          prompt.Spec.Name,
+		Prompt:        prompt.Spec.Prompt,
+		Type:          prompt.Spec.Type,
+		Labels:        prompt.Spec.Labels,
+		CommitMessage: ownershipMarker("LangfusePrompt", &prompt),
+	}
+	if len(prompt.Spec.Config) > 0 {
+		lfPrompt.Config = prompt.Spec.Config
+	}
+	existing, err := r.findPromptVersion(ctx, lf, project.Status.ID, lfPrompt)
+	if err != nil {
+		log.Error(err, "Failed to look up Prompt")
+		return langfuseError(ctx, r.Client, &prompt, &prompt.Status.Conditions, err)
+	}
+	if existing != nil {
+		log.Info("Adopting existing Prompt version", "name", prompt.Spec.Name, "version", existing.Version)
+	} else {
+		log.Info("Creating Prompt", "name", prompt.Spec.Name)
+		if _, err := lf.CreatePrompt(ctx, project.Status.ID, lfPrompt); err != nil {
+			log.Error(err, "Failed to create Prompt")
+			return langfuseError(ctx, r.Client, &prompt, &prompt.Status.Conditions, err)
+		}
 	}
 
 	prompt.Status.Conditions = []metav1.Condition{{
@@ -99,6 +132,38 @@ func (r *LangfusePromptReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 	return ctrl.Result{}, nil
 }
 
+// findPromptVersion returns the newest version of the prompt if this
+// controller created it with exactly the content of want, or nil. Creating
+// the prompt again would add an identical version.
+func (r *LangfusePromptReconciler) findPromptVersion(ctx context.Context, lf langfuse.LangfuseAPI,
+	projectID string, want langfuse.CreatePromptRequest) (*langfuse.Prompt, error) {
+	latest, err := lf.GetPrompt(ctx, projectID, want.Name, langfuse.GetPromptOptions{Label: "latest"})
+	if langfuse.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if latest.CommitMessage != want.CommitMessage || latest.Type != want.Type ||
+		!sameJSON(latest.Prompt, want.Prompt) || !sameJSON(promptConfig(latest.Config), promptConfig(want.Config)) {
+		return nil, nil
+	}
+	for _, label := range want.Labels {
+		if !slices.Contains(latest.Labels, label) {
+			return nil, nil
+		}
+	}
+	return latest, nil
+}
+
+// promptConfig treats a missing prompt config like an empty one.
+func promptConfig(config interface{}) interface{} {
+	if config == nil {
+		return map[string]interface{}{}
+	}
+	return config
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *LangfusePromptReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
@@ -107,5 +172,5 @@ func (r *LangfusePromptReconciler) SetupWithManager(mgr ctrl.Manager) error {
 			MaxConcurrentReconciles: 1, // avoids event storms
 		}).
 		Named("langfuseprompt").
-		Complete(r)
+		Complete(traced("LangfusePrompt", r))
 }
@@ -28,6 +28,8 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	langfusev1alpha1 "github.com/sqaisar/langfuse-controller/api/v1alpha1"
+	"github.com/sqaisar/langfuse-controller/internal/langfuse"
+	"github.com/sqaisar/langfuse-controller/internal/langfuse/fake"
 )
 
 var _ = Describe("LangfusePrompt Controller", func() {
@@ -40,46 +42,105 @@ var _ = Describe("LangfusePrompt Controller", func() {
 			Name:      resourceName,
 			Namespace: "default", // TODO(user):Modify as needed
 		}
+		projectNamespacedName := types.NamespacedName{
+			Name:      "langfuseprompt-project",
+			Namespace: "default",
+		}
 		langfuseprompt := &langfusev1alpha1.LangfusePrompt{}
 
+		var lfClient *fake.Client
+		var projectID string
+
 		BeforeEach(func() {
+			By("creating the Langfuse project and its LangfuseProject")
+			lfClient = fake.NewClient()
+			lfProject, err := lfClient.CreateProject(ctx, langfuse.CreateProjectRequest{Name: "LangfusePrompt Test Project"})
+			Expect(err).NotTo(HaveOccurred())
+			projectID = lfProject.ID
+			createReadyProject(ctx, projectNamespacedName, projectID)
+
 			By("creating the custom resource for the Kind LangfusePrompt")
-			err := k8sClient.Get(ctx, typeNamespacedName, langfuseprompt)
+			err = k8sClient.Get(ctx, typeNamespacedName, langfuseprompt)
 			if err != nil && errors.IsNotFound(err) {
 				resource := &langfusev1alpha1.LangfusePrompt{
 					ObjectMeta: metav1.ObjectMeta{
 						Name:      resourceName,
 						Namespace: "default",
 					},
-					// TODO(user): Specify other spec details if needed.
+					Spec: langfusev1alpha1.LangfusePromptSpec{
+						ProjectRef: projectNamespacedName.Name,
+						Name:       "test-prompt",
+						Prompt:     "Summarise {{text}}",
+						Type:       "text",
+						Labels:     []string{"production"},
+					},
 				}
 				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
 			}
 		})
 
 		AfterEach(func() {
-			// TODO(user): Cleanup logic after each test, like removing the resource instance.
 			resource := &langfusev1alpha1.LangfusePrompt{}
 			err := k8sClient.Get(ctx, typeNamespacedName, resource)
 			Expect(err).NotTo(HaveOccurred())
 
 			By("Cleanup the specific resource instance LangfusePrompt")
 			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+			deleteProject(ctx, projectNamespacedName)
 		})
 		It("should successfully reconcile the resource", func() {
-			Skip("Requires Langfuse API - add mocking for unit tests")
 			By("Reconciling the created resource")
 			controllerReconciler := &LangfusePromptReconciler{
-				Client: k8sClient,
-				Scheme: k8sClient.Scheme(),
+				Client:         k8sClient,
+				Scheme:         k8sClient.Scheme(),
+				LangfuseClient: lfClient,
 			}
 
 			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
 				NamespacedName: typeNamespacedName,
 			})
 			Expect(err).NotTo(HaveOccurred())
-			// TODO(user): Add more specific assertions depending on your controller's reconciliation logic.
-			// Example: If you expect a certain status condition after reconciliation, verify it here.
+
+			By("Checking the prompt was created in Langfuse")
+			versions := lfClient.PromptVersions(projectID, "test-prompt")
+			Expect(versions).To(HaveLen(1))
+			Expect(versions[0].Version).To(Equal(1))
+			Expect(versions[0].Prompt).To(Equal("Summarise {{text}}"))
+			Expect(versions[0].Labels).To(ConsistOf("production"))
+
+			resource := &langfusev1alpha1.LangfusePrompt{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.Conditions).To(HaveLen(1))
+			Expect(resource.Status.Conditions[0].Type).To(Equal("Available"))
+			Expect(resource.Status.Conditions[0].Status).To(Equal(metav1.ConditionTrue))
+
+			By("Reconciling again after a lost status update")
+			resource.Status.Conditions = nil
+			Expect(k8sClient.Status().Update(ctx, resource)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lfClient.PromptVersions(projectID, "test-prompt")).To(HaveLen(1))
+		})
+
+		It("should requeue while the project is not ready", func() {
+			project := &langfusev1alpha1.LangfuseProject{}
+			Expect(k8sClient.Get(ctx, projectNamespacedName, project)).To(Succeed())
+			project.Status.ID = ""
+			Expect(k8sClient.Status().Update(ctx, project)).To(Succeed())
+
+			controllerReconciler := &LangfusePromptReconciler{
+				Client:         k8sClient,
+				Scheme:         k8sClient.Scheme(),
+				LangfuseClient: lfClient,
+			}
+			result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Requeue).To(BeTrue())
+			Expect(lfClient.Calls("CreatePrompt")).To(BeZero())
 		})
 	})
 })
@@ -27,15 +27,18 @@ import (
 
 	langfusev1alpha1 "github.com/sqaisar/langfuse-controller/api/v1alpha1"
 	"github.com/sqaisar/langfuse-controller/internal/langfuse"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
 )
 
 // LangfuseScoreConfigReconciler reconciles a LangfuseScoreConfig object
 type LangfuseScoreConfigReconciler struct {
 	client.Client
 	Scheme         *runtime.Scheme
-	LangfuseClient *langfuse.Client
+	LangfuseClient langfuse.LangfuseAPI
+	// Connections, if set, provides the clients of resources managed in
+	// a LangfuseConnection. Other resources use LangfuseClient.
+	Connections *Connections
 }
 
 // +kubebuilder:rbac:groups=langfuse.io,resources=langfusescoreconfigs,verbs=get;list;watch;create;update;patch;delete
@@ -59,10 +62,6 @@ func (r *LangfuseScoreConfigReconciler) Reconcile(ctx context.Context, req ctrl.
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
-	if len(config.Status.Conditions) > 0 && config.Status.Conditions[0].Status == metav1.ConditionTrue {
-		return ctrl.Result{}, nil
-	}
-
 	var project langfusev1alpha1.LangfuseProject
 	if err := r.Get(ctx, types.NamespacedName{Name: config.Spec.ProjectRef, Namespace: req.Namespace}, &project); err != nil {
 		log.Error(err, "Failed to get Project")
@@ -73,32 +72,129 @@ func (r *LangfuseScoreConfigReconciler) Reconcile(ctx context.Context, req ctrl.
 		return ctrl.Result{Requeue: true}, nil
 	}
 
-	log.Info("Creating Score Config", "name", config.Spec.Name)
-	if err := r.LangfuseClient.CreateScoreConfig(project.Status.ID, map[string]interface{}{
-		"name":       config.Spec.Name,
-		"dataType":   config.Spec.DataType,
-		"minValue":   config.Spec.MinValue,
-		"maxValue":   config.Spec.MaxValue,
-		"categories": config.Spec.Categories,
-	}); err != nil {
-		log.Error(err, "Failed to create Score Config")
-		return ctrl.Result{}, err
+	lf, _, ok, err := requireConnection(ctx, r.Client, r.Connections, r.LangfuseClient, project.Namespace,
+		project.Spec.ConnectionRef, project.Status.Connection, &config, &config.Status.Conditions)
+	if !ok || err != nil {
+		return ctrl.Result{RequeueAfter: connectionRequeueAfter}, err
+	}
+	if ok, err := requireLangfuse(ctx, r.Client, lf, &config, &config.Status.Conditions); !ok || err != nil {
+		return ctrl.Result{RequeueAfter: unavailableRequeueAfter}, err
+	}
+	if ok, err := requireCapability(ctx, r.Client, lf, langfuse.CapabilityScoreConfigs, &config,
+		&config.Status.Conditions); !ok || err != nil {
+		return ctrl.Result{RequeueAfter: unsupportedRequeueAfter}, err
 	}
 
-	config.Status.Conditions = []metav1.Condition{{
-		Type:               "Available",
-		Status:             metav1.ConditionTrue,
-		Reason:             "Created",
-		Message:            "Score Config created",
-		LastTransitionTime: metav1.Now(),
-	}}
-	if err := r.Status().Update(ctx, &config); err != nil {
-		return ctrl.Result{}, err
+	lfConfig := scoreConfigFromSpec(config.Spec)
+	lfConfig.Description = ownershipMarker("LangfuseScoreConfig", &config)
+	existing, err := r.currentScoreConfig(ctx, lf, project.Status.ID, &config, lfConfig)
+	if err != nil {
+		log.Error(err, "Failed to look up Score Config")
+		return langfuseError(ctx, r.Client, &config, &config.Status.Conditions, err)
+	}
+	id := ""
+	switch {
+	case existing == nil:
+		log.Info("Creating Score Config", "name", config.Spec.Name)
+		created, err := lf.CreateScoreConfig(ctx, project.Status.ID, lfConfig)
+		if err != nil {
+			log.Error(err, "Failed to create Score Config")
+			return langfuseError(ctx, r.Client, &config, &config.Status.Conditions, err)
+		}
+		id = created.ID
+	case scoreConfigDrifted(existing, lfConfig):
+		log.Info("Updating Score Config", "name", config.Spec.Name, "id", existing.ID)
+		if _, err := lf.UpdateScoreConfig(ctx, project.Status.ID, existing.ID, langfuse.UpdateScoreConfigRequest{
+			Name:        lfConfig.Name,
+			Description: lfConfig.Description,
+			MinValue:    lfConfig.MinValue,
+			MaxValue:    lfConfig.MaxValue,
+			Categories:  lfConfig.Categories,
+			IsArchived:  ptr.To(false),
+		}); err != nil {
+			log.Error(err, "Failed to update Score Config")
+			return langfuseError(ctx, r.Client, &config, &config.Status.Conditions, err)
+		}
+		id = existing.ID
+	default:
+		id = existing.ID
+	}
+
+	changed := config.Status.ID != id
+	config.Status.ID = id
+	if setAvailable(&config.Status.Conditions, config.Generation, "Created", "Score Config created") || changed {
+		if err := r.Status().Update(ctx, &config); err != nil {
+			return ctrl.Result{}, err
+		}
 	}
 
 	return ctrl.Result{}, nil
 }
 
+// scoreConfigFromSpec converts the CR spec into a Langfuse score config.
+// Categories are assigned their index as value.
+func scoreConfigFromSpec(spec langfusev1alpha1.LangfuseScoreConfigSpec) langfuse.CreateScoreConfigRequest {
+	sc := langfuse.CreateScoreConfigRequest{
+		Name:     spec.Name,
+		DataType: spec.DataType,
+	}
+	if spec.MinValue != nil {
+		v := float64(*spec.MinValue)
+		sc.MinValue = &v
+	}
+	if spec.MaxValue != nil {
+		v := float64(*spec.MaxValue)
+		sc.MaxValue = &v
+	}
+	for i, label := range spec.Categories {
+		sc.Categories = append(sc.Categories, langfuse.ScoreConfigCategory{Label: label, Value: float64(i)})
+	}
+	return sc
+}
+
+// currentScoreConfig returns the score config of config: the one with the
+// ID in its status, or else the one findScoreConfig finds. It returns nil
+// if there is none, e.g. because it was deleted in Langfuse.
+func (r *LangfuseScoreConfigReconciler) currentScoreConfig(ctx context.Context, lf langfuse.LangfuseAPI,
+	projectID string, config *langfusev1alpha1.LangfuseScoreConfig,
+	want langfuse.CreateScoreConfigRequest) (*langfuse.ScoreConfig, error) {
+	if config.Status.ID == "" {
+		return r.findScoreConfig(ctx, lf, projectID, want)
+	}
+	sc, err := lf.GetScoreConfig(ctx, projectID, config.Status.ID)
+	if langfuse.IsNotFound(err) {
+		return nil, nil
+	}
+	return sc, err
+}
+
+// scoreConfigDrifted reports whether sc differs from want in the fields
+// want sets, which are the ones an update can change. Archived score
+// configs are restored.
+func scoreConfigDrifted(sc *langfuse.ScoreConfig, want langfuse.CreateScoreConfigRequest) bool {
+	return sc.IsArchived || sc.Name != want.Name || sc.Description != want.Description ||
+		want.MinValue != nil && !samePrice(sc.MinValue, want.MinValue) ||
+		want.MaxValue != nil && !samePrice(sc.MaxValue, want.MaxValue) ||
+		want.Categories != nil && !sameJSON(sc.Categories, want.Categories)
+}
+
+// findScoreConfig returns the active score config this controller created
+// for want, identified by name and the ownership marker in its description,
+// or nil.
+func (r *LangfuseScoreConfigReconciler) findScoreConfig(ctx context.Context, lf langfuse.LangfuseAPI,
+	projectID string, want langfuse.CreateScoreConfigRequest) (*langfuse.ScoreConfig, error) {
+	for sc, err := range lf.ListScoreConfigs(ctx, projectID, langfuse.ListOptions{}) {
+		if err != nil {
+			return nil, err
+		}
+		if sc.Name == want.Name && sc.Description == want.Description && sc.DataType == want.DataType &&
+			!sc.IsArchived {
+			return &sc, nil
+		}
+	}
+	return nil, nil
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *LangfuseScoreConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
@@ -107,5 +203,5 @@ func (r *LangfuseScoreConfigReconciler) SetupWithManager(mgr ctrl.Manager) error
 			MaxConcurrentReconciles: 1, // avoids event storms
 		}).
 		Named("langfusescoreconfig").
-		Complete(r)
+		Complete(traced("LangfuseScoreConfig", r))
 }
@@ -23,11 +23,14 @@ import (
 	. "github.com/onsi/gomega"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	langfusev1alpha1 "github.com/sqaisar/langfuse-controller/api/v1alpha1"
+	"github.com/sqaisar/langfuse-controller/internal/langfuse"
+	"github.com/sqaisar/langfuse-controller/internal/langfuse/fake"
 )
 
 var _ = Describe("LangfuseScoreConfig Controller", func() {
@@ -40,46 +43,131 @@ var _ = Describe("LangfuseScoreConfig Controller", func() {
 			Name:      resourceName,
 			Namespace: "default", // TODO(user):Modify as needed
 		}
+		projectNamespacedName := types.NamespacedName{
+			Name:      "langfusescoreconfig-project",
+			Namespace: "default",
+		}
 		langfusescoreconfig := &langfusev1alpha1.LangfuseScoreConfig{}
 
+		var lfClient *fake.Client
+		var projectID string
+
 		BeforeEach(func() {
+			By("creating the Langfuse project and its LangfuseProject")
+			lfClient = fake.NewClient()
+			lfProject, err := lfClient.CreateProject(ctx, langfuse.CreateProjectRequest{Name: "LangfuseScoreConfig Test Project"})
+			Expect(err).NotTo(HaveOccurred())
+			projectID = lfProject.ID
+			createReadyProject(ctx, projectNamespacedName, projectID)
+
 			By("creating the custom resource for the Kind LangfuseScoreConfig")
-			err := k8sClient.Get(ctx, typeNamespacedName, langfusescoreconfig)
+			err = k8sClient.Get(ctx, typeNamespacedName, langfusescoreconfig)
 			if err != nil && errors.IsNotFound(err) {
 				resource := &langfusev1alpha1.LangfuseScoreConfig{
 					ObjectMeta: metav1.ObjectMeta{
 						Name:      resourceName,
 						Namespace: "default",
 					},
-					// TODO(user): Specify other spec details if needed.
+					Spec: langfusev1alpha1.LangfuseScoreConfigSpec{
+						ProjectRef: projectNamespacedName.Name,
+						Name:       "correctness",
+						DataType:   "CATEGORICAL",
+						Categories: []string{"wrong", "right"},
+					},
 				}
 				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
 			}
 		})
 
 		AfterEach(func() {
-			// TODO(user): Cleanup logic after each test, like removing the resource instance.
 			resource := &langfusev1alpha1.LangfuseScoreConfig{}
 			err := k8sClient.Get(ctx, typeNamespacedName, resource)
 			Expect(err).NotTo(HaveOccurred())
 
 			By("Cleanup the specific resource instance LangfuseScoreConfig")
 			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+			deleteProject(ctx, projectNamespacedName)
 		})
 		It("should successfully reconcile the resource", func() {
-			Skip("Requires Langfuse API - add mocking for unit tests")
 			By("Reconciling the created resource")
 			controllerReconciler := &LangfuseScoreConfigReconciler{
-				Client: k8sClient,
-				Scheme: k8sClient.Scheme(),
+				Client:         k8sClient,
+				Scheme:         k8sClient.Scheme(),
+				LangfuseClient: lfClient,
 			}
 
 			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
 				NamespacedName: typeNamespacedName,
 			})
 			Expect(err).NotTo(HaveOccurred())
-			// TODO(user): Add more specific assertions depending on your controller's reconciliation logic.
-			// Example: If you expect a certain status condition after reconciliation, verify it here.
+
+			By("Checking the score config was created in Langfuse")
+			configs := lfClient.ScoreConfigs(projectID)
+			Expect(configs).To(HaveLen(1))
+			Expect(configs[0].Name).To(Equal("correctness"))
+			Expect(configs[0].Categories).To(HaveLen(2))
+			Expect(configs[0].Categories[1].Label).To(Equal("right"))
+
+			resource := &langfusev1alpha1.LangfuseScoreConfig{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.Conditions).To(HaveLen(1))
+			Expect(resource.Status.Conditions[0].Type).To(Equal("Available"))
+			Expect(resource.Status.Conditions[0].Status).To(Equal(metav1.ConditionTrue))
+
+			Expect(resource.Status.ID).To(Equal(configs[0].ID))
+
+			By("Reconciling again after a lost status update")
+			resource.Status = langfusev1alpha1.LangfuseScoreConfigStatus{}
+			Expect(k8sClient.Status().Update(ctx, resource)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lfClient.ScoreConfigs(projectID)).To(HaveLen(1))
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.ID).To(Equal(configs[0].ID))
+
+			By("Restoring a score config archived in Langfuse")
+			_, err = lfClient.UpdateScoreConfig(ctx, projectID, configs[0].ID,
+				langfuse.UpdateScoreConfigRequest{IsArchived: ptr.To(true)})
+			Expect(err).NotTo(HaveOccurred())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lfClient.ScoreConfigs(projectID)[0].IsArchived).To(BeFalse())
+
+			By("Updating the score config after a spec change")
+			resource.Spec.Categories = []string{"wrong", "partly", "right"}
+			Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			configs = lfClient.ScoreConfigs(projectID)
+			Expect(configs).To(HaveLen(1))
+			Expect(configs[0].Categories).To(HaveLen(3))
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.Conditions[0].ObservedGeneration).To(Equal(resource.Generation))
+		})
+
+		It("should requeue while the project is not ready", func() {
+			project := &langfusev1alpha1.LangfuseProject{}
+			Expect(k8sClient.Get(ctx, projectNamespacedName, project)).To(Succeed())
+			project.Status.ID = ""
+			Expect(k8sClient.Status().Update(ctx, project)).To(Succeed())
+
+			controllerReconciler := &LangfuseScoreConfigReconciler{
+				Client:         k8sClient,
+				Scheme:         k8sClient.Scheme(),
+				LangfuseClient: lfClient,
+			}
+			result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Requeue).To(BeTrue())
+			Expect(lfClient.Calls("CreateScoreConfig")).To(BeZero())
 		})
 	})
 })
@@ -0,0 +1,318 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	langfusev1alpha1 "github.com/sqaisar/langfuse-controller/api/v1alpha1"
+)
+
+const (
+	// ConditionAvailable reports whether the web Deployment of a
+	// LangfuseServer has its minimum of available replicas.
+	ConditionAvailable = "Available"
+	// ConditionProgressing is True while a LangfuseServer's version or
+	// configuration is rolled out.
+	ConditionProgressing = "Progressing"
+
+	ReasonMinimumReplicasAvailable = "MinimumReplicasAvailable"
+	ReasonMinimumReplicasMissing   = "MinimumReplicasUnavailable"
+	ReasonRollingOutWeb            = "RollingOutWeb"
+	ReasonRollingOutWorker         = "RollingOutWorker"
+	ReasonRolledOut                = "RolledOut"
+)
+
+// LangfuseServerReconciler reconciles a LangfuseServer object
+type LangfuseServerReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=langfuse.io,resources=langfuseservers,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=langfuse.io,resources=langfuseservers/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=langfuse.io,resources=langfuseservers/finalizers,verbs=update
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=services;configmaps,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
+
+// Reconcile renders the ConfigMap, Services and Deployments of a
+// LangfuseServer. The web Deployment is rolled out first on a version
+// change, because it runs the database migrations on startup, and the
+// worker is only moved to the new version once every web replica runs it
+// and is available. The endpoint of the web Service is published in
+// status.url for LangfuseConnections with a serverRef.
+func (r *LangfuseServerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	var server langfusev1alpha1.LangfuseServer
+	if err := r.Get(ctx, req.NamespacedName, &server); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !server.DeletionTimestamp.IsZero() {
+		// The owned objects are garbage collected.
+		return ctrl.Result{}, nil
+	}
+	status := server.Status.DeepCopy()
+
+	if err := r.ensureServerSecret(ctx, &server); err != nil {
+		log.Error(err, "Failed to create the generated LangfuseServer Secret")
+		return ctrl.Result{}, err
+	}
+	config := serverConfig(&server)
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name: server.Name + serverConfigSuffix, Namespace: server.Namespace,
+	}}
+	if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, configMap, func() error {
+		configMap.Labels = serverLabels(&server, "")
+		configMap.Data = config
+		return ctrl.SetControllerReference(&server, configMap, r.Scheme)
+	}); err != nil {
+		log.Error(err, "Failed to reconcile the LangfuseServer ConfigMap")
+		return ctrl.Result{}, err
+	}
+	configHash, err := r.configHash(ctx, &server, config)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	for _, component := range []string{serverComponentWeb, serverComponentWorker} {
+		svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{
+			Name: serverComponentName(&server, component), Namespace: server.Namespace,
+		}}
+		if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, svc, func() error {
+			mutateServerService(&server, component, svc)
+			return ctrl.SetControllerReference(&server, svc, r.Scheme)
+		}); err != nil {
+			log.Error(err, "Failed to reconcile the LangfuseServer Service", "component", component)
+			return ctrl.Result{}, err
+		}
+	}
+	status.URL = serverURL(&server)
+
+	version := server.Spec.Version
+	web, err := r.ensureDeployment(ctx, &server, serverComponentWeb, version, configHash)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	webRolledOut := deploymentRolledOut(web, version)
+
+	// The worker follows the web once it is rolled out. Until then it keeps
+	// its version, and it is not created before the web is available.
+	worker := &appsv1.Deployment{}
+	err = r.Get(ctx, types.NamespacedName{
+		Name: serverComponentName(&server, serverComponentWorker), Namespace: server.Namespace,
+	}, worker)
+	if client.IgnoreNotFound(err) != nil {
+		return ctrl.Result{}, err
+	}
+	workerVersion := version
+	if !webRolledOut {
+		workerVersion = ""
+		if err == nil {
+			workerVersion = worker.Annotations[AnnotationServerVersion]
+		}
+	}
+	workerRolledOut := false
+	if workerVersion != "" {
+		if workerVersion != version {
+			log.Info("Waiting for the web to be rolled out before upgrading the worker",
+				"version", version, "workerVersion", workerVersion)
+		}
+		worker, err = r.ensureDeployment(ctx, &server, serverComponentWorker, workerVersion, configHash)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		workerRolledOut = deploymentRolledOut(worker, version)
+	}
+
+	setServerConditions(&server, status, web, webRolledOut, workerRolledOut)
+	if webRolledOut && workerRolledOut && status.Version != version {
+		log.Info("Langfuse is rolled out", "version", version)
+		status.Version = version
+	}
+	if !equality.Semantic.DeepEqual(status, &server.Status) {
+		server.Status = *status
+		if err := r.Status().Update(ctx, &server); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	return ctrl.Result{}, nil
+}
+
+// ensureServerSecret creates the Secret with the keys generated for
+// server. It is not owned by the LangfuseServer, since data encrypted with
+// its keys outlives the Deployments, and is never updated.
+func (r *LangfuseServerReconciler) ensureServerSecret(ctx context.Context,
+	server *langfusev1alpha1.LangfuseServer) error {
+	key := types.NamespacedName{Name: server.Name + serverSecretSuffix, Namespace: server.Namespace}
+	err := r.Get(ctx, key, &corev1.Secret{})
+	if client.IgnoreNotFound(err) != nil || err == nil {
+		return err
+	}
+	data, err := generateServerSecret()
+	if err != nil {
+		return err
+	}
+	logf.FromContext(ctx).Info("Creating the generated LangfuseServer Secret", "secret", key.Name)
+	return r.Create(ctx, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      key.Name,
+			Namespace: key.Namespace,
+			Labels:    serverLabels(server, ""),
+		},
+		Data: data,
+	})
+}
+
+// configHash returns the hash of config and of the Secrets server's
+// environment refers to, so that rotating a password restarts the pods.
+// Secrets that do not exist yet are hashed as missing.
+func (r *LangfuseServerReconciler) configHash(ctx context.Context, server *langfusev1alpha1.LangfuseServer,
+	config map[string]string) (string, error) {
+	var versions []string
+	for _, name := range serverSecretNames(server) {
+		secret := &corev1.Secret{}
+		err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: server.Namespace}, secret)
+		if client.IgnoreNotFound(err) != nil {
+			return "", err
+		}
+		versions = append(versions, name+"/"+secret.ResourceVersion)
+	}
+	return serverConfigHash(config, versions), nil
+}
+
+// ensureDeployment creates or updates the Deployment of a component of
+// server running version.
+func (r *LangfuseServerReconciler) ensureDeployment(ctx context.Context, server *langfusev1alpha1.LangfuseServer,
+	component, version, configHash string) (*appsv1.Deployment, error) {
+	dep := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+		Name: serverComponentName(server, component), Namespace: server.Namespace,
+	}}
+	result, err := controllerutil.CreateOrUpdate(ctx, r.Client, dep, func() error {
+		mutateServerDeployment(server, component, version, configHash, dep)
+		return ctrl.SetControllerReference(server, dep, r.Scheme)
+	})
+	if err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to reconcile the LangfuseServer Deployment", "component", component)
+		return nil, err
+	}
+	if result != controllerutil.OperationResultNone {
+		logf.FromContext(ctx).Info("Rolling out the LangfuseServer Deployment", "component", component,
+			"version", version, "operation", result)
+	}
+	return dep, nil
+}
+
+// setServerConditions sets the Available and Progressing conditions of
+// server in status.
+func setServerConditions(server *langfusev1alpha1.LangfuseServer, status *langfusev1alpha1.LangfuseServerStatus,
+	web *appsv1.Deployment, webRolledOut, workerRolledOut bool) {
+	available := metav1.Condition{
+		Type:               ConditionAvailable,
+		Status:             metav1.ConditionFalse,
+		Reason:             ReasonMinimumReplicasMissing,
+		Message:            "The web Deployment does not have its minimum of available replicas",
+		ObservedGeneration: server.Generation,
+	}
+	if cond := deploymentCondition(web, appsv1.DeploymentAvailable); cond != nil &&
+		cond.Status == corev1.ConditionTrue {
+		available.Status, available.Reason = metav1.ConditionTrue, ReasonMinimumReplicasAvailable
+		available.Message = fmt.Sprintf("%d of %d web replicas are available", web.Status.AvailableReplicas,
+			web.Status.Replicas)
+	}
+	meta.SetStatusCondition(&status.Conditions, available)
+
+	progressing := metav1.Condition{
+		Type:               ConditionProgressing,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: server.Generation,
+	}
+	version := server.Spec.Version
+	switch {
+	case !webRolledOut:
+		progressing.Reason = ReasonRollingOutWeb
+		progressing.Message = fmt.Sprintf("Rolling out Langfuse %s to the web, %d of %d replicas updated; "+
+			"the worker follows once the web is available", version, web.Status.UpdatedReplicas, web.Status.Replicas)
+	case !workerRolledOut:
+		progressing.Reason = ReasonRollingOutWorker
+		progressing.Message = fmt.Sprintf("Rolling out Langfuse %s to the worker", version)
+	default:
+		progressing.Status, progressing.Reason = metav1.ConditionFalse, ReasonRolledOut
+		progressing.Message = fmt.Sprintf("Langfuse %s is rolled out", version)
+	}
+	meta.SetStatusCondition(&status.Conditions, progressing)
+}
+
+// deploymentCondition returns the condition of dep of type t, or nil.
+func deploymentCondition(dep *appsv1.Deployment, t appsv1.DeploymentConditionType) *appsv1.DeploymentCondition {
+	for i := range dep.Status.Conditions {
+		if dep.Status.Conditions[i].Type == t {
+			return &dep.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// serversForSecret maps a Secret to the LangfuseServers whose environment
+// refers to it.
+func (r *LangfuseServerReconciler) serversForSecret(ctx context.Context, obj client.Object) []reconcile.Request {
+	var servers langfusev1alpha1.LangfuseServerList
+	if err := r.List(ctx, &servers, client.InNamespace(obj.GetNamespace())); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to list LangfuseServers")
+		return nil
+	}
+	var requests []reconcile.Request
+	for i := range servers.Items {
+		if slices.Contains(serverSecretNames(&servers.Items[i]), obj.GetName()) {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&servers.Items[i])})
+		}
+	}
+	return requests
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *LangfuseServerReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&langfusev1alpha1.LangfuseServer{}).
+		Owns(&appsv1.Deployment{}).
+		Owns(&corev1.Service{}).
+		Owns(&corev1.ConfigMap{}).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.serversForSecret)).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: 1, // avoids event storms
+		}).
+		Named("langfuseserver").
+		Complete(traced("LangfuseServer", r))
+}
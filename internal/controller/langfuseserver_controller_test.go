@@ -0,0 +1,214 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	langfusev1alpha1 "github.com/sqaisar/langfuse-controller/api/v1alpha1"
+	"github.com/sqaisar/langfuse-controller/pkg/langfusetest"
+)
+
+var _ = Describe("LangfuseServer Controller", func() {
+	ctx := context.Background()
+	serverKey := types.NamespacedName{Name: "langfuse", Namespace: "default"}
+	webKey := types.NamespacedName{Name: "langfuse-web", Namespace: "default"}
+	workerKey := types.NamespacedName{Name: "langfuse-worker", Namespace: "default"}
+
+	BeforeEach(func() {
+		secretRef := func(name, key string) corev1.SecretKeySelector {
+			return corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: name}, Key: key}
+		}
+		Expect(k8sClient.Create(ctx, &langfusev1alpha1.LangfuseServer{
+			ObjectMeta: metav1.ObjectMeta{Name: serverKey.Name, Namespace: serverKey.Namespace},
+			Spec: langfusev1alpha1.LangfuseServerSpec{
+				Version:  "3.1.0",
+				Database: langfusev1alpha1.PostgresReference{URLSecretRef: secretRef("postgres", "url")},
+				ClickHouse: langfusev1alpha1.ClickHouseReference{
+					URL:               "http://clickhouse:8123",
+					MigrationURL:      "clickhouse://clickhouse:9000",
+					PasswordSecretRef: secretRef("clickhouse", "password"),
+				},
+				Redis: langfusev1alpha1.RedisReference{ConnectionStringSecretRef: secretRef("redis", "url")},
+				S3:    langfusev1alpha1.S3Reference{Bucket: "langfuse", Endpoint: "http://minio:9000"},
+			},
+		})).To(Succeed())
+	})
+
+	AfterEach(func() {
+		// envtest runs no garbage collector, so owned objects are deleted
+		// here.
+		for _, obj := range []struct {
+			key types.NamespacedName
+			obj client.Object
+		}{
+			{serverKey, &langfusev1alpha1.LangfuseServer{}},
+			{webKey, &appsv1.Deployment{}},
+			{workerKey, &appsv1.Deployment{}},
+			{webKey, &corev1.Service{}},
+			{workerKey, &corev1.Service{}},
+			{types.NamespacedName{Name: "langfuse-config", Namespace: "default"}, &corev1.ConfigMap{}},
+			{types.NamespacedName{Name: "langfuse-secrets", Namespace: "default"}, &corev1.Secret{}},
+		} {
+			if err := k8sClient.Get(ctx, obj.key, obj.obj); err == nil {
+				Expect(k8sClient.Delete(ctx, obj.obj)).To(Succeed())
+			}
+		}
+	})
+
+	reconcileServer := func() *langfusev1alpha1.LangfuseServer {
+		reconciler := &LangfuseServerReconciler{Client: k8sClient, Scheme: k8sClient.Scheme()}
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: serverKey})
+		Expect(err).NotTo(HaveOccurred())
+		server := &langfusev1alpha1.LangfuseServer{}
+		Expect(k8sClient.Get(ctx, serverKey, server)).To(Succeed())
+		return server
+	}
+
+	// rollOut plays the Deployment controller, which envtest does not run,
+	// and marks every replica of the Deployment as updated and available.
+	rollOut := func(key types.NamespacedName) {
+		dep := &appsv1.Deployment{}
+		Expect(k8sClient.Get(ctx, key, dep)).To(Succeed())
+		dep.Status = appsv1.DeploymentStatus{
+			ObservedGeneration: dep.Generation,
+			Replicas:           *dep.Spec.Replicas,
+			UpdatedReplicas:    *dep.Spec.Replicas,
+			ReadyReplicas:      *dep.Spec.Replicas,
+			AvailableReplicas:  *dep.Spec.Replicas,
+			Conditions: []appsv1.DeploymentCondition{{
+				Type:   appsv1.DeploymentAvailable,
+				Status: corev1.ConditionTrue,
+				Reason: "MinimumReplicasAvailable",
+			}},
+		}
+		Expect(k8sClient.Status().Update(ctx, dep)).To(Succeed())
+	}
+
+	image := func(key types.NamespacedName) string {
+		dep := &appsv1.Deployment{}
+		Expect(k8sClient.Get(ctx, key, dep)).To(Succeed())
+		return dep.Spec.Template.Spec.Containers[0].Image
+	}
+
+	It("should deploy the web before the worker", func() {
+		server := reconcileServer()
+		Expect(image(webKey)).To(Equal(DefaultServerWebImage + ":3.1.0"))
+		Expect(k8sClient.Get(ctx, workerKey, &appsv1.Deployment{})).To(Satisfy(apierrors.IsNotFound))
+		Expect(server.Status.URL).To(Equal("http://langfuse-web.default.svc:3000"))
+		Expect(meta.FindStatusCondition(server.Status.Conditions, ConditionProgressing).Reason).
+			To(Equal(ReasonRollingOutWeb))
+
+		By("rendering the configuration and generating the keys")
+		configMap := &corev1.ConfigMap{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "langfuse-config", Namespace: "default"},
+			configMap)).To(Succeed())
+		Expect(configMap.Data).To(HaveKeyWithValue("NEXTAUTH_URL", server.Status.URL))
+		Expect(configMap.Data).To(HaveKeyWithValue("LANGFUSE_S3_EVENT_UPLOAD_ENDPOINT", "http://minio:9000"))
+		secret := &corev1.Secret{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "langfuse-secrets", Namespace: "default"},
+			secret)).To(Succeed())
+		Expect(secret.Data["ENCRYPTION_KEY"]).To(HaveLen(64))
+
+		By("deploying the worker once the web is available")
+		rollOut(webKey)
+		reconcileServer()
+		Expect(image(workerKey)).To(Equal(DefaultServerWorkerImage + ":3.1.0"))
+
+		rollOut(workerKey)
+		server = reconcileServer()
+		Expect(server.Status.Version).To(Equal("3.1.0"))
+		Expect(meta.IsStatusConditionTrue(server.Status.Conditions, ConditionAvailable)).To(BeTrue())
+		Expect(meta.IsStatusConditionFalse(server.Status.Conditions, ConditionProgressing)).To(BeTrue())
+	})
+
+	It("should upgrade the worker only after the web", func() {
+		reconcileServer()
+		rollOut(webKey)
+		reconcileServer()
+		rollOut(workerKey)
+		reconcileServer()
+
+		server := &langfusev1alpha1.LangfuseServer{}
+		Expect(k8sClient.Get(ctx, serverKey, server)).To(Succeed())
+		server.Spec.Version = "3.2.0"
+		Expect(k8sClient.Update(ctx, server)).To(Succeed())
+
+		server = reconcileServer()
+		Expect(image(webKey)).To(Equal(DefaultServerWebImage + ":3.2.0"))
+		Expect(image(workerKey)).To(Equal(DefaultServerWorkerImage + ":3.1.0"))
+		Expect(server.Status.Version).To(Equal("3.1.0"))
+
+		rollOut(webKey)
+		server = reconcileServer()
+		Expect(image(workerKey)).To(Equal(DefaultServerWorkerImage + ":3.2.0"))
+		Expect(meta.FindStatusCondition(server.Status.Conditions, ConditionProgressing).Reason).
+			To(Equal(ReasonRollingOutWorker))
+
+		rollOut(workerKey)
+		server = reconcileServer()
+		Expect(server.Status.Version).To(Equal("3.2.0"))
+	})
+
+	It("should publish its endpoint to connections with a serverRef", func() {
+		lfServer := langfusetest.NewServer()
+		defer lfServer.Close()
+		server := &langfusev1alpha1.LangfuseServer{}
+		Expect(k8sClient.Get(ctx, serverKey, server)).To(Succeed())
+		server.Status.URL = lfServer.URL
+		Expect(k8sClient.Status().Update(ctx, server)).To(Succeed())
+
+		secretKey := types.NamespacedName{Name: "self-hosted-credentials", Namespace: "default"}
+		Expect(k8sClient.Create(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: secretKey.Name, Namespace: secretKey.Namespace},
+			StringData: map[string]string{
+				"LANGFUSE_PUBLIC_KEY": lfServer.PublicKey,
+				"LANGFUSE_SECRET_KEY": lfServer.SecretKey,
+			},
+		})).To(Succeed())
+		conn := &langfusev1alpha1.LangfuseConnection{
+			ObjectMeta: metav1.ObjectMeta{Name: "self-hosted", Namespace: "default"},
+			Spec: langfusev1alpha1.LangfuseConnectionSpec{
+				ServerRef:            &corev1.LocalObjectReference{Name: serverKey.Name},
+				CredentialsSecretRef: langfusev1alpha1.CredentialsSecretReference{Name: secretKey.Name},
+			},
+		}
+		Expect(k8sClient.Create(ctx, conn)).To(Succeed())
+		defer func() {
+			Expect(k8sClient.Delete(ctx, conn)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+				Name: secretKey.Name, Namespace: secretKey.Namespace,
+			}})).To(Succeed())
+		}()
+
+		lf, err := (&Connections{Reader: k8sClient}).Client(ctx, conn.Namespace, conn.Name)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(lf.Host()).To(Equal(lfServer.URL))
+		Expect(lf.Ready(ctx)).To(Succeed())
+	})
+})
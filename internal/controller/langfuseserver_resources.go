@@ -0,0 +1,350 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strconv"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	langfusev1alpha1 "github.com/sqaisar/langfuse-controller/api/v1alpha1"
+)
+
+const (
+	// DefaultServerWebImage is the image repository of the web Deployment of
+	// a LangfuseServer.
+	DefaultServerWebImage = "docker.io/langfuse/langfuse"
+	// DefaultServerWorkerImage is the image repository of the worker
+	// Deployment of a LangfuseServer.
+	DefaultServerWorkerImage = "docker.io/langfuse/langfuse-worker"
+
+	// AnnotationServerVersion records the Langfuse version a Deployment of
+	// a LangfuseServer was last rolled out with.
+	AnnotationServerVersion = "langfuse.io/version"
+	// AnnotationConfigHash on the pod template restarts the pods of a
+	// LangfuseServer when its configuration or one of its Secrets changes.
+	AnnotationConfigHash = "langfuse.io/config-hash"
+
+	serverComponentWeb    = "web"
+	serverComponentWorker = "worker"
+
+	serverWebPort    = 3000
+	serverWorkerPort = 3030
+
+	// serverSecretSuffix names the Secret with the keys the operator
+	// generates for a LangfuseServer.
+	serverSecretSuffix = "-secrets"
+	// serverConfigSuffix names the ConfigMap with the environment of a
+	// LangfuseServer.
+	serverConfigSuffix = "-config"
+)
+
+// serverComponentName is the name of the Deployment and Service of a
+// component of server.
+func serverComponentName(server *langfusev1alpha1.LangfuseServer, component string) string {
+	return server.Name + "-" + component
+}
+
+// serverURL is the in-cluster endpoint of the web Service of server.
+func serverURL(server *langfusev1alpha1.LangfuseServer) string {
+	return fmt.Sprintf("http://%s.%s.svc:%d", serverComponentName(server, serverComponentWeb), server.Namespace,
+		serverWebPort)
+}
+
+// serverSelector returns the labels that select the pods of a component
+// of server.
+func serverSelector(server *langfusev1alpha1.LangfuseServer, component string) map[string]string {
+	labels := map[string]string{
+		"app.kubernetes.io/name":     "langfuse",
+		"app.kubernetes.io/instance": server.Name,
+	}
+	if component != "" {
+		labels["app.kubernetes.io/component"] = component
+	}
+	return labels
+}
+
+// serverLabels returns the labels of the objects of a component of server,
+// or of the objects shared by the components if component is empty.
+func serverLabels(server *langfusev1alpha1.LangfuseServer, component string) map[string]string {
+	labels := serverSelector(server, component)
+	labels["app.kubernetes.io/managed-by"] = "langfuse-controller"
+	return labels
+}
+
+// generateServerSecret returns the keys Langfuse signs sessions and
+// encrypts stored credentials with. They must never change, so they are
+// generated once.
+func generateServerSecret() (map[string][]byte, error) {
+	random := func(n int) ([]byte, error) {
+		b := make([]byte, n)
+		_, err := rand.Read(b)
+		return b, err
+	}
+	salt, err := random(32)
+	if err != nil {
+		return nil, err
+	}
+	nextAuthSecret, err := random(32)
+	if err != nil {
+		return nil, err
+	}
+	encryptionKey, err := random(32)
+	if err != nil {
+		return nil, err
+	}
+	return map[string][]byte{
+		"SALT":            []byte(base64.StdEncoding.EncodeToString(salt)),
+		"NEXTAUTH_SECRET": []byte(base64.StdEncoding.EncodeToString(nextAuthSecret)),
+		// ENCRYPTION_KEY must be 256 bits in hex.
+		"ENCRYPTION_KEY": []byte(hex.EncodeToString(encryptionKey)),
+	}, nil
+}
+
+// serverConfig returns the environment of server that holds no secrets.
+// It is stored in a ConfigMap shared by the web and the worker.
+func serverConfig(server *langfusev1alpha1.LangfuseServer) map[string]string {
+	spec := &server.Spec
+	url := spec.URL
+	if url == "" {
+		url = serverURL(server)
+	}
+	user := spec.ClickHouse.User
+	if user == "" {
+		user = "default"
+	}
+	config := map[string]string{
+		// The web server binds to HOSTNAME, which is the pod name otherwise.
+		"HOSTNAME":                   "0.0.0.0",
+		"NEXTAUTH_URL":               url,
+		"CLICKHOUSE_URL":             spec.ClickHouse.URL,
+		"CLICKHOUSE_MIGRATION_URL":   spec.ClickHouse.MigrationURL,
+		"CLICKHOUSE_USER":            user,
+		"CLICKHOUSE_CLUSTER_ENABLED": strconv.FormatBool(spec.ClickHouse.ClusterEnabled),
+	}
+	region := spec.S3.Region
+	if region == "" {
+		region = "auto"
+	}
+	for upload, prefix := range map[string]string{"EVENT": "events/", "MEDIA": "media/"} {
+		env := "LANGFUSE_S3_" + upload + "_UPLOAD_"
+		config[env+"BUCKET"] = spec.S3.Bucket
+		config[env+"REGION"] = region
+		config[env+"PREFIX"] = prefix
+		config[env+"FORCE_PATH_STYLE"] = strconv.FormatBool(spec.S3.ForcePathStyle)
+		if spec.S3.Endpoint != "" {
+			config[env+"ENDPOINT"] = spec.S3.Endpoint
+		}
+	}
+	return config
+}
+
+// serverSecretEnv returns the environment of server that is read from
+// Secrets, followed by spec.env.
+func serverSecretEnv(server *langfusev1alpha1.LangfuseServer) []corev1.EnvVar {
+	spec := &server.Spec
+	fromSecret := func(name string, selector corev1.SecretKeySelector) corev1.EnvVar {
+		return corev1.EnvVar{Name: name, ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &selector}}
+	}
+	generated := func(key string) corev1.EnvVar {
+		return fromSecret(key, corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: server.Name + serverSecretSuffix},
+			Key:                  key,
+		})
+	}
+	env := []corev1.EnvVar{
+		fromSecret("DATABASE_URL", spec.Database.URLSecretRef),
+		fromSecret("CLICKHOUSE_PASSWORD", spec.ClickHouse.PasswordSecretRef),
+		fromSecret("REDIS_CONNECTION_STRING", spec.Redis.ConnectionStringSecretRef),
+		generated("SALT"),
+		generated("NEXTAUTH_SECRET"),
+		generated("ENCRYPTION_KEY"),
+	}
+	if ref := spec.S3.CredentialsSecretRef; ref != nil {
+		accessKeyIDKey, secretAccessKeyKey := ref.AccessKeyIDKey, ref.SecretAccessKeyKey
+		if accessKeyIDKey == "" {
+			accessKeyIDKey = "AWS_ACCESS_KEY_ID"
+		}
+		if secretAccessKeyKey == "" {
+			secretAccessKeyKey = "AWS_SECRET_ACCESS_KEY"
+		}
+		for _, upload := range []string{"EVENT", "MEDIA"} {
+			env = append(env,
+				fromSecret("LANGFUSE_S3_"+upload+"_UPLOAD_ACCESS_KEY_ID", corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: ref.Name},
+					Key:                  accessKeyIDKey,
+				}),
+				fromSecret("LANGFUSE_S3_"+upload+"_UPLOAD_SECRET_ACCESS_KEY", corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: ref.Name},
+					Key:                  secretAccessKeyKey,
+				}),
+			)
+		}
+	}
+	return append(env, spec.Env...)
+}
+
+// serverSecretNames returns the names of the Secrets the environment of
+// server refers to.
+func serverSecretNames(server *langfusev1alpha1.LangfuseServer) []string {
+	var names []string
+	for _, env := range serverSecretEnv(server) {
+		if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil {
+			names = append(names, env.ValueFrom.SecretKeyRef.Name)
+		}
+	}
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+// serverConfigHash hashes the configuration of server and the versions of
+// its Secrets, given in the order of serverSecretNames.
+func serverConfigHash(config map[string]string, secretVersions []string) string {
+	keys := make([]string, 0, len(config))
+	for key := range config {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	h := sha256.New()
+	for _, key := range keys {
+		fmt.Fprintf(h, "%s=%s\n", key, config[key])
+	}
+	for _, version := range secretVersions {
+		fmt.Fprintf(h, "secret=%s\n", version)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// mutateServerService sets the fields of the Service of a component of
+// server. Fields it does not set keep the values defaulted by the API
+// server.
+func mutateServerService(server *langfusev1alpha1.LangfuseServer, component string, svc *corev1.Service) {
+	port := int32(serverWebPort)
+	if component == serverComponentWorker {
+		port = serverWorkerPort
+	}
+	svc.Labels = serverLabels(server, component)
+	svc.Spec.Selector = serverSelector(server, component)
+	svc.Spec.Ports = []corev1.ServicePort{{
+		Name:       "http",
+		Port:       port,
+		TargetPort: intstr.FromString("http"),
+		Protocol:   corev1.ProtocolTCP,
+	}}
+}
+
+// mutateServerDeployment sets the fields of the Deployment of a component
+// of server running version. Fields it does not set keep the values
+// defaulted by the API server, so an unchanged Deployment is not updated.
+func mutateServerDeployment(server *langfusev1alpha1.LangfuseServer, component, version, configHash string,
+	dep *appsv1.Deployment) {
+	spec := server.Spec.Web
+	image, port := DefaultServerWebImage, int32(serverWebPort)
+	liveness, readiness := "/api/public/health", "/api/public/ready"
+	if component == serverComponentWorker {
+		spec = server.Spec.Worker
+		image, port = DefaultServerWorkerImage, serverWorkerPort
+		liveness, readiness = "/api/health", "/api/health"
+	}
+	if spec.Image != "" {
+		image = spec.Image
+	}
+	replicas := int32(1)
+	if spec.Replicas != nil {
+		replicas = *spec.Replicas
+	}
+
+	dep.Labels = serverLabels(server, component)
+	if dep.Annotations == nil {
+		dep.Annotations = map[string]string{}
+	}
+	dep.Annotations[AnnotationServerVersion] = version
+	dep.Spec.Replicas = &replicas
+	dep.Spec.Selector = &metav1.LabelSelector{MatchLabels: serverSelector(server, component)}
+	dep.Spec.Template.Labels = serverLabels(server, component)
+	if dep.Spec.Template.Annotations == nil {
+		dep.Spec.Template.Annotations = map[string]string{}
+	}
+	dep.Spec.Template.Annotations[AnnotationConfigHash] = configHash
+
+	pod := &dep.Spec.Template.Spec
+	if pod.SecurityContext == nil {
+		pod.SecurityContext = &corev1.PodSecurityContext{}
+	}
+	pod.SecurityContext.SeccompProfile = &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault}
+	if len(pod.Containers) != 1 {
+		pod.Containers = []corev1.Container{{}}
+	}
+	c := &pod.Containers[0]
+	c.Name = component
+	c.Image = image + ":" + version
+	c.Ports = []corev1.ContainerPort{{Name: "http", ContainerPort: port, Protocol: corev1.ProtocolTCP}}
+	c.EnvFrom = []corev1.EnvFromSource{{
+		ConfigMapRef: &corev1.ConfigMapEnvSource{
+			LocalObjectReference: corev1.LocalObjectReference{Name: server.Name + serverConfigSuffix},
+		},
+	}}
+	c.Env = serverSecretEnv(server)
+	c.Resources = spec.Resources
+	c.LivenessProbe = serverProbe(liveness, 30)
+	c.ReadinessProbe = serverProbe(readiness, 10)
+	allowPrivilegeEscalation := false
+	c.SecurityContext = &corev1.SecurityContext{
+		AllowPrivilegeEscalation: &allowPrivilegeEscalation,
+		Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+	}
+}
+
+// serverProbe returns an HTTP probe of path. Every field the API server
+// would default is set, so that comparing it to the stored probe works.
+func serverProbe(path string, initialDelaySeconds int32) *corev1.Probe {
+	return &corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{HTTPGet: &corev1.HTTPGetAction{
+			Path:   path,
+			Port:   intstr.FromString("http"),
+			Scheme: corev1.URISchemeHTTP,
+		}},
+		InitialDelaySeconds: initialDelaySeconds,
+		TimeoutSeconds:      5,
+		PeriodSeconds:       10,
+		SuccessThreshold:    1,
+		FailureThreshold:    3,
+	}
+}
+
+// deploymentRolledOut reports whether every replica of dep runs version
+// and is available.
+func deploymentRolledOut(dep *appsv1.Deployment, version string) bool {
+	if dep.Annotations[AnnotationServerVersion] != version || dep.Status.ObservedGeneration < dep.Generation {
+		return false
+	}
+	replicas := int32(1)
+	if dep.Spec.Replicas != nil {
+		replicas = *dep.Spec.Replicas
+	}
+	return dep.Status.Replicas == replicas && dep.Status.UpdatedReplicas == replicas &&
+		dep.Status.AvailableReplicas == replicas
+}
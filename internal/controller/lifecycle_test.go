@@ -0,0 +1,228 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	langfusev1alpha1 "github.com/sqaisar/langfuse-controller/api/v1alpha1"
+	"github.com/sqaisar/langfuse-controller/internal/langfuse"
+	"github.com/sqaisar/langfuse-controller/pkg/langfusetest"
+)
+
+// These specs run the reconcilers against the fake Langfuse HTTP server
+// through the real client, covering authentication, project keys and
+// retries that the in-memory fake skips.
+var _ = Describe("Reconciling against a Langfuse server", func() {
+	ctx := context.Background()
+	projectKey := types.NamespacedName{Name: "lifecycle-project", Namespace: "default"}
+	promptKey := types.NamespacedName{Name: "lifecycle-prompt", Namespace: "default"}
+	scoreConfigKey := types.NamespacedName{Name: "lifecycle-score-config", Namespace: "default"}
+	apiKeyKey := types.NamespacedName{Name: "lifecycle-api-key", Namespace: "default"}
+	modelKey := types.NamespacedName{Name: "lifecycle-model", Namespace: "default"}
+	llmConnectionKey := types.NamespacedName{Name: "lifecycle-llm-connection", Namespace: "default"}
+	apiKeySecretKey := types.NamespacedName{Name: "lifecycle-api-key-credentials", Namespace: "default"}
+	llmSecretKey := types.NamespacedName{Name: "lifecycle-openai-credentials", Namespace: "default"}
+
+	var server *langfusetest.Server
+	var projectCredentials *ProjectCredentials
+	var lfClient *langfuse.Client
+
+	BeforeEach(func() {
+		server = langfusetest.NewServer()
+		projectCredentials = &ProjectCredentials{Reader: k8sClient}
+		lfClient = langfuse.NewClient(langfuse.Options{
+			BaseURL:            server.URL,
+			PublicKey:          server.PublicKey,
+			SecretKey:          server.SecretKey,
+			RetryWaitMin:       time.Millisecond,
+			RetryWaitMax:       10 * time.Millisecond,
+			ProjectCredentials: projectCredentials,
+		})
+	})
+
+	AfterEach(func() {
+		server.Close()
+		for _, obj := range []struct {
+			key types.NamespacedName
+			obj client.Object
+		}{
+			{promptKey, &langfusev1alpha1.LangfusePrompt{}},
+			{scoreConfigKey, &langfusev1alpha1.LangfuseScoreConfig{}},
+			{apiKeyKey, &langfusev1alpha1.LangfuseAPIKey{}},
+			{modelKey, &langfusev1alpha1.LangfuseModel{}},
+			{llmConnectionKey, &langfusev1alpha1.LangfuseLlmConnection{}},
+			{apiKeySecretKey, &corev1.Secret{}},
+			{llmSecretKey, &corev1.Secret{}},
+			{projectKey, &langfusev1alpha1.LangfuseProject{}},
+		} {
+			if err := k8sClient.Get(ctx, obj.key, obj.obj); err == nil {
+				deleteAndRelease(ctx, obj.obj)
+			}
+		}
+	})
+
+	It("should create a project and its objects with the project's own key", func() {
+		By("reconciling the project")
+		Expect(k8sClient.Create(ctx, &langfusev1alpha1.LangfuseProject{
+			ObjectMeta: metav1.ObjectMeta{Name: projectKey.Name, Namespace: projectKey.Namespace},
+			Spec:       langfusev1alpha1.LangfuseProjectSpec{Name: "Lifecycle Project"},
+		})).To(Succeed())
+		projectReconciler := &LangfuseProjectReconciler{
+			Client:             k8sClient,
+			Scheme:             k8sClient.Scheme(),
+			LangfuseClient:     lfClient,
+			ProjectCredentials: projectCredentials,
+		}
+		_, err := projectReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: projectKey})
+		Expect(err).NotTo(HaveOccurred())
+
+		project := &langfusev1alpha1.LangfuseProject{}
+		Expect(k8sClient.Get(ctx, projectKey, project)).To(Succeed())
+		Expect(project.Status.State).To(Equal("Ready"))
+		Expect(meta.IsStatusConditionTrue(project.Status.Conditions, ConditionAuthenticated)).To(BeTrue())
+		keys := server.APIKeys(project.Status.ID)
+		Expect(keys).To(HaveLen(1))
+
+		By("reconciling a prompt while Langfuse throttles the first attempts")
+		server.InjectFault(langfusetest.Fault{
+			Method: http.MethodPost, Path: "/api/public/v2/prompts", StatusCode: http.StatusTooManyRequests, Times: 2,
+		})
+		Expect(k8sClient.Create(ctx, &langfusev1alpha1.LangfusePrompt{
+			ObjectMeta: metav1.ObjectMeta{Name: promptKey.Name, Namespace: promptKey.Namespace},
+			Spec: langfusev1alpha1.LangfusePromptSpec{
+				ProjectRef: projectKey.Name,
+				Name:       "summarise",
+				Prompt:     "Summarise {{text}}",
+				Type:       "text",
+				Labels:     []string{"production"},
+			},
+		})).To(Succeed())
+		promptReconciler := &LangfusePromptReconciler{
+			Client:         k8sClient,
+			Scheme:         k8sClient.Scheme(),
+			LangfuseClient: lfClient,
+		}
+		_, err = promptReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: promptKey})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(server.PromptVersions(project.Status.ID, "summarise")).To(HaveLen(1))
+		Expect(server.RequestCount(http.MethodPost, "/api/public/v2/prompts")).To(Equal(3))
+
+		By("reconciling a score config")
+		Expect(k8sClient.Create(ctx, &langfusev1alpha1.LangfuseScoreConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: scoreConfigKey.Name, Namespace: scoreConfigKey.Namespace},
+			Spec: langfusev1alpha1.LangfuseScoreConfigSpec{
+				ProjectRef: projectKey.Name,
+				Name:       "correctness",
+				DataType:   "CATEGORICAL",
+				Categories: []string{"wrong", "right"},
+			},
+		})).To(Succeed())
+		scoreConfigReconciler := &LangfuseScoreConfigReconciler{
+			Client:         k8sClient,
+			Scheme:         k8sClient.Scheme(),
+			LangfuseClient: lfClient,
+		}
+		_, err = scoreConfigReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: scoreConfigKey})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(server.ScoreConfigs(project.Status.ID)).To(HaveLen(1))
+
+		By("reconciling an API key")
+		Expect(k8sClient.Create(ctx, &langfusev1alpha1.LangfuseAPIKey{
+			ObjectMeta: metav1.ObjectMeta{Name: apiKeyKey.Name, Namespace: apiKeyKey.Namespace},
+			Spec: langfusev1alpha1.LangfuseAPIKeySpec{
+				ProjectRef: projectKey.Name,
+				Name:       "ci",
+				SecretName: apiKeySecretKey.Name,
+			},
+		})).To(Succeed())
+		apiKeyReconciler := &LangfuseAPIKeyReconciler{
+			Client:         k8sClient,
+			Scheme:         k8sClient.Scheme(),
+			LangfuseClient: lfClient,
+		}
+		_, err = apiKeyReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: apiKeyKey})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(server.APIKeys(project.Status.ID)).To(HaveLen(2))
+		apiKeySecret := &corev1.Secret{}
+		Expect(k8sClient.Get(ctx, apiKeySecretKey, apiKeySecret)).To(Succeed())
+		Expect(string(apiKeySecret.Data["LANGFUSE_HOST"])).To(Equal(server.URL))
+
+		By("reconciling a model")
+		Expect(k8sClient.Create(ctx, &langfusev1alpha1.LangfuseModel{
+			ObjectMeta: metav1.ObjectMeta{Name: modelKey.Name, Namespace: modelKey.Namespace},
+			Spec: langfusev1alpha1.LangfuseModelSpec{
+				ModelName:    "lifecycle-model",
+				MatchPattern: "(?i)^(lifecycle-model)$",
+				Unit:         "TOKENS",
+				InputPrice:   "0.000001",
+			},
+		})).To(Succeed())
+		modelReconciler := &LangfuseModelReconciler{
+			Client:         k8sClient,
+			Scheme:         k8sClient.Scheme(),
+			LangfuseClient: lfClient,
+		}
+		_, err = modelReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: modelKey})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(server.Models()).To(HaveLen(1))
+
+		By("reconciling an LLM connection")
+		Expect(k8sClient.Create(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: llmSecretKey.Name, Namespace: llmSecretKey.Namespace},
+			StringData: map[string]string{"apiKey": "sk-openai"},
+		})).To(Succeed())
+		Expect(k8sClient.Create(ctx, &langfusev1alpha1.LangfuseLlmConnection{
+			ObjectMeta: metav1.ObjectMeta{Name: llmConnectionKey.Name, Namespace: llmConnectionKey.Namespace},
+			Spec: langfusev1alpha1.LangfuseLlmConnectionSpec{
+				ProjectRef: projectKey.Name,
+				Provider:   "openai",
+				SecretRef:  corev1.SecretReference{Name: llmSecretKey.Name},
+			},
+		})).To(Succeed())
+		llmConnectionReconciler := &LangfuseLlmConnectionReconciler{
+			Client:         k8sClient,
+			Scheme:         k8sClient.Scheme(),
+			LangfuseClient: lfClient,
+		}
+		_, err = llmConnectionReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: llmConnectionKey})
+		Expect(err).NotTo(HaveOccurred())
+		conns := server.LlmConnections(project.Status.ID)
+		Expect(conns).To(HaveLen(1))
+		Expect(conns[0].SecretKey).To(Equal("sk-openai"))
+
+		By("checking that project objects were written with the project key")
+		for _, req := range server.Requests() {
+			switch req.Path {
+			case "/api/public/v2/prompts", "/api/public/score-configs", "/api/public/llm-connections":
+				Expect(req.PublicKey).To(Equal(keys[0].PublicKey))
+			}
+		}
+	})
+})
@@ -0,0 +1,82 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Langfuse objects created by the controller are marked with the custom
+// resource that manages them, so that after a lost status update the next
+// reconcile can find and adopt the object instead of creating a duplicate.
+// Objects without such a marker, or with another owner's, are never adopted.
+//
+// Models are the exception: they have no field to carry a marker, so a
+// LangfuseModel without an ID in its status adopts a custom model whose
+// fields all match its spec and that no other LangfuseModel has recorded.
+// From then on it finds the model by the recorded ID. LLM connections have
+// no such field either, but are never adopted: one of the same provider is
+// only replaced by the LangfuseLlmConnection whose status has its ID.
+const (
+	// managedBy identifies this controller in ownership markers.
+	managedBy = "langfuse-controller"
+
+	// metadataManagedBy and metadataOwner are the keys of the marker in the
+	// metadata of objects that have it, such as projects.
+	metadataManagedBy = "managedBy"
+	metadataOwner     = "owner"
+)
+
+// ownerOf identifies the custom resource of the given kind that manages a
+// Langfuse object, e.g. "LangfuseProject/default/my-project".
+func ownerOf(kind string, obj client.Object) string {
+	return kind + "/" + obj.GetNamespace() + "/" + obj.GetName()
+}
+
+// ownershipMetadata returns the marker for objects with metadata.
+func ownershipMetadata(kind string, obj client.Object) map[string]interface{} {
+	return map[string]interface{}{
+		metadataManagedBy: managedBy,
+		metadataOwner:     ownerOf(kind, obj),
+	}
+}
+
+// ownedByMetadata reports whether metadata carries the marker of obj.
+func ownedByMetadata(metadata map[string]interface{}, kind string, obj client.Object) bool {
+	return metadata[metadataManagedBy] == managedBy && metadata[metadataOwner] == ownerOf(kind, obj)
+}
+
+// ownershipMarker returns the marker for objects without metadata. It is
+// stored in a free-text field such as a prompt's commit message.
+func ownershipMarker(kind string, obj client.Object) string {
+	return "Managed by " + managedBy + " for " + ownerOf(kind, obj)
+}
+
+// sameJSON reports whether a and b encode to equivalent JSON documents. It
+// compares values built from the spec with those decoded from Langfuse.
+func sameJSON(a, b interface{}) bool {
+	var da, db interface{}
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	if errA != nil || errB != nil || json.Unmarshal(ja, &da) != nil || json.Unmarshal(jb, &db) != nil {
+		return false
+	}
+	return reflect.DeepEqual(da, db)
+}
@@ -0,0 +1,202 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	langfusev1alpha1 "github.com/sqaisar/langfuse-controller/api/v1alpha1"
+	"github.com/sqaisar/langfuse-controller/internal/langfuse"
+)
+
+const (
+	// LabelProjectID marks the controller-owned Secret holding the internal
+	// API key of the Langfuse project with this ID.
+	LabelProjectID = "langfuse.io/project-id"
+	// AnnotationAPIKeyID records the Langfuse ID of the key in a Secret.
+	AnnotationAPIKeyID = "langfuse.io/api-key-id"
+
+	// projectKeySecretSuffix is appended to the LangfuseProject name to name
+	// its internal key Secret.
+	projectKeySecretSuffix = "-langfuse-internal-key"
+	// projectKeyNote is shown next to the internal key in the Langfuse UI.
+	projectKeyNote = "langfuse-controller (internal, do not delete)"
+)
+
+// ProjectCredentials serves the internal project keys kept in
+// controller-owned Secrets to the Langfuse client, so that project-level
+// calls are made with a key scoped to the project. The
+// LangfuseProjectReconciler creates the keys and Secrets, and replaces
+// keys that Langfuse rejected.
+type ProjectCredentials struct {
+	// Reader finds the LangfuseProject of a project ID and reads the key
+	// Secret it controls.
+	Reader client.Reader
+
+	mu    sync.RWMutex
+	cache map[string]langfuse.Credentials
+	// owners are the LangfuseProjects of the cached keys.
+	owners map[string]*langfusev1alpha1.LangfuseProject
+	// rejected holds the projects whose key Langfuse rejected, until a new
+	// key is set.
+	rejected map[string]bool
+
+	eventsOnce sync.Once
+	events     chan event.GenericEvent
+}
+
+var _ langfuse.ProjectCredentialsSource = (*ProjectCredentials)(nil)
+
+// ProjectCredentials implements langfuse.ProjectCredentialsSource.
+func (p *ProjectCredentials) ProjectCredentials(ctx context.Context, projectID string) (langfuse.Credentials,
+	bool, error) {
+	p.mu.RLock()
+	creds, ok := p.cache[projectID]
+	rejected := p.rejected[projectID]
+	p.mu.RUnlock()
+	if ok {
+		return creds, true, nil
+	}
+	if rejected {
+		// The Secret still holds the rejected key.
+		return langfuse.Credentials{}, false, nil
+	}
+
+	var projects langfusev1alpha1.LangfuseProjectList
+	if err := p.Reader.List(ctx, &projects); err != nil {
+		return langfuse.Credentials{}, false, err
+	}
+	for i := range projects.Items {
+		project := &projects.Items[i]
+		if project.Status.ID != projectID {
+			continue
+		}
+		creds, ok, err := p.projectKey(ctx, project)
+		if err != nil || ok {
+			if ok {
+				p.set(project, creds)
+			}
+			return creds, ok, err
+		}
+	}
+	return langfuse.Credentials{}, false, nil
+}
+
+// ProjectCredentialsRejected implements langfuse.ProjectCredentialsSource.
+// The key is not used again, and its LangfuseProject is reconciled so that
+// the key is replaced.
+func (p *ProjectCredentials) ProjectCredentialsRejected(projectID string) {
+	p.mu.Lock()
+	owner := p.owners[projectID]
+	delete(p.cache, projectID)
+	delete(p.owners, projectID)
+	if p.rejected == nil {
+		p.rejected = map[string]bool{}
+	}
+	p.rejected[projectID] = true
+	p.mu.Unlock()
+
+	if owner == nil {
+		return
+	}
+	select {
+	case p.channel() <- event.GenericEvent{Object: owner}:
+	default:
+		// The periodic sync replaces the key instead.
+	}
+}
+
+// Rejected reports whether Langfuse rejected the key of projectID since it
+// was last set.
+func (p *ProjectCredentials) Rejected(projectID string) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.rejected[projectID]
+}
+
+// Events returns the LangfuseProjects whose key was rejected, for the
+// LangfuseProjectReconciler to watch.
+func (p *ProjectCredentials) Events() <-chan event.GenericEvent {
+	return p.channel()
+}
+
+func (p *ProjectCredentials) channel() chan event.GenericEvent {
+	p.eventsOnce.Do(func() { p.events = make(chan event.GenericEvent, 16) })
+	return p.events
+}
+
+// projectKey reads the internal key of project from its Secret. Secrets
+// that project does not control are ignored, so that no one with access to
+// a namespace can make the controller use their key for another project.
+func (p *ProjectCredentials) projectKey(ctx context.Context,
+	project *langfusev1alpha1.LangfuseProject) (langfuse.Credentials, bool, error) {
+	secret := &corev1.Secret{}
+	key := types.NamespacedName{Name: project.Name + projectKeySecretSuffix, Namespace: project.Namespace}
+	if err := p.Reader.Get(ctx, key, secret); apierrors.IsNotFound(err) {
+		return langfuse.Credentials{}, false, nil
+	} else if err != nil {
+		return langfuse.Credentials{}, false, err
+	}
+	if !metav1.IsControlledBy(secret, project) || secret.Labels[LabelProjectID] != project.Status.ID {
+		return langfuse.Credentials{}, false, nil
+	}
+	creds, ok := credentialsFromSecret(secret)
+	return creds, ok, nil
+}
+
+// set caches the key of project.
+func (p *ProjectCredentials) set(project *langfusev1alpha1.LangfuseProject, creds langfuse.Credentials) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.cache == nil {
+		p.cache = map[string]langfuse.Credentials{}
+		p.owners = map[string]*langfusev1alpha1.LangfuseProject{}
+	}
+	id := project.Status.ID
+	p.cache[id] = creds
+	p.owners[id] = &langfusev1alpha1.LangfuseProject{
+		ObjectMeta: metav1.ObjectMeta{Name: project.Name, Namespace: project.Namespace},
+	}
+	delete(p.rejected, id)
+}
+
+// forget drops the key of a deleted project.
+func (p *ProjectCredentials) forget(projectID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.cache, projectID)
+	delete(p.owners, projectID)
+	delete(p.rejected, projectID)
+}
+
+// credentialsFromSecret reads a key pair in the layout of the Secrets
+// written for LangfuseAPIKey.
+func credentialsFromSecret(secret *corev1.Secret) (langfuse.Credentials, bool) {
+	creds := langfuse.Credentials{
+		PublicKey: string(secret.Data["LANGFUSE_PUBLIC_KEY"]),
+		SecretKey: string(secret.Data["LANGFUSE_SECRET_KEY"]),
+	}
+	return creds, creds.PublicKey != "" && creds.SecretKey != ""
+}
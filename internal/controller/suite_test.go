@@ -25,6 +25,8 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -114,3 +116,39 @@ func getFirstFoundEnvTestBinaryDir() string {
 	}
 	return ""
 }
+
+// createReadyProject creates a LangfuseProject CR whose status already points
+// at projectID, as the project reconciler leaves it once the remote project
+// exists. Child resource tests use it as their projectRef.
+func createReadyProject(ctx context.Context, key types.NamespacedName, projectID string) {
+	project := &langfusev1alpha1.LangfuseProject{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      key.Name,
+			Namespace: key.Namespace,
+		},
+		Spec: langfusev1alpha1.LangfuseProjectSpec{
+			Name: key.Name,
+		},
+	}
+	Expect(k8sClient.Create(ctx, project)).To(Succeed())
+	project.Status.ID = projectID
+	project.Status.State = "Ready"
+	Expect(k8sClient.Status().Update(ctx, project)).To(Succeed())
+}
+
+// deleteProject removes a LangfuseProject CR created by createReadyProject.
+func deleteProject(ctx context.Context, key types.NamespacedName) {
+	project := &langfusev1alpha1.LangfuseProject{}
+	Expect(k8sClient.Get(ctx, key, project)).To(Succeed())
+	deleteAndRelease(ctx, project)
+}
+
+// deleteAndRelease deletes obj and removes its finalizers, since no
+// controller runs in envtest to remove them.
+func deleteAndRelease(ctx context.Context, obj client.Object) {
+	Expect(k8sClient.Delete(ctx, obj)).To(Succeed())
+	if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(obj), obj); err == nil && len(obj.GetFinalizers()) > 0 {
+		obj.SetFinalizers(nil)
+		Expect(k8sClient.Update(ctx, obj)).To(Succeed())
+	}
+}
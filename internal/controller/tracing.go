@@ -0,0 +1,61 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/sqaisar/langfuse-controller/internal/langfuse"
+)
+
+var tracer = otel.Tracer("github.com/sqaisar/langfuse-controller/internal/controller")
+
+// traced wraps r so that every reconcile runs in a span tagged with the
+// kind, namespace and name of the reconciled resource. Langfuse API calls
+// made during the reconcile become child spans and are attributed to the
+// resource in the audit log.
+func traced(kind string, r reconcile.Reconciler) reconcile.Reconciler {
+	return reconcile.Func(func(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+		ctx, span := tracer.Start(ctx, "Reconcile "+kind, trace.WithAttributes(
+			attribute.String("k8s.resource.kind", kind),
+			attribute.String("k8s.namespace.name", req.Namespace),
+			attribute.String("k8s.resource.name", req.Name),
+		))
+		defer span.End()
+		ctx = langfuse.WithObject(ctx, langfuse.Object{Kind: kind, Namespace: req.Namespace, Name: req.Name})
+
+		result, err := r.Reconcile(ctx, req)
+		if langfuse.IsUnavailable(err) {
+			// The circuit breaker opened during the reconcile. Retry once
+			// Langfuse may be back rather than with the error backoff; the
+			// next reconcile marks the resource LangfuseUnavailable.
+			return ctrl.Result{RequeueAfter: unavailableRequeueAfter}, nil
+		}
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		return result, err
+	})
+}
@@ -0,0 +1,56 @@
+package langfuse
+
+import (
+	"context"
+	"iter"
+)
+
+// LangfuseAPI is the set of Langfuse operations the reconcilers depend on.
+// *Client implements it against a real Langfuse; the fake subpackage
+// provides an in-memory implementation for tests.
+//
+// Methods that take a projectID operate on objects inside that project.
+// Langfuse derives the project of those endpoints from the API key, so a
+// *Client sends them with the project's key from its ProjectCredentials.
+// API key management is an organization-level call and always uses the
+// client's own credentials.
+type LangfuseAPI interface {
+	// Host returns the Langfuse base URL that SDKs should be pointed at.
+	Host() string
+	// Supports reports whether the server has the endpoints of capability.
+	Supports(capability Capability) bool
+	// Available reports false while requests are short-circuited because
+	// Langfuse is down. Calls then fail with ErrUnavailable.
+	Available() bool
+
+	CreateProject(ctx context.Context, project CreateProjectRequest) (*Project, error)
+	GetProject(ctx context.Context, id string) (*Project, error)
+	ListProjects(ctx context.Context) iter.Seq2[Project, error]
+	UpdateProject(ctx context.Context, id string, project UpdateProjectRequest) (*Project, error)
+	DeleteProject(ctx context.Context, id string) error
+
+	CreateAPIKey(ctx context.Context, projectID, name string) (*APIKey, error)
+	ListAPIKeys(ctx context.Context, projectID string) iter.Seq2[APIKeySummary, error]
+	DeleteAPIKey(ctx context.Context, projectID, keyID string) error
+
+	CreateModel(ctx context.Context, model CreateModelRequest) (*Model, error)
+	GetModel(ctx context.Context, id string) (*Model, error)
+	ListModels(ctx context.Context, opts ListOptions) iter.Seq2[Model, error]
+	DeleteModel(ctx context.Context, id string) error
+
+	UpsertLlmConnection(ctx context.Context, projectID string, connection UpsertLlmConnectionRequest) (*LlmConnection, error)
+	ListLlmConnections(ctx context.Context, projectID string, opts ListOptions) iter.Seq2[LlmConnection, error]
+
+	CreatePrompt(ctx context.Context, projectID string, prompt CreatePromptRequest) (*Prompt, error)
+	GetPrompt(ctx context.Context, projectID, name string, opts GetPromptOptions) (*Prompt, error)
+	ListPrompts(ctx context.Context, projectID string, opts ListOptions) iter.Seq2[PromptMeta, error]
+	UpdatePromptLabels(ctx context.Context, projectID, name string, version int, labels []string) (*Prompt, error)
+	DeletePrompt(ctx context.Context, projectID, name string) error
+
+	CreateScoreConfig(ctx context.Context, projectID string, config CreateScoreConfigRequest) (*ScoreConfig, error)
+	GetScoreConfig(ctx context.Context, projectID, id string) (*ScoreConfig, error)
+	ListScoreConfigs(ctx context.Context, projectID string, opts ListOptions) iter.Seq2[ScoreConfig, error]
+	UpdateScoreConfig(ctx context.Context, projectID, id string, config UpdateScoreConfigRequest) (*ScoreConfig, error)
+}
+
+var _ LangfuseAPI = (*Client)(nil)
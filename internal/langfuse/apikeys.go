@@ -0,0 +1,29 @@
+package langfuse
+
+import (
+	"context"
+	"iter"
+)
+
+// CreateAPIKey creates an API key for a project. note is shown next to the
+// key in the Langfuse UI. The secret key is only returned here.
+func (c *Client) CreateAPIKey(ctx context.Context, projectID, note string) (*APIKey, error) {
+	return c.projectsCreateAPIKey(ctx, projectID, CreateAPIKeyRequest{Note: note})
+}
+
+// ListAPIKeys lists the API keys of a project. Secret keys are not returned.
+func (c *Client) ListAPIKeys(ctx context.Context, projectID string) iter.Seq2[APIKeySummary, error] {
+	return unpaginated(ctx, func(ctx context.Context) ([]APIKeySummary, error) {
+		resp, err := c.projectsGetAPIKeys(ctx, projectID)
+		if err != nil {
+			return nil, err
+		}
+		return resp.APIKeys, nil
+	})
+}
+
+// DeleteAPIKey revokes an API key.
+func (c *Client) DeleteAPIKey(ctx context.Context, projectID, keyID string) error {
+	_, err := c.projectsDeleteAPIKey(ctx, projectID, keyID)
+	return err
+}
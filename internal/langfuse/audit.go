@@ -0,0 +1,244 @@
+package langfuse
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Redacted replaces the values of secret fields in audit events.
+const Redacted = "[REDACTED]"
+
+// Object identifies the Kubernetes resource on whose behalf a request is
+// made.
+type Object struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+}
+
+type objectKey struct{}
+
+// WithObject returns a context whose Langfuse requests are attributed to
+// obj in the audit log.
+func WithObject(ctx context.Context, obj Object) context.Context {
+	return context.WithValue(ctx, objectKey{}, obj)
+}
+
+// AuditEvent is one line of the audit log: a mutating request and its
+// outcome after retries.
+type AuditEvent struct {
+	Time     time.Time `json:"time"`
+	Object   *Object   `json:"object,omitempty"`
+	Method   string    `json:"method"`
+	Endpoint string    `json:"endpoint"`
+	Path     string    `json:"path"`
+	// Request is the request body with secret fields replaced by Redacted.
+	Request json.RawMessage `json:"request,omitempty"`
+	// Status is the HTTP status of the last attempt, or 0 if there was no
+	// response.
+	Status   int `json:"status"`
+	Attempts int `json:"attempts"`
+	// Error is the error of the request. For Langfuse error responses it is
+	// only the status, as their message may echo the request.
+	Error string `json:"error,omitempty"`
+}
+
+// AuditLog writes an AuditEvent as a JSON line for every request that
+// changes Langfuse. Reads are not logged, and response bodies never are,
+// since some of them carry newly created secret keys.
+type AuditLog struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewAuditLog returns an audit log writing to w, e.g. os.Stdout or a
+// RotatingFile.
+func NewAuditLog(w io.Writer) *AuditLog {
+	return &AuditLog{w: w}
+}
+
+// Write appends e to the log.
+func (l *AuditLog) Write(e AuditEvent) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, err = l.w.Write(append(line, '\n'))
+	return err
+}
+
+// audit records a request sent by do. Failing to write the audit log does
+// not fail the request.
+func (c *Client) audit(ctx context.Context, method, endpoint, path string, body []byte,
+	attempts, status int, err error) {
+	if c.AuditLog == nil || method == http.MethodGet || method == http.MethodHead {
+		return
+	}
+	e := AuditEvent{
+		Time:     time.Now().UTC(),
+		Method:   method,
+		Endpoint: endpoint,
+		Path:     path,
+		Request:  redact(body),
+		Status:   status,
+		Attempts: attempts,
+	}
+	if obj, ok := ctx.Value(objectKey{}).(Object); ok {
+		e.Object = &obj
+	}
+	if err != nil {
+		e.Error = auditError(err)
+	}
+	if werr := c.AuditLog.Write(e); werr != nil {
+		logf.FromContext(ctx).Error(werr, "Failed to write Langfuse audit log", "method", method, "endpoint", endpoint)
+	}
+}
+
+// auditError describes err for the audit log. The message of an APIError
+// comes from the response body and is left out.
+func auditError(err error) string {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return fmt.Sprintf("langfuse: %s %s: %d %s", apiErr.Method, apiErr.Endpoint, apiErr.StatusCode,
+			http.StatusText(apiErr.StatusCode))
+	}
+	return err.Error()
+}
+
+// redact returns body with the values of secret fields replaced. Bodies
+// that are not JSON are dropped entirely rather than risk leaking them.
+func redact(body []byte) json.RawMessage {
+	if len(body) == 0 {
+		return nil
+	}
+	var doc interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return json.RawMessage(fmt.Sprintf("%q", Redacted))
+	}
+	out, err := json.Marshal(redactValue(doc))
+	if err != nil {
+		return json.RawMessage(fmt.Sprintf("%q", Redacted))
+	}
+	return out
+}
+
+func redactValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, item := range v {
+			if sensitive(k) {
+				v[k] = Redacted
+			} else {
+				v[k] = redactValue(item)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactValue(item)
+		}
+	}
+	return v
+}
+
+// sensitive reports whether a field may hold a secret. Extra headers of
+// LLM connections are redacted as a whole since they usually carry tokens.
+func sensitive(field string) bool {
+	f := strings.ToLower(field)
+	for _, s := range []string{"secret", "password", "token", "authorization", "credential", "extraheaders"} {
+		if strings.Contains(f, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// RotatingFile is an append-only file that is rotated once it would grow
+// beyond MaxSize bytes. Rotated files are renamed to path.1, path.2 and so
+// on, keeping at most MaxBackups of them.
+type RotatingFile struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// OpenRotatingFile opens path for appending, creating it if needed.
+func OpenRotatingFile(path string, maxSize int64, maxBackups int) (*RotatingFile, error) {
+	f := &RotatingFile{path: path, maxSize: maxSize, maxBackups: maxBackups}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *RotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	f.file, f.size = file, info.Size()
+	return nil
+}
+
+// Write appends p, rotating the file first if p would not fit.
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.maxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.maxSize {
+		if err := f.rotate(); err != nil {
+			return 0, fmt.Errorf("rotating %s: %w", f.path, err)
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+func (f *RotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	if f.maxBackups <= 0 {
+		if err := os.Remove(f.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return f.open()
+	}
+	for i := f.maxBackups - 1; i >= 1; i-- {
+		err := os.Rename(fmt.Sprintf("%s.%d", f.path, i), fmt.Sprintf("%s.%d", f.path, i+1))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := os.Rename(f.path, f.path+".1"); err != nil {
+		return err
+	}
+	return f.open()
+}
+
+// Close closes the current file.
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Close()
+}
@@ -0,0 +1,124 @@
+package langfuse
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAuditLogRecordsMutations(t *testing.T) {
+	var buf bytes.Buffer
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			// Created keys come back in the response, which is never logged.
+			_, _ = w.Write([]byte(`{"id":"k1","publicKey":"pk-new","secretKey":"sk-new-secret"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":[],"meta":{}}`))
+	}, Options{AuditLog: NewAuditLog(&buf)})
+	ctx := WithObject(context.Background(), Object{Kind: "LangfuseLlmConnection", Namespace: "team-a", Name: "openai"})
+
+	if _, err := c.UpsertLlmConnection(ctx, "p1", UpsertLlmConnectionRequest{
+		Provider:     "openai",
+		Adapter:      "openai",
+		SecretKey:    "sk-openai-secret",
+		ExtraHeaders: map[string]string{"X-Token": "header-secret"},
+	}); err != nil {
+		t.Fatalf("UpsertLlmConnection: %v", err)
+	}
+	if _, err := c.CreateAPIKey(ctx, "p1", "ci"); err != nil {
+		t.Fatalf("CreateAPIKey: %v", err)
+	}
+	if _, err := Collect(c.ListModels(ctx, ListOptions{})); err != nil {
+		t.Fatalf("ListModels: %v", err)
+	}
+
+	out := buf.String()
+	for _, secret := range []string{"sk-openai-secret", "header-secret", "sk-new-secret"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("audit log leaks %q:\n%s", secret, out)
+		}
+	}
+
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d audit lines, want 2 (reads are not logged):\n%s", len(lines), out)
+	}
+	var e AuditEvent
+	if err := json.Unmarshal([]byte(lines[0]), &e); err != nil {
+		t.Fatalf("decoding %s: %v", lines[0], err)
+	}
+	if e.Method != http.MethodPut || e.Endpoint != "/api/public/llm-connections" || e.Status != http.StatusOK {
+		t.Errorf("unexpected event %+v", e)
+	}
+	if e.Object == nil || *e.Object != (Object{Kind: "LangfuseLlmConnection", Namespace: "team-a", Name: "openai"}) {
+		t.Errorf("object: got %+v", e.Object)
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal(e.Request, &body); err != nil {
+		t.Fatalf("decoding request: %v", err)
+	}
+	if body["provider"] != "openai" || body["secretKey"] != Redacted || body["extraHeaders"] != Redacted {
+		t.Errorf("request not redacted as expected: %v", body)
+	}
+}
+
+func TestAuditLogRecordsFailures(t *testing.T) {
+	var buf bytes.Buffer
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		// Error messages may echo the request, including its secrets.
+		w.WriteHeader(http.StatusConflict)
+		_, _ = w.Write([]byte(`{"message":"connection with secretKey sk-echoed-secret already exists"}`))
+	}, Options{AuditLog: NewAuditLog(&buf)})
+
+	if err := c.DeleteModel(context.Background(), "m1"); err == nil {
+		t.Fatal("expected an error")
+	}
+	var e AuditEvent
+	if err := json.Unmarshal(buf.Bytes(), &e); err != nil {
+		t.Fatalf("decoding %s: %v", buf.String(), err)
+	}
+	if e.Status != http.StatusConflict || e.Attempts != 1 {
+		t.Errorf("unexpected event %+v", e)
+	}
+	if want := "langfuse: DELETE /api/public/models/m1: 409 Conflict"; e.Error != want {
+		t.Errorf("error: got %q, want %q", e.Error, want)
+	}
+	if strings.Contains(buf.String(), "sk-echoed-secret") {
+		t.Errorf("audit log leaks the error message:\n%s", buf.String())
+	}
+}
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	f, err := OpenRotatingFile(path, 10, 2)
+	if err != nil {
+		t.Fatalf("OpenRotatingFile: %v", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		if _, err := f.Write([]byte(line)); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	want := map[string]string{
+		path:        "fourth\n",
+		path + ".1": "third\n",
+		path + ".2": "second\n",
+	}
+	for name, content := range want {
+		got, err := os.ReadFile(name)
+		if err != nil || string(got) != content {
+			t.Errorf("%s: got %q, %v; want %q", filepath.Base(name), got, err, content)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("more than two backups kept: %v", err)
+	}
+}
@@ -0,0 +1,159 @@
+package langfuse
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// DefaultBreakerThreshold is the number of consecutive failed requests
+	// after which the circuit breaker opens.
+	DefaultBreakerThreshold = 5
+	// DefaultBreakerProbeInterval is how often a BreakerProbe checks whether
+	// Langfuse is back while the breaker is open.
+	DefaultBreakerProbeInterval = 30 * time.Second
+)
+
+// healthEndpoint is never short-circuited, so that probes can close the
+// breaker.
+const healthEndpoint = "/api/public/health"
+
+// ErrUnavailable is returned without contacting Langfuse while the
+// circuit breaker is open.
+var ErrUnavailable = errors.New("langfuse: unavailable, circuit breaker is open")
+
+// IsUnavailable reports whether err was returned because the circuit
+// breaker is open.
+func IsUnavailable(err error) bool {
+	return errors.Is(err, ErrUnavailable)
+}
+
+// CircuitBreaker stops a Client from sending requests after Langfuse failed
+// Threshold requests in a row, so that reconcilers back off instead of
+// flooding the workqueue and logs while it is down. A request counts as
+// failed if it got no response or a 5xx after all retries. The breaker
+// closes again on the next successful request, which while it is open can
+// only be a health check, see BreakerProbe.
+type CircuitBreaker struct {
+	threshold int
+	// skipMetrics leaves the breaker gauges to the operator's main client.
+	skipMetrics bool
+
+	mu       sync.Mutex
+	failures int
+	open     bool
+	lastErr  error
+}
+
+// NewCircuitBreaker returns a closed breaker that opens after threshold
+// consecutive failures.
+func NewCircuitBreaker(threshold int) *CircuitBreaker {
+	if threshold <= 0 {
+		threshold = DefaultBreakerThreshold
+	}
+	return &CircuitBreaker{threshold: threshold}
+}
+
+// Open reports whether requests are currently short-circuited.
+func (b *CircuitBreaker) Open() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.open
+}
+
+// Check implements a controller-runtime healthz.Checker that fails while
+// the breaker is open.
+func (b *CircuitBreaker) Check(_ *http.Request) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.open {
+		return fmt.Errorf("langfuse circuit breaker is open after %d consecutive failures: %w", b.failures, b.lastErr)
+	}
+	return nil
+}
+
+// record counts the outcome of a request. Errors caused by the caller's
+// context ending say nothing about Langfuse and are ignored.
+func (b *CircuitBreaker) record(ctx context.Context, err error) {
+	if err != nil && ctx.Err() != nil {
+		return
+	}
+	// Any answer below 500, even an error, shows that Langfuse is up.
+	failed := err != nil && (StatusCodeOf(err) == 0 || StatusCodeOf(err) >= http.StatusInternalServerError)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !failed {
+		b.failures, b.lastErr = 0, nil
+		if b.open {
+			b.open = false
+			if !b.skipMetrics {
+				breakerOpen.Set(0)
+			}
+			logf.FromContext(ctx).Info("Langfuse is available again, closing the circuit breaker")
+		}
+		return
+	}
+	b.failures++
+	b.lastErr = err
+	if !b.open && b.failures >= b.threshold {
+		b.open = true
+		if !b.skipMetrics {
+			breakerOpen.Set(1)
+			breakerTrips.Inc()
+		}
+		logf.FromContext(ctx).Error(err, "Langfuse is unavailable, opening the circuit breaker",
+			"consecutiveFailures", b.failures)
+	}
+}
+
+// Available reports whether requests are sent to Langfuse, i.e. the
+// client has no circuit breaker or it is closed.
+func (c *Client) Available() bool {
+	return c.Breaker == nil || !c.Breaker.Open()
+}
+
+// BreakerProbe checks the health endpoint every Interval while the
+// client's circuit breaker is open, closing it once Langfuse answers.
+//
+// It implements manager.Runnable and runs on every replica, leader or not.
+type BreakerProbe struct {
+	Client *Client
+	// Interval defaults to DefaultBreakerProbeInterval.
+	Interval time.Duration
+}
+
+// Start probes Langfuse until ctx is done.
+func (p *BreakerProbe) Start(ctx context.Context) error {
+	if p.Client == nil || p.Client.Breaker == nil {
+		return errors.New("breaker probe needs a client with a circuit breaker")
+	}
+	interval := p.Interval
+	if interval <= 0 {
+		interval = DefaultBreakerProbeInterval
+	}
+	log := logf.FromContext(ctx).WithName("langfuse-breaker")
+	ctx = logf.IntoContext(ctx, log)
+
+	for sleep(ctx, interval) {
+		if !p.Client.Breaker.Open() {
+			continue
+		}
+		if _, err := p.Client.Health(ctx); err != nil {
+			log.V(1).Info("Langfuse is still unavailable", "error", err.Error())
+		}
+	}
+	return nil
+}
+
+// NeedLeaderElection reports false so that the readiness of every replica
+// follows the breaker.
+func (p *BreakerProbe) NeedLeaderElection() bool {
+	return false
+}
@@ -0,0 +1,112 @@
+package langfuse
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCircuitBreakerOpensAndCloses(t *testing.T) {
+	var down atomic.Bool
+	var requests atomic.Int32
+	down.Store(true)
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if down.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"OK","version":"3.0.0"}`))
+	}, Options{MaxRetries: -1, BreakerThreshold: 2})
+	ctx := context.Background()
+
+	for range 2 {
+		if _, err := c.GetModel(ctx, "m1"); StatusCodeOf(err) != http.StatusBadGateway {
+			t.Fatalf("GetModel: got %v, want 502", err)
+		}
+	}
+	if c.Available() || c.Breaker.Check(nil) == nil {
+		t.Fatal("breaker did not open after two failures")
+	}
+	if got := testutil.ToFloat64(breakerOpen); got != 1 {
+		t.Errorf("circuit_breaker_open: got %v, want 1", got)
+	}
+
+	sent := requests.Load()
+	if _, err := c.GetModel(ctx, "m1"); !IsUnavailable(err) {
+		t.Fatalf("GetModel while open: got %v, want ErrUnavailable", err)
+	}
+	if requests.Load() != sent {
+		t.Error("a request was sent while the breaker was open")
+	}
+
+	// Health checks go through and close the breaker once they succeed.
+	down.Store(false)
+	if _, err := c.Health(ctx); err != nil {
+		t.Fatalf("Health: %v", err)
+	}
+	if !c.Available() || c.Breaker.Check(nil) != nil {
+		t.Fatal("breaker did not close after a successful health check")
+	}
+}
+
+func TestCircuitBreakerIgnoresClientErrors(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}, Options{MaxRetries: -1, BreakerThreshold: 1})
+
+	for range 3 {
+		_, _ = c.GetModel(context.Background(), "m1")
+	}
+	if !c.Available() {
+		t.Error("breaker opened on 404s")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _ = c.GetModel(ctx, "m1")
+	if !c.Available() {
+		t.Error("breaker opened on a canceled request")
+	}
+}
+
+func TestBreakerProbe(t *testing.T) {
+	var down atomic.Bool
+	down.Store(true)
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if down.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"OK"}`))
+	}, Options{MaxRetries: -1, BreakerThreshold: 1})
+	if _, err := c.GetModel(context.Background(), "m1"); err == nil {
+		t.Fatal("expected an error")
+	}
+	if c.Available() {
+		t.Fatal("breaker did not open")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- (&BreakerProbe{Client: c, Interval: time.Millisecond}).Start(ctx) }()
+	defer func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Start: %v", err)
+		}
+	}()
+
+	down.Store(false)
+	deadline := time.Now().Add(5 * time.Second)
+	for !c.Available() {
+		if time.Now().After(deadline) {
+			t.Fatal("probe did not close the breaker")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
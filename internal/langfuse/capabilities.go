@@ -0,0 +1,142 @@
+package langfuse
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// DefaultCapabilityProbeInterval is how often a CapabilityDetector probes
+// the server again when Interval is not set, to notice upgrades.
+const DefaultCapabilityProbeInterval = 10 * time.Minute
+
+// Capability is a group of Langfuse endpoints that not every server has.
+// Langfuse v2, v3 and Cloud differ in which they expose.
+type Capability string
+
+const (
+	// CapabilityOrganizations covers the organization API used to manage
+	// projects and their API keys.
+	CapabilityOrganizations Capability = "organizations"
+	// CapabilityModels covers custom model definitions.
+	CapabilityModels Capability = "models"
+	// CapabilityLlmConnections covers LLM connections.
+	CapabilityLlmConnections Capability = "llmConnections"
+	// CapabilityPrompts covers prompt management.
+	CapabilityPrompts Capability = "prompts"
+	// CapabilityScoreConfigs covers score configurations.
+	CapabilityScoreConfigs Capability = "scoreConfigs"
+)
+
+// capabilityProbes maps each capability to a cheap GET request that
+// returns 404 on servers without it.
+var capabilityProbes = []struct {
+	capability Capability
+	endpoint   string
+	path       string
+}{
+	{CapabilityOrganizations, "/api/public/organizations/projects", "/api/public/organizations/projects"},
+	{CapabilityModels, "/api/public/models", "/api/public/models?limit=1"},
+	{CapabilityLlmConnections, "/api/public/llm-connections", "/api/public/llm-connections?limit=1"},
+	{CapabilityPrompts, "/api/public/v2/prompts", "/api/public/v2/prompts?limit=1"},
+	{CapabilityScoreConfigs, "/api/public/score-configs", "/api/public/score-configs?limit=1"},
+}
+
+// Capabilities is what a Langfuse server was found to support.
+type Capabilities struct {
+	// Version is the server version reported by the health endpoint.
+	Version string
+	// Supported records the result of probing each capability.
+	Supported map[Capability]bool
+}
+
+// Has reports whether the server supports c. Capabilities that were not
+// probed are assumed to be supported.
+func (caps *Capabilities) Has(c Capability) bool {
+	supported, probed := caps.Supported[c]
+	return supported || !probed
+}
+
+// Supports reports whether the server supports c. Until DetectCapabilities
+// has succeeded, every capability is assumed to be supported.
+func (c *Client) Supports(capability Capability) bool {
+	caps := c.capabilities.Load()
+	return caps == nil || caps.Has(capability)
+}
+
+// DetectCapabilities probes the server version and endpoints, and makes
+// the result available through Supports. An endpoint counts as missing only
+// if it returns 404; other errors, such as 403s for endpoints the key may
+// not use, do not say that the server lacks it.
+func (c *Client) DetectCapabilities(ctx context.Context) (*Capabilities, error) {
+	health, err := c.Health(ctx)
+	if err != nil {
+		return nil, err
+	}
+	caps := &Capabilities{Version: health.Version, Supported: map[Capability]bool{}}
+	for _, probe := range capabilityProbes {
+		err := c.do(ctx, http.MethodGet, probe.endpoint, probe.path, nil, nil)
+		if err != nil && StatusCodeOf(err) == 0 {
+			// No answer from the server says nothing about the endpoint.
+			return nil, err
+		}
+		caps.Supported[probe.capability] = !IsNotFound(err)
+	}
+	c.capabilities.Store(caps)
+	if !c.skipServerMetrics {
+		observeCapabilities(caps)
+	}
+	return caps, nil
+}
+
+// CapabilityDetector runs DetectCapabilities when the manager starts,
+// retrying until the server answers, and again every Interval.
+//
+// It implements manager.Runnable and runs on every replica, leader or not.
+type CapabilityDetector struct {
+	Client *Client
+	// Interval is how often the server is probed again. Defaults to
+	// DefaultCapabilityProbeInterval.
+	Interval time.Duration
+}
+
+// Start probes the server until ctx is done.
+func (d *CapabilityDetector) Start(ctx context.Context) error {
+	if d.Client == nil {
+		return errors.New("capability detector needs a client")
+	}
+	interval := d.Interval
+	if interval <= 0 {
+		interval = DefaultCapabilityProbeInterval
+	}
+	log := logf.FromContext(ctx).WithName("langfuse-capabilities")
+
+	retry := d.Client.RetryWaitMin
+	for {
+		wait := interval
+		caps, err := d.Client.DetectCapabilities(ctx)
+		switch {
+		case ctx.Err() != nil:
+			return nil
+		case err != nil:
+			log.Error(err, "Failed to detect Langfuse capabilities, assuming all are supported", "retryIn", retry)
+			wait = retry
+			retry = min(2*retry, interval)
+		default:
+			retry = d.Client.RetryWaitMin
+			log.Info("Detected Langfuse capabilities", "version", caps.Version, "supported", caps.Supported)
+		}
+		if !sleep(ctx, wait) {
+			return nil
+		}
+	}
+}
+
+// NeedLeaderElection reports false so that standby replicas know the
+// server's capabilities when they take over.
+func (d *CapabilityDetector) NeedLeaderElection() bool {
+	return false
+}
@@ -0,0 +1,74 @@
+package langfuse
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestDetectCapabilities(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/public/health":
+			_, _ = w.Write([]byte(`{"status":"OK","version":"2.95.0"}`))
+		case "/api/public/llm-connections", "/api/public/organizations/projects":
+			w.WriteHeader(http.StatusNotFound)
+		case "/api/public/score-configs":
+			// Reachable, but not with this key: the endpoint exists.
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			_, _ = w.Write([]byte(`{"data":[],"meta":{"page":1,"limit":1,"totalItems":0,"totalPages":0}}`))
+		}
+	}, Options{})
+
+	if !c.Supports(CapabilityLlmConnections) {
+		t.Error("capabilities must be assumed before detection")
+	}
+	caps, err := c.DetectCapabilities(context.Background())
+	if err != nil {
+		t.Fatalf("DetectCapabilities: %v", err)
+	}
+	if caps.Version != "2.95.0" {
+		t.Errorf("version: got %q", caps.Version)
+	}
+	for capability, want := range map[Capability]bool{
+		CapabilityOrganizations:  false,
+		CapabilityModels:         true,
+		CapabilityLlmConnections: false,
+		CapabilityPrompts:        true,
+		CapabilityScoreConfigs:   true,
+	} {
+		if got := c.Supports(capability); got != want {
+			t.Errorf("Supports(%s): got %v, want %v", capability, got, want)
+		}
+	}
+}
+
+func TestDetectCapabilitiesRequiresServer(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}, Options{MaxRetries: -1})
+
+	if _, err := c.DetectCapabilities(context.Background()); err == nil {
+		t.Fatal("expected an error")
+	}
+	if !c.Supports(CapabilityModels) {
+		t.Error("a failed detection must not mark capabilities unsupported")
+	}
+}
+
+func TestDetectCapabilitiesSkipServerMetrics(t *testing.T) {
+	observeCapabilities(&Capabilities{Version: "3.0.0"})
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"OK","version":"2.0.0"}`))
+	}, Options{SkipServerMetrics: true})
+
+	if _, err := c.DetectCapabilities(context.Background()); err != nil {
+		t.Fatalf("DetectCapabilities: %v", err)
+	}
+	if got := testutil.ToFloat64(serverInfo.WithLabelValues("3.0.0")); got != 1 {
+		t.Errorf("langfuse_server_info of the main server was overwritten: got %v", got)
+	}
+}
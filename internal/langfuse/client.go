@@ -2,114 +2,291 @@ package langfuse
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"sync/atomic"
+	"time"
 )
 
-type Client struct {
-	BaseURL   string
-	Client    *http.Client
+// DefaultRequestTimeout bounds a single HTTP request to Langfuse when
+// Options.RequestTimeout is not set.
+const DefaultRequestTimeout = 30 * time.Second
+
+// Options configures a Client. Zero values fall back to the LANGFUSE_*
+// environment variables and package defaults.
+type Options struct {
+	// BaseURL is the Langfuse endpoint. Defaults to LANGFUSE_HOST, then Langfuse Cloud.
+	BaseURL string
+	// PublicKey defaults to LANGFUSE_PUBLIC_KEY. Use SetCredentials or a
+	// CredentialsWatcher to rotate keys later.
 	PublicKey string
+	// SecretKey defaults to LANGFUSE_SECRET_KEY.
 	SecretKey string
+	// RequestTimeout bounds each HTTP request. Defaults to DefaultRequestTimeout.
+	RequestTimeout time.Duration
+	// MaxRetries is the number of retries for transient failures. Defaults to
+	// DefaultMaxRetries; a negative value disables retries.
+	MaxRetries int
+	// RetryWaitMin is the base backoff delay. Defaults to DefaultRetryWaitMin.
+	RetryWaitMin time.Duration
+	// RetryWaitMax caps a single backoff delay. Defaults to DefaultRetryWaitMax.
+	RetryWaitMax time.Duration
+	// RateLimit is the sustained request rate in requests per second,
+	// including retries. Defaults to DefaultRateLimit; a negative value
+	// disables client-side rate limiting.
+	RateLimit float64
+	// RateBurst is the number of requests that may be sent at once. Defaults
+	// to DefaultRateBurst.
+	RateBurst int
+	// ProjectCredentials supplies the keys for project-level calls. Without
+	// it every call uses PublicKey and SecretKey.
+	ProjectCredentials ProjectCredentialsSource
+	// Transport sends the HTTP requests. Defaults to http.DefaultTransport;
+	// use NewTransport for custom CAs, client certificates or proxies.
+	Transport http.RoundTripper
+	// AuditLog, if set, records every request that changes Langfuse.
+	AuditLog *AuditLog
+	// BreakerThreshold is the number of consecutive failed requests after
+	// which the circuit breaker opens. Defaults to DefaultBreakerThreshold;
+	// a negative value disables the breaker.
+	BreakerThreshold int
+	// SkipServerMetrics keeps the client from setting the gauges that
+	// describe the operator's Langfuse server: langfuse_client_authenticated,
+	// langfuse_server_info, langfuse_server_capability and the circuit
+	// breaker gauges. Set it on clients of additional Langfuse instances.
+	SkipServerMetrics bool
+}
+
+type Client struct {
+	BaseURL string
+	Client  *http.Client
+
+	// credentials is swapped atomically by SetCredentials. They are the
+	// organization key, used for calls outside of a project.
+	credentials atomic.Pointer[Credentials]
+
+	// ProjectCredentials supplies the keys of project-level calls, see
+	// ProjectCredentialsSource.
+	ProjectCredentials ProjectCredentialsSource
+
+	// capabilities is set by DetectCapabilities.
+	capabilities atomic.Pointer[Capabilities]
+
+	// RequestTimeout bounds each HTTP request on top of the caller's context.
+	RequestTimeout time.Duration
+
+	// MaxRetries, RetryWaitMin and RetryWaitMax control retries of transient
+	// failures, see retry.go.
+	MaxRetries   int
+	RetryWaitMin time.Duration
+	RetryWaitMax time.Duration
+
+	// RateLimiter throttles every attempt. Nil disables rate limiting.
+	RateLimiter *RateLimiter
+
+	// AuditLog records mutating requests, see audit.go. Nil disables it.
+	AuditLog *AuditLog
+
+	// Breaker short-circuits requests while Langfuse is down, see
+	// breaker.go. Nil disables it.
+	Breaker *CircuitBreaker
+
+	// skipServerMetrics is set from Options.SkipServerMetrics.
+	skipServerMetrics bool
 }
 
-func NewClient() *Client {
-	baseURL := os.Getenv("LANGFUSE_HOST")
+func NewClient(opts Options) *Client {
+	baseURL := opts.BaseURL
+	if baseURL == "" {
+		baseURL = os.Getenv("LANGFUSE_HOST")
+	}
 	if baseURL == "" {
 		baseURL = "https://cloud.langfuse.com"
 	}
-	publicKey := os.Getenv("LANGFUSE_PUBLIC_KEY")
-	secretKey := os.Getenv("LANGFUSE_SECRET_KEY")
+	publicKey := opts.PublicKey
+	if publicKey == "" {
+		publicKey = os.Getenv("LANGFUSE_PUBLIC_KEY")
+	}
+	secretKey := opts.SecretKey
+	if secretKey == "" {
+		secretKey = os.Getenv("LANGFUSE_SECRET_KEY")
+	}
+	requestTimeout := opts.RequestTimeout
+	if requestTimeout <= 0 {
+		requestTimeout = DefaultRequestTimeout
+	}
+	maxRetries := opts.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = DefaultMaxRetries
+	} else if maxRetries < 0 {
+		maxRetries = 0
+	}
+	retryWaitMin := opts.RetryWaitMin
+	if retryWaitMin <= 0 {
+		retryWaitMin = DefaultRetryWaitMin
+	}
+	retryWaitMax := opts.RetryWaitMax
+	if retryWaitMax <= 0 {
+		retryWaitMax = DefaultRetryWaitMax
+	}
+	var limiter *RateLimiter
+	if opts.RateLimit >= 0 {
+		rate, burst := opts.RateLimit, opts.RateBurst
+		if rate == 0 {
+			rate = DefaultRateLimit
+		}
+		if burst <= 0 {
+			burst = DefaultRateBurst
+		}
+		limiter = NewRateLimiter(rate, burst)
+	}
 
-	return &Client{
-		BaseURL:   baseURL,
-		Client:    &http.Client{},
-		PublicKey: publicKey,
-		SecretKey: secretKey,
+	var breaker *CircuitBreaker
+	if opts.BreakerThreshold >= 0 {
+		breaker = NewCircuitBreaker(opts.BreakerThreshold)
+		breaker.skipMetrics = opts.SkipServerMetrics
 	}
-}
 
-func (c *Client) do(req *http.Request, v interface{}) error {
-	// Use Basic Auth with public_key:secret_key base64 encoded
-	auth := base64.StdEncoding.EncodeToString([]byte(c.PublicKey + ":" + c.SecretKey))
-	req.Header.Set("Authorization", "Basic "+auth)
-	req.Header.Set("Content-Type", "application/json")
+	c := &Client{
+		BaseURL:        baseURL,
+		Client:         &http.Client{Transport: opts.Transport},
+		RequestTimeout: requestTimeout,
+		MaxRetries:     maxRetries,
+		RetryWaitMin:   retryWaitMin,
+		RetryWaitMax:   retryWaitMax,
+		RateLimiter:    limiter,
+		AuditLog:       opts.AuditLog,
+		Breaker:        breaker,
 
-	resp, err := c.Client.Do(req)
-	if err != nil {
-		return err
+		ProjectCredentials: opts.ProjectCredentials,
+		skipServerMetrics:  opts.SkipServerMetrics,
 	}
-	defer func() { _ = resp.Body.Close() }()
+	c.SetCredentials(Credentials{PublicKey: publicKey, SecretKey: secretKey})
+	return c
+}
 
-	if resp.StatusCode >= 400 {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("API error: %s: %s", resp.Status, string(body))
+// Host returns the Langfuse base URL.
+func (c *Client) Host() string {
+	return c.BaseURL
+}
+
+// do sends a JSON request to path and decodes the response into v. endpoint
+// is the path template, such as "/api/public/models/{id}", used to label
+// metrics and traces. Each attempt waits for the rate limiter, is bound to
+// ctx and additionally to the client's RequestTimeout; transient failures
+// are retried with backoff as long as ctx allows.
+func (c *Client) do(ctx context.Context, method, endpoint, path string, body, v interface{}) (err error) {
+	ctx, span := c.startSpan(ctx, method, endpoint)
+	var attempts, status int
+	defer func() { endSpan(span, attempts, status, err) }()
+
+	if c.Breaker != nil {
+		if endpoint != healthEndpoint && c.Breaker.Open() {
+			return ErrUnavailable
+		}
+		defer func() { c.Breaker.record(ctx, err) }()
 	}
 
-	if v != nil {
-		return json.NewDecoder(resp.Body).Decode(v)
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("encoding request body: %w", err)
+		}
 	}
-	return nil
-}
+	defer func() { c.audit(ctx, method, endpoint, path, data, attempts, status, err) }()
 
-func (c *Client) CreateProject(name string) (*Project, error) {
-	reqBody, _ := json.Marshal(CreateProjectRequest{Name: name})
-	req, _ := http.NewRequest("POST", c.BaseURL+"/api/public/projects", bytes.NewBuffer(reqBody))
+	priority := requestPriority(ctx, method)
+	for attempt := 0; ; attempt++ {
+		if err := c.wait(ctx, priority); err != nil {
+			return err
+		}
+		start := time.Now()
+		requestsInFlight.Inc()
+		resp, respBody, err := c.send(ctx, method, path, data)
+		requestsInFlight.Dec()
+		observeRequest(endpoint, method, resp, err, time.Since(start))
+		if !c.skipServerMetrics && !usesProjectKey(ctx) {
+			observeAuth(resp)
+		}
+		c.observeProjectAuth(ctx, resp)
+		attempts, status = attempt+1, 0
+		if resp != nil {
+			status = resp.StatusCode
+		}
 
-	var project Project
-	err := c.do(req, &project)
-	return &project, err
-}
+		if err == nil && resp.StatusCode < 400 {
+			if v != nil && len(respBody) > 0 {
+				return json.Unmarshal(respBody, v)
+			}
+			return nil
+		}
 
-func (c *Client) GetProject(id string) (*Project, error) {
-	req, _ := http.NewRequest("GET", c.BaseURL+"/api/public/projects/"+id, nil)
-	var project Project
-	err := c.do(req, &project)
-	return &project, err
+		retry := attempt < c.MaxRetries
+		var header http.Header
+		if err != nil {
+			retry = retry && shouldRetry(ctx, method, 0, err)
+		} else {
+			retry = retry && shouldRetry(ctx, method, resp.StatusCode, nil)
+			header = resp.Header
+			err = newAPIError(method, path, resp.StatusCode, respBody)
+		}
+		if !retry || !sleep(ctx, c.backoff(attempt, header)) {
+			return err
+		}
+		requestRetries.WithLabelValues(endpoint, method).Inc()
+	}
 }
 
-func (c *Client) CreateAPIKey(projectID, name string) (*APIKey, error) {
-	reqBody, _ := json.Marshal(CreateAPIKeyRequest{Name: name, ProjectID: projectID})
-	// Note: Endpoint might be different, checking docs...
-	// Docs say POST /api/public/projects/{projectId}/apiKeys
-	req, _ := http.NewRequest("POST", fmt.Sprintf("%s/api/public/projects/%s/apiKeys", c.BaseURL, projectID), bytes.NewBuffer(reqBody))
-
-	var apiKey APIKey
-	err := c.do(req, &apiKey)
-	return &apiKey, err
+// wait blocks until the rate limiter admits a request of priority p.
+func (c *Client) wait(ctx context.Context, p Priority) error {
+	if c.RateLimiter == nil {
+		return nil
+	}
+	waited, err := c.RateLimiter.Wait(ctx, p)
+	rateLimiterWait.WithLabelValues(p.String()).Observe(waited.Seconds())
+	return err
 }
 
-// CreateModel creates a new model definition
-func (c *Client) CreateModel(model Model) (*Model, error) {
-	reqBody, _ := json.Marshal(model)
-	req, _ := http.NewRequest("POST", c.BaseURL+"/api/public/models", bytes.NewBuffer(reqBody))
-	var createdModel Model
-	err := c.do(req, &createdModel)
-	return &createdModel, err
-}
+// send performs a single HTTP attempt and reads the full response body so
+// that the per-request timeout can be released before returning.
+func (c *Client) send(ctx context.Context, method, path string, data []byte) (*http.Response, []byte, error) {
+	if c.RequestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.RequestTimeout)
+		defer cancel()
+	}
 
-// CreateLlmConnection creates a new LLM connection
-// Note: Endpoint is hypothetical, need verification
-func (c *Client) CreateLlmConnection(projectID string, connection interface{}) error {
-	reqBody, _ := json.Marshal(connection)
-	req, _ := http.NewRequest("POST", fmt.Sprintf("%s/api/public/projects/%s/llm-connections", c.BaseURL, projectID), bytes.NewBuffer(reqBody))
-	return c.do(req, nil)
-}
+	var reqBody io.Reader
+	if data != nil {
+		reqBody = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, reqBody)
+	if err != nil {
+		return nil, nil, err
+	}
 
-// CreatePrompt creates a new prompt
-func (c *Client) CreatePrompt(projectID string, prompt interface{}) error {
-	reqBody, _ := json.Marshal(prompt)
-	req, _ := http.NewRequest("POST", fmt.Sprintf("%s/api/public/projects/%s/prompts", c.BaseURL, projectID), bytes.NewBuffer(reqBody))
-	return c.do(req, nil)
-}
+	// Use Basic Auth with public_key:secret_key base64 encoded. The keys are
+	// read per attempt so that rotated credentials apply to retries.
+	creds := c.requestCredentials(ctx)
+	auth := base64.StdEncoding.EncodeToString([]byte(creds.PublicKey + ":" + creds.SecretKey))
+	req.Header.Set("Authorization", "Basic "+auth)
+	req.Header.Set("Content-Type", "application/json")
 
-// CreateScoreConfig creates a new score configuration
-func (c *Client) CreateScoreConfig(projectID string, config interface{}) error {
-	reqBody, _ := json.Marshal(config)
-	req, _ := http.NewRequest("POST", fmt.Sprintf("%s/api/public/projects/%s/score-configs", c.BaseURL, projectID), bytes.NewBuffer(reqBody))
-	return c.do(req, nil)
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp, respBody, nil
 }
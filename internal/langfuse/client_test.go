@@ -0,0 +1,102 @@
+package langfuse
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc, opts Options) *Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	opts.BaseURL = srv.URL
+	if opts.PublicKey == "" {
+		opts.PublicKey = "pk-test"
+	}
+	if opts.SecretKey == "" {
+		opts.SecretKey = "sk-test"
+	}
+	return NewClient(opts)
+}
+
+func TestClientSendsBasicAuth(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "pk-test" || pass != "sk-test" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"projects":[{"id":"p1","name":"demo"}]}`))
+	}, Options{})
+
+	project, err := c.GetProject(context.Background(), "p1")
+	if err != nil {
+		t.Fatalf("GetProject: %v", err)
+	}
+	if project.ID != "p1" || project.Name != "demo" {
+		t.Fatalf("unexpected project %+v", project)
+	}
+}
+
+func TestClientRequestTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}, Options{RequestTimeout: 50 * time.Millisecond, MaxRetries: -1})
+
+	start := time.Now()
+	_, err := c.GetProject(context.Background(), "p1")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Fatalf("request was not bounded by RequestTimeout")
+	}
+}
+
+func TestClientHonoursCallerContext(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}, Options{RequestTimeout: time.Minute})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.GetProject(ctx, "p1"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context canceled, got %v", err)
+	}
+}
+
+func TestClientEndpoints(t *testing.T) {
+	var got []string
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Method+" "+r.URL.RequestURI())
+		_, _ = w.Write([]byte(`{}`))
+	}, Options{})
+	ctx := context.Background()
+
+	_, _ = c.UpsertLlmConnection(ctx, "p1", UpsertLlmConnectionRequest{Provider: "openai"})
+	_, _ = c.GetPrompt(ctx, "p1", "greeting", GetPromptOptions{Label: "staging"})
+	_, _ = c.UpdatePromptLabels(ctx, "p1", "greeting", 2, []string{"production"})
+	_, _ = c.UpdateScoreConfig(ctx, "p1", "sc1", UpdateScoreConfigRequest{})
+	_ = c.DeleteAPIKey(ctx, "p1", "k1")
+
+	want := []string{
+		"PUT /api/public/llm-connections",
+		"GET /api/public/v2/prompts/greeting?label=staging",
+		"PATCH /api/public/v2/prompts/greeting/versions/2",
+		"PATCH /api/public/score-configs/sc1",
+		"DELETE /api/public/projects/p1/apiKeys/k1",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("requested %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,194 @@
+package langfuse
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// DefaultCredentialsReloadInterval is how often a CredentialsWatcher checks
+// its files when Interval is not set.
+const DefaultCredentialsReloadInterval = 10 * time.Second
+
+// Credentials are the public/secret key pair sent as Basic auth.
+type Credentials struct {
+	PublicKey string
+	SecretKey string
+}
+
+// Credentials returns the key pair the client currently authenticates with.
+func (c *Client) Credentials() Credentials {
+	return *c.credentials.Load()
+}
+
+// SetCredentials atomically replaces the key pair. Requests already on the
+// wire finish with the old keys; every later attempt, including retries of
+// in-flight calls, uses the new ones.
+func (c *Client) SetCredentials(creds Credentials) {
+	c.credentials.Store(&creds)
+}
+
+// ProjectCredentialsSource looks up the API keys of individual projects.
+// Langfuse derives the project of its project-level endpoints (prompts,
+// score configs, LLM connections) from the key, so those calls must be
+// authenticated with a key of the project rather than the organization key.
+type ProjectCredentialsSource interface {
+	// ProjectCredentials returns the key of projectID. ok is false if the
+	// project has no key yet.
+	ProjectCredentials(ctx context.Context, projectID string) (creds Credentials, ok bool, err error)
+	// ProjectCredentialsRejected is called when Langfuse answers a request
+	// made with the key of projectID with 401, e.g. because the key was
+	// revoked, so that a cached key is not used again.
+	ProjectCredentialsRejected(projectID string)
+}
+
+type credentialsKey struct{}
+
+// projectKey is the key of a project that requests are authenticated with.
+type projectKey struct {
+	projectID string
+	creds     Credentials
+}
+
+// forProject returns a context whose requests authenticate with the key of
+// projectID. Without a ProjectCredentials source, or while the project has
+// no key yet, requests keep using the client's own credentials; the latter
+// is logged, since Langfuse then cannot tell the project from the key.
+func (c *Client) forProject(ctx context.Context, projectID string) (context.Context, error) {
+	if c.ProjectCredentials == nil || projectID == "" {
+		return ctx, nil
+	}
+	creds, ok, err := c.ProjectCredentials.ProjectCredentials(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("looking up the API key of project %s: %w", projectID, err)
+	}
+	if !ok {
+		logf.FromContext(ctx).Info("Project has no API key yet, using the organization key", "projectID", projectID)
+		return ctx, nil
+	}
+	return context.WithValue(ctx, credentialsKey{}, projectKey{projectID: projectID, creds: creds}), nil
+}
+
+// requestCredentials returns the key pair for a request made with ctx.
+func (c *Client) requestCredentials(ctx context.Context) Credentials {
+	if key, ok := ctx.Value(credentialsKey{}).(projectKey); ok {
+		return key.creds
+	}
+	return c.Credentials()
+}
+
+// usesProjectKey reports whether requests made with ctx authenticate with
+// the key of a project rather than the client's own credentials.
+func usesProjectKey(ctx context.Context) bool {
+	_, ok := ctx.Value(credentialsKey{}).(projectKey)
+	return ok
+}
+
+// observeProjectAuth tells the ProjectCredentials source about a response
+// that rejected the project key of ctx.
+func (c *Client) observeProjectAuth(ctx context.Context, resp *http.Response) {
+	key, ok := ctx.Value(credentialsKey{}).(projectKey)
+	if ok && resp != nil && resp.StatusCode == http.StatusUnauthorized {
+		c.ProjectCredentials.ProjectCredentialsRejected(key.projectID)
+	}
+}
+
+// LoadCredentials reads a key pair from two files, such as the keys of a
+// Secret mounted as a volume. Surrounding whitespace is ignored.
+func LoadCredentials(publicKeyFile, secretKeyFile string) (Credentials, error) {
+	publicKey, err := readKeyFile(publicKeyFile)
+	if err != nil {
+		return Credentials{}, err
+	}
+	secretKey, err := readKeyFile(secretKeyFile)
+	if err != nil {
+		return Credentials{}, err
+	}
+	return Credentials{PublicKey: publicKey, SecretKey: secretKey}, nil
+}
+
+func readKeyFile(name string) (string, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return "", fmt.Errorf("reading credentials: %w", err)
+	}
+	key := string(bytes.TrimSpace(data))
+	if key == "" {
+		return "", fmt.Errorf("reading credentials: %s is empty", name)
+	}
+	return key, nil
+}
+
+// CredentialsWatcher reloads a Client's credentials whenever the files
+// change. Mount the credentials Secret as a volume (not with subPath) and
+// the kubelet updates the files in place when the Secret is rotated, so keys
+// can be rotated without restarting the manager.
+//
+// It implements manager.Runnable and runs on every replica, leader or not.
+type CredentialsWatcher struct {
+	Client        *Client
+	PublicKeyFile string
+	SecretKeyFile string
+	// Interval is how often the files are checked. Defaults to
+	// DefaultCredentialsReloadInterval.
+	Interval time.Duration
+}
+
+// Start polls the files until ctx is done. Unreadable or half-written files
+// are logged and the current credentials are kept.
+func (w *CredentialsWatcher) Start(ctx context.Context) error {
+	if w.Client == nil || w.PublicKeyFile == "" || w.SecretKeyFile == "" {
+		return errors.New("credentials watcher needs a client and both key files")
+	}
+	interval := w.Interval
+	if interval <= 0 {
+		interval = DefaultCredentialsReloadInterval
+	}
+	log := logf.FromContext(ctx).WithName("langfuse-credentials")
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		changed, err := w.reload()
+		if err != nil {
+			credentialReloads.WithLabelValues("error").Inc()
+			log.Error(err, "Failed to reload Langfuse credentials, keeping the current ones")
+			continue
+		}
+		if changed {
+			credentialReloads.WithLabelValues("success").Inc()
+			log.Info("Reloaded Langfuse credentials", "publicKey", w.Client.Credentials().PublicKey)
+		}
+	}
+}
+
+// NeedLeaderElection reports false so that standby replicas keep their
+// credentials current too.
+func (w *CredentialsWatcher) NeedLeaderElection() bool {
+	return false
+}
+
+// reload swaps in the credentials from the files if they differ from the
+// client's.
+func (w *CredentialsWatcher) reload() (bool, error) {
+	creds, err := LoadCredentials(w.PublicKeyFile, w.SecretKeyFile)
+	if err != nil {
+		return false, err
+	}
+	if creds == w.Client.Credentials() {
+		return false, nil
+	}
+	w.Client.SetCredentials(creds)
+	return true, nil
+}
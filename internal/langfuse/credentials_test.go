@@ -0,0 +1,231 @@
+package langfuse
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func writeKeys(t *testing.T, dir, publicKey, secretKey string) (string, string) {
+	t.Helper()
+	pk, sk := filepath.Join(dir, "LANGFUSE_PUBLIC_KEY"), filepath.Join(dir, "LANGFUSE_SECRET_KEY")
+	if err := os.WriteFile(pk, []byte(publicKey), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(sk, []byte(secretKey), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return pk, sk
+}
+
+func TestSetCredentialsAppliesToRetries(t *testing.T) {
+	var c *Client
+	var users []string
+	c = newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		user, _, _ := r.BasicAuth()
+		users = append(users, user)
+		if len(users) == 1 {
+			// The keys are rotated while the first attempt is in flight.
+			c.SetCredentials(Credentials{PublicKey: "pk-new", SecretKey: "sk-new"})
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"m1"}`))
+	}, fastRetries())
+
+	if _, err := c.GetModel(context.Background(), "m1"); err != nil {
+		t.Fatalf("GetModel: %v", err)
+	}
+	if len(users) != 2 || users[0] != "pk-test" || users[1] != "pk-new" {
+		t.Errorf("public keys sent: got %v, want [pk-test pk-new]", users)
+	}
+}
+
+func TestLoadCredentials(t *testing.T) {
+	dir := t.TempDir()
+	pk, sk := writeKeys(t, dir, "pk-lf-1\n", " sk-lf-1 ")
+
+	creds, err := LoadCredentials(pk, sk)
+	if err != nil {
+		t.Fatalf("LoadCredentials: %v", err)
+	}
+	if want := (Credentials{PublicKey: "pk-lf-1", SecretKey: "sk-lf-1"}); creds != want {
+		t.Errorf("got %+v, want %+v", creds, want)
+	}
+
+	pk, sk = writeKeys(t, dir, "pk-lf-1", "")
+	if _, err := LoadCredentials(pk, sk); err == nil {
+		t.Error("expected an error for an empty secret key")
+	}
+}
+
+func TestCredentialsWatcherReloads(t *testing.T) {
+	dir := t.TempDir()
+	pk, sk := writeKeys(t, dir, "pk-old", "sk-old")
+	c := NewClient(Options{BaseURL: "http://langfuse.invalid", PublicKey: "pk-old", SecretKey: "sk-old"})
+	w := &CredentialsWatcher{Client: c, PublicKeyFile: pk, SecretKeyFile: sk, Interval: time.Millisecond}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- w.Start(ctx) }()
+	defer func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Start: %v", err)
+		}
+	}()
+
+	writeKeys(t, dir, "pk-new", "sk-new")
+	want := Credentials{PublicKey: "pk-new", SecretKey: "sk-new"}
+	deadline := time.Now().Add(5 * time.Second)
+	for c.Credentials() != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("credentials not reloaded: got %+v", c.Credentials())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestClientRecordsAuthFailures(t *testing.T) {
+	failuresBefore := testutil.ToFloat64(authFailures)
+	valid := true
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if !valid {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"m1"}`))
+	}, Options{})
+
+	if _, err := c.GetModel(context.Background(), "m1"); err != nil {
+		t.Fatalf("GetModel: %v", err)
+	}
+	if got := testutil.ToFloat64(authenticated); got != 1 {
+		t.Errorf("authenticated after 200: got %v, want 1", got)
+	}
+
+	valid = false
+	if _, err := c.GetModel(context.Background(), "m1"); !IsUnauthorized(err) {
+		t.Fatalf("GetModel: got %v, want 401", err)
+	}
+	if got := testutil.ToFloat64(authenticated); got != 0 {
+		t.Errorf("authenticated after 401: got %v, want 0", got)
+	}
+	if got := testutil.ToFloat64(authFailures) - failuresBefore; got != 1 {
+		t.Errorf("auth failures: got %v, want 1", got)
+	}
+}
+
+func TestClientHandlesProjectKeyAuthFailures(t *testing.T) {
+	keys := staticProjectCredentials{"p1": {PublicKey: "pk-p1", SecretKey: "sk-p1"}}
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if user, _, _ := r.BasicAuth(); user == "pk-p1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"m1"}`))
+	}, Options{ProjectCredentials: keys})
+	ctx := context.Background()
+
+	if _, err := c.GetModel(ctx, "m1"); err != nil {
+		t.Fatalf("GetModel: %v", err)
+	}
+	failuresBefore := testutil.ToFloat64(authFailures)
+	if _, err := c.CreatePrompt(ctx, "p1", CreatePromptRequest{Name: "greeting"}); !IsUnauthorized(err) {
+		t.Fatalf("CreatePrompt: got %v, want 401", err)
+	}
+	if got := testutil.ToFloat64(authenticated); got != 1 {
+		t.Errorf("authenticated after a project key 401: got %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(authFailures) - failuresBefore; got != 0 {
+		t.Errorf("auth failures: got %v, want 0", got)
+	}
+	if _, ok := keys["p1"]; ok {
+		t.Error("the rejected project key was not dropped")
+	}
+}
+
+type staticProjectCredentials map[string]Credentials
+
+func (s staticProjectCredentials) ProjectCredentials(_ context.Context, projectID string) (Credentials, bool, error) {
+	creds, ok := s[projectID]
+	return creds, ok, nil
+}
+
+func (s staticProjectCredentials) ProjectCredentialsRejected(projectID string) {
+	delete(s, projectID)
+}
+
+func TestClientUsesProjectCredentials(t *testing.T) {
+	users := map[string]string{}
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		user, _, _ := r.BasicAuth()
+		users[r.URL.Path] = user
+		_, _ = w.Write([]byte(`{}`))
+	}, Options{ProjectCredentials: staticProjectCredentials{
+		"p1": {PublicKey: "pk-p1", SecretKey: "sk-p1"},
+	}})
+	ctx := context.Background()
+
+	if _, err := c.CreatePrompt(ctx, "p1", CreatePromptRequest{Name: "greeting"}); err != nil {
+		t.Fatalf("CreatePrompt: %v", err)
+	}
+	if _, err := c.CreateScoreConfig(ctx, "p2", CreateScoreConfigRequest{Name: "accuracy"}); err != nil {
+		t.Fatalf("CreateScoreConfig: %v", err)
+	}
+	if _, err := c.CreateAPIKey(ctx, "p1", "ci"); err != nil {
+		t.Fatalf("CreateAPIKey: %v", err)
+	}
+
+	want := map[string]string{
+		// Project calls use the project key ...
+		"/api/public/v2/prompts": "pk-p1",
+		// ... unless the project has none yet ...
+		"/api/public/score-configs": "pk-test",
+		// ... and key management always uses the organization key.
+		"/api/public/projects/p1/apiKeys": "pk-test",
+	}
+	for path, user := range want {
+		if users[path] != user {
+			t.Errorf("%s: got public key %q, want %q", path, users[path], user)
+		}
+	}
+}
+
+func TestGetProjectReadsRetentionWithProjectKey(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		user, _, _ := r.BasicAuth()
+		switch {
+		case r.URL.Path == "/api/public/organizations/projects" && user == "pk-test":
+			_, _ = w.Write([]byte(`{"projects":[{"id":"p1","name":"demo"},{"id":"p2","name":"new"}]}`))
+		case r.URL.Path == "/api/public/projects" && user == "pk-p1":
+			_, _ = w.Write([]byte(`{"data":[{"id":"p1","name":"demo","retentionDays":30}]}`))
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}, Options{ProjectCredentials: staticProjectCredentials{
+		"p1": {PublicKey: "pk-p1", SecretKey: "sk-p1"},
+	}})
+	ctx := context.Background()
+
+	project, err := c.GetProject(ctx, "p1")
+	if err != nil {
+		t.Fatalf("GetProject: %v", err)
+	}
+	if project.RetentionDays == nil || *project.RetentionDays != 30 {
+		t.Errorf("p1: got retention %v, want 30", project.RetentionDays)
+	}
+	// Without a project key the retention is unknown.
+	project, err = c.GetProject(ctx, "p2")
+	if err != nil {
+		t.Fatalf("GetProject: %v", err)
+	}
+	if project.RetentionDays != nil {
+		t.Errorf("p2: got retention %d, want none", *project.RetentionDays)
+	}
+}
@@ -0,0 +1,10 @@
+// Package langfuse is a client for the Langfuse public API.
+//
+// The request and response types and the per-endpoint request functions in
+// zz_generated.openapi.go are generated from the vendored OpenAPI
+// specification in openapi/langfuse.yml. The hand-written files add
+// authentication, retries, pagination and the LangfuseAPI surface used by
+// the reconcilers on top of them.
+package langfuse
+
+//go:generate go run ../../hack/langfuse-gen -spec openapi/langfuse.yml -out zz_generated.openapi.go
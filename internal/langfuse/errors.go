@@ -0,0 +1,95 @@
+package langfuse
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// APIError is returned for any non-2xx response from the Langfuse API.
+type APIError struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+	// Method and Endpoint identify the request, e.g. "POST" and "/api/public/projects".
+	Method   string
+	Endpoint string
+	// Message is the error message decoded from the response body, or the raw
+	// body if it was not a Langfuse error document.
+	Message string
+	// Body is the raw response body.
+	Body []byte
+}
+
+// errorResponse is the error document Langfuse returns for failed requests.
+type errorResponse struct {
+	Message string `json:"message"`
+	Error   string `json:"error"`
+}
+
+func newAPIError(method, endpoint string, statusCode int, body []byte) *APIError {
+	e := &APIError{
+		StatusCode: statusCode,
+		Method:     method,
+		Endpoint:   endpoint,
+		Body:       body,
+	}
+	var doc errorResponse
+	if json.Unmarshal(body, &doc) == nil && (doc.Message != "" || doc.Error != "") {
+		e.Message = doc.Message
+		if e.Message == "" {
+			e.Message = doc.Error
+		}
+	} else {
+		e.Message = strings.TrimSpace(string(body))
+	}
+	return e
+}
+
+func (e *APIError) Error() string {
+	status := fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	if e.Message == "" {
+		return fmt.Sprintf("langfuse: %s %s: %s", e.Method, e.Endpoint, status)
+	}
+	return fmt.Sprintf("langfuse: %s %s: %s: %s", e.Method, e.Endpoint, status, e.Message)
+}
+
+// StatusCodeOf returns the HTTP status code carried by err, or 0 if err is
+// not (or does not wrap) an *APIError.
+func StatusCodeOf(err error) int {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	return 0
+}
+
+// IsNotFound reports whether err is a 404 from Langfuse, e.g. because the
+// object was deleted out-of-band.
+func IsNotFound(err error) bool {
+	return StatusCodeOf(err) == http.StatusNotFound
+}
+
+// IsConflict reports whether err is a 409 from Langfuse, typically because
+// an object with the same natural key already exists.
+func IsConflict(err error) bool {
+	return StatusCodeOf(err) == http.StatusConflict
+}
+
+// IsUnauthorized reports whether err means the configured credentials were
+// rejected or lack permission (401 or 403).
+func IsUnauthorized(err error) bool {
+	code := StatusCodeOf(err)
+	return code == http.StatusUnauthorized || code == http.StatusForbidden
+}
+
+// IsRateLimited reports whether err is a 429 from Langfuse.
+func IsRateLimited(err error) bool {
+	return StatusCodeOf(err) == http.StatusTooManyRequests
+}
+
+// IsServerError reports whether err is a 5xx from Langfuse.
+func IsServerError(err error) bool {
+	return StatusCodeOf(err) >= 500
+}
@@ -0,0 +1,50 @@
+package langfuse
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestAPIErrorClassification(t *testing.T) {
+	tests := []struct {
+		status int
+		check  func(error) bool
+	}{
+		{http.StatusNotFound, IsNotFound},
+		{http.StatusConflict, IsConflict},
+		{http.StatusUnauthorized, IsUnauthorized},
+		{http.StatusForbidden, IsUnauthorized},
+		{http.StatusTooManyRequests, IsRateLimited},
+		{http.StatusBadGateway, IsServerError},
+	}
+	for _, tt := range tests {
+		err := fmt.Errorf("wrapped: %w", newAPIError(http.MethodGet, "/api/public/projects", tt.status, nil))
+		if !tt.check(err) {
+			t.Errorf("status %d not classified", tt.status)
+		}
+	}
+	if IsNotFound(fmt.Errorf("plain")) {
+		t.Error("plain error classified as not found")
+	}
+}
+
+func TestClientReturnsAPIError(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+		_, _ = w.Write([]byte(`{"message":"Project with this name already exists"}`))
+	}, Options{MaxRetries: -1})
+
+	_, err := c.CreateProject(context.Background(), CreateProjectRequest{Name: "demo"})
+	if !IsConflict(err) {
+		t.Fatalf("expected conflict, got %v", err)
+	}
+	apiErr := err.(*APIError)
+	if apiErr.Method != http.MethodPost || apiErr.Endpoint != "/api/public/projects" {
+		t.Errorf("unexpected request identity %s %s", apiErr.Method, apiErr.Endpoint)
+	}
+	if apiErr.Message != "Project with this name already exists" {
+		t.Errorf("unexpected message %q", apiErr.Message)
+	}
+}
@@ -0,0 +1,820 @@
+// Package fake provides a stateful, in-memory implementation of
+// langfuse.LangfuseAPI for unit tests. It mimics the server-side behaviour
+// the operator relies on: IDs are assigned on create, names are unique
+// within their scope, and creating a prompt with an existing name adds a
+// new version.
+package fake
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/sqaisar/langfuse-controller/internal/langfuse"
+)
+
+// DefaultHost is returned by Host when Client.BaseURL is empty.
+const DefaultHost = "http://langfuse.fake"
+
+// Client is an in-memory Langfuse. The zero value is not usable; call NewClient.
+type Client struct {
+	// BaseURL is returned by Host.
+	BaseURL string
+
+	mu          sync.Mutex
+	nextID      int
+	errors      map[string]error
+	calls       map[string]int
+	unsupported map[langfuse.Capability]bool
+	unavailable bool
+	projects    map[string]*langfuse.Project
+	apiKeys     map[string][]langfuse.APIKey
+	models      map[string]*langfuse.Model
+	// Project-scoped objects, keyed by project ID and then by natural key.
+	llmConnections map[string]map[string]*llmConnection
+	prompts        map[string]map[string][]langfuse.Prompt
+	scoreConfigs   map[string]map[string]*langfuse.ScoreConfig
+}
+
+var _ langfuse.LangfuseAPI = (*Client)(nil)
+
+// llmConnection keeps the upsert request, which carries the secret key, next
+// to the connection Langfuse would return.
+type llmConnection struct {
+	langfuse.LlmConnection
+	request langfuse.UpsertLlmConnectionRequest
+}
+
+// NewClient returns an empty fake Langfuse.
+func NewClient() *Client {
+	return &Client{
+		errors:         map[string]error{},
+		calls:          map[string]int{},
+		unsupported:    map[langfuse.Capability]bool{},
+		projects:       map[string]*langfuse.Project{},
+		apiKeys:        map[string][]langfuse.APIKey{},
+		models:         map[string]*langfuse.Model{},
+		llmConnections: map[string]map[string]*llmConnection{},
+		prompts:        map[string]map[string][]langfuse.Prompt{},
+		scoreConfigs:   map[string]map[string]*langfuse.ScoreConfig{},
+	}
+}
+
+// SetError makes every subsequent call to the named method (e.g.
+// "CreateProject") fail with err. Pass a nil err to clear it.
+func (c *Client) SetError(method string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err == nil {
+		delete(c.errors, method)
+		return
+	}
+	c.errors[method] = err
+}
+
+// SetUnsupported makes Supports report that the server lacks capability,
+// like an older Langfuse would.
+func (c *Client) SetUnsupported(capability langfuse.Capability) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.unsupported[capability] = true
+}
+
+// Supports implements langfuse.LangfuseAPI.
+func (c *Client) Supports(capability langfuse.Capability) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return !c.unsupported[capability]
+}
+
+// SetUnavailable simulates an open circuit breaker: Available reports
+// false and every call fails with langfuse.ErrUnavailable.
+func (c *Client) SetUnavailable(unavailable bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.unavailable = unavailable
+}
+
+// Available implements langfuse.LangfuseAPI.
+func (c *Client) Available() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return !c.unavailable
+}
+
+// Calls returns how many times the named method has been called.
+func (c *Client) Calls(method string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.calls[method]
+}
+
+// Host implements langfuse.LangfuseAPI.
+func (c *Client) Host() string {
+	if c.BaseURL != "" {
+		return c.BaseURL
+	}
+	return DefaultHost
+}
+
+// begin records a call and returns any injected error. Callers must hold c.mu.
+func (c *Client) begin(ctx context.Context, method string) error {
+	c.calls[method]++
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if c.unavailable {
+		return langfuse.ErrUnavailable
+	}
+	return c.errors[method]
+}
+
+func (c *Client) newID(prefix string) string {
+	c.nextID++
+	return fmt.Sprintf("%s-%d", prefix, c.nextID)
+}
+
+func apiError(code int, method, endpoint, format string, args ...interface{}) error {
+	return &langfuse.APIError{
+		StatusCode: code,
+		Method:     method,
+		Endpoint:   endpoint,
+		Message:    fmt.Sprintf(format, args...),
+	}
+}
+
+// project returns the project with id or a 404.
+func (c *Client) project(method, endpoint, id string) (*langfuse.Project, error) {
+	p, ok := c.projects[id]
+	if !ok {
+		return nil, apiError(http.StatusNotFound, method, endpoint, "project %q not found", id)
+	}
+	return p, nil
+}
+
+// checkRetention rejects the retention values Langfuse rejects.
+func checkRetention(method, endpoint string, days int) error {
+	if days != 0 && days < 3 {
+		return apiError(http.StatusBadRequest, method, endpoint, "retention must be 0 or at least 3 days")
+	}
+	return nil
+}
+
+// CreateProject implements langfuse.LangfuseAPI.
+func (c *Client) CreateProject(ctx context.Context, req langfuse.CreateProjectRequest) (*langfuse.Project, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.begin(ctx, "CreateProject"); err != nil {
+		return nil, err
+	}
+	for _, p := range c.projects {
+		if p.Name == req.Name {
+			return nil, apiError(http.StatusConflict, http.MethodPost, "/api/public/projects",
+				"project with name %q already exists", req.Name)
+		}
+	}
+	if err := checkRetention(http.MethodPost, "/api/public/projects", req.Retention); err != nil {
+		return nil, err
+	}
+	p := &langfuse.Project{ID: c.newID("project"), Name: req.Name, Metadata: req.Metadata}
+	if req.Retention != 0 {
+		days := req.Retention
+		p.RetentionDays = &days
+	}
+	c.projects[p.ID] = p
+	out := *p
+	return &out, nil
+}
+
+// GetProject implements langfuse.LangfuseAPI.
+func (c *Client) GetProject(ctx context.Context, id string) (*langfuse.Project, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.begin(ctx, "GetProject"); err != nil {
+		return nil, err
+	}
+	p, err := c.project(http.MethodGet, "/api/public/organizations/projects", id)
+	if err != nil {
+		return nil, err
+	}
+	out := *p
+	return &out, nil
+}
+
+// CreateAPIKey implements langfuse.LangfuseAPI.
+func (c *Client) CreateAPIKey(ctx context.Context, projectID, note string) (*langfuse.APIKey, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.begin(ctx, "CreateAPIKey"); err != nil {
+		return nil, err
+	}
+	if _, err := c.project(http.MethodPost, "/api/public/projects/"+projectID+"/apiKeys", projectID); err != nil {
+		return nil, err
+	}
+	id := c.newID("apikey")
+	key := langfuse.APIKey{
+		ID:               id,
+		Note:             note,
+		PublicKey:        "pk-lf-" + id,
+		SecretKey:        "sk-lf-" + id,
+		DisplaySecretKey: mask("sk-lf-" + id),
+	}
+	c.apiKeys[projectID] = append(c.apiKeys[projectID], key)
+	return &key, nil
+}
+
+// CreateModel implements langfuse.LangfuseAPI.
+func (c *Client) CreateModel(ctx context.Context, req langfuse.CreateModelRequest) (*langfuse.Model, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.begin(ctx, "CreateModel"); err != nil {
+		return nil, err
+	}
+	for _, m := range c.models {
+		if m.ModelName == req.ModelName {
+			return nil, apiError(http.StatusConflict, http.MethodPost, "/api/public/models",
+				"model %q already exists", req.ModelName)
+		}
+	}
+	model := langfuse.Model{
+		ID:              c.newID("model"),
+		ModelName:       req.ModelName,
+		MatchPattern:    req.MatchPattern,
+		StartDate:       req.StartDate,
+		Unit:            req.Unit,
+		InputPrice:      req.InputPrice,
+		OutputPrice:     req.OutputPrice,
+		TotalPrice:      req.TotalPrice,
+		TokenizerID:     req.TokenizerID,
+		TokenizerConfig: req.TokenizerConfig,
+	}
+	c.models[model.ID] = &model
+	out := model
+	return &out, nil
+}
+
+// CreatePrompt implements langfuse.LangfuseAPI. A prompt with an existing
+// name is stored as the next version, and labels are moved from older
+// versions to the new one.
+func (c *Client) CreatePrompt(ctx context.Context, projectID string,
+	req langfuse.CreatePromptRequest) (*langfuse.Prompt, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.begin(ctx, "CreatePrompt"); err != nil {
+		return nil, err
+	}
+	if _, err := c.project(http.MethodPost, "/api/public/v2/prompts", projectID); err != nil {
+		return nil, err
+	}
+	byName := c.prompts[projectID]
+	if byName == nil {
+		byName = map[string][]langfuse.Prompt{}
+		c.prompts[projectID] = byName
+	}
+	versions := byName[req.Name]
+	for i := range versions {
+		versions[i].Labels = without(versions[i].Labels, req.Labels)
+	}
+	prompt := langfuse.Prompt{
+		Name:          req.Name,
+		Version:       len(versions) + 1,
+		Type:          req.Type,
+		Prompt:        req.Prompt,
+		Config:        req.Config,
+		Labels:        req.Labels,
+		Tags:          req.Tags,
+		CommitMessage: req.CommitMessage,
+	}
+	byName[req.Name] = append(versions, prompt)
+	out := prompt
+	return &out, nil
+}
+
+// CreateScoreConfig implements langfuse.LangfuseAPI. Names are unique
+// within a project.
+func (c *Client) CreateScoreConfig(ctx context.Context, projectID string,
+	req langfuse.CreateScoreConfigRequest) (*langfuse.ScoreConfig, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.begin(ctx, "CreateScoreConfig"); err != nil {
+		return nil, err
+	}
+	endpoint := "/api/public/score-configs"
+	if _, err := c.project(http.MethodPost, endpoint, projectID); err != nil {
+		return nil, err
+	}
+	configs := c.scoreConfigs[projectID]
+	if configs == nil {
+		configs = map[string]*langfuse.ScoreConfig{}
+		c.scoreConfigs[projectID] = configs
+	}
+	for _, sc := range configs {
+		if sc.Name == req.Name {
+			return nil, apiError(http.StatusConflict, http.MethodPost, endpoint,
+				"score config %q already exists", req.Name)
+		}
+	}
+	config := langfuse.ScoreConfig{
+		ID:          c.newID("scoreconfig"),
+		ProjectID:   projectID,
+		Name:        req.Name,
+		DataType:    req.DataType,
+		MinValue:    req.MinValue,
+		MaxValue:    req.MaxValue,
+		Categories:  req.Categories,
+		Description: req.Description,
+	}
+	configs[config.ID] = &config
+	out := config
+	return &out, nil
+}
+
+// ListProjects implements langfuse.LangfuseAPI.
+func (c *Client) ListProjects(ctx context.Context) iter.Seq2[langfuse.Project, error] {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.begin(ctx, "ListProjects"); err != nil {
+		return failed[langfuse.Project](err)
+	}
+	return items(c.sortedProjects())
+}
+
+// UpdateProject implements langfuse.LangfuseAPI. Metadata and retention
+// are left unchanged when they are not set.
+func (c *Client) UpdateProject(ctx context.Context, id string,
+	req langfuse.UpdateProjectRequest) (*langfuse.Project, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.begin(ctx, "UpdateProject"); err != nil {
+		return nil, err
+	}
+	endpoint := "/api/public/projects/" + id
+	p, err := c.project(http.MethodPut, endpoint, id)
+	if err != nil {
+		return nil, err
+	}
+	for _, other := range c.projects {
+		if other.ID != id && other.Name == req.Name {
+			return nil, apiError(http.StatusConflict, http.MethodPut, endpoint,
+				"project with name %q already exists", req.Name)
+		}
+	}
+	if req.Retention != nil {
+		if err := checkRetention(http.MethodPut, endpoint, *req.Retention); err != nil {
+			return nil, err
+		}
+		p.RetentionDays = nil
+		if *req.Retention != 0 {
+			days := *req.Retention
+			p.RetentionDays = &days
+		}
+	}
+	p.Name = req.Name
+	if req.Metadata != nil {
+		p.Metadata = req.Metadata
+	}
+	out := *p
+	return &out, nil
+}
+
+// DeleteProject implements langfuse.LangfuseAPI. All project-scoped objects
+// are removed with the project.
+func (c *Client) DeleteProject(ctx context.Context, id string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.begin(ctx, "DeleteProject"); err != nil {
+		return err
+	}
+	if _, err := c.project(http.MethodDelete, "/api/public/projects/"+id, id); err != nil {
+		return err
+	}
+	delete(c.projects, id)
+	delete(c.apiKeys, id)
+	delete(c.llmConnections, id)
+	delete(c.prompts, id)
+	delete(c.scoreConfigs, id)
+	return nil
+}
+
+// ListAPIKeys implements langfuse.LangfuseAPI. Like Langfuse, it does not
+// return secret keys.
+func (c *Client) ListAPIKeys(ctx context.Context, projectID string) iter.Seq2[langfuse.APIKeySummary, error] {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.begin(ctx, "ListAPIKeys"); err != nil {
+		return failed[langfuse.APIKeySummary](err)
+	}
+	if _, err := c.project(http.MethodGet, "/api/public/projects/"+projectID+"/apiKeys", projectID); err != nil {
+		return failed[langfuse.APIKeySummary](err)
+	}
+	keys := make([]langfuse.APIKeySummary, 0, len(c.apiKeys[projectID]))
+	for _, key := range c.apiKeys[projectID] {
+		keys = append(keys, langfuse.APIKeySummary{
+			ID:               key.ID,
+			CreatedAt:        key.CreatedAt,
+			Note:             key.Note,
+			PublicKey:        key.PublicKey,
+			DisplaySecretKey: key.DisplaySecretKey,
+		})
+	}
+	return items(keys)
+}
+
+// DeleteAPIKey implements langfuse.LangfuseAPI.
+func (c *Client) DeleteAPIKey(ctx context.Context, projectID, keyID string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.begin(ctx, "DeleteAPIKey"); err != nil {
+		return err
+	}
+	endpoint := "/api/public/projects/" + projectID + "/apiKeys/" + keyID
+	keys := c.apiKeys[projectID]
+	for i, key := range keys {
+		if key.ID == keyID {
+			c.apiKeys[projectID] = append(keys[:i:i], keys[i+1:]...)
+			return nil
+		}
+	}
+	return apiError(http.StatusNotFound, http.MethodDelete, endpoint, "API key %q not found", keyID)
+}
+
+// GetModel implements langfuse.LangfuseAPI.
+func (c *Client) GetModel(ctx context.Context, id string) (*langfuse.Model, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.begin(ctx, "GetModel"); err != nil {
+		return nil, err
+	}
+	m, ok := c.models[id]
+	if !ok {
+		return nil, apiError(http.StatusNotFound, http.MethodGet, "/api/public/models/"+id, "model %q not found", id)
+	}
+	out := *m
+	return &out, nil
+}
+
+// ListModels implements langfuse.LangfuseAPI.
+func (c *Client) ListModels(ctx context.Context, _ langfuse.ListOptions) iter.Seq2[langfuse.Model, error] {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.begin(ctx, "ListModels"); err != nil {
+		return failed[langfuse.Model](err)
+	}
+	return items(c.sortedModels())
+}
+
+// DeleteModel implements langfuse.LangfuseAPI.
+func (c *Client) DeleteModel(ctx context.Context, id string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.begin(ctx, "DeleteModel"); err != nil {
+		return err
+	}
+	if _, ok := c.models[id]; !ok {
+		return apiError(http.StatusNotFound, http.MethodDelete, "/api/public/models/"+id, "model %q not found", id)
+	}
+	delete(c.models, id)
+	return nil
+}
+
+// ListLlmConnections implements langfuse.LangfuseAPI.
+func (c *Client) ListLlmConnections(ctx context.Context, projectID string,
+	_ langfuse.ListOptions) iter.Seq2[langfuse.LlmConnection, error] {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.begin(ctx, "ListLlmConnections"); err != nil {
+		return failed[langfuse.LlmConnection](err)
+	}
+	if _, err := c.project(http.MethodGet, "/api/public/llm-connections", projectID); err != nil {
+		return failed[langfuse.LlmConnection](err)
+	}
+	conns := make([]langfuse.LlmConnection, 0, len(c.llmConnections[projectID]))
+	for _, conn := range c.llmConnections[projectID] {
+		conns = append(conns, conn.LlmConnection)
+	}
+	sort.Slice(conns, func(i, j int) bool { return conns[i].Provider < conns[j].Provider })
+	return items(conns)
+}
+
+// UpsertLlmConnection implements langfuse.LangfuseAPI. Like the Langfuse
+// endpoint it creates or replaces the connection of the request's provider.
+func (c *Client) UpsertLlmConnection(ctx context.Context, projectID string,
+	req langfuse.UpsertLlmConnectionRequest) (*langfuse.LlmConnection, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.begin(ctx, "UpsertLlmConnection"); err != nil {
+		return nil, err
+	}
+	if _, err := c.project(http.MethodPut, "/api/public/llm-connections", projectID); err != nil {
+		return nil, err
+	}
+	conns := c.llmConnections[projectID]
+	if conns == nil {
+		conns = map[string]*llmConnection{}
+		c.llmConnections[projectID] = conns
+	}
+	conn := &llmConnection{request: req}
+	if existing, ok := conns[req.Provider]; ok {
+		conn.ID = existing.ID
+	} else {
+		conn.ID = c.newID("llmconnection")
+	}
+	conn.Provider = req.Provider
+	conn.Adapter = req.Adapter
+	conn.BaseURL = req.BaseURL
+	conn.CustomModels = req.CustomModels
+	conn.WithDefaultModels = req.WithDefaultModels == nil || *req.WithDefaultModels
+	conn.DisplaySecretKey = mask(req.SecretKey)
+	for header := range req.ExtraHeaders {
+		conn.ExtraHeaderKeys = append(conn.ExtraHeaderKeys, header)
+	}
+	sort.Strings(conn.ExtraHeaderKeys)
+	conns[req.Provider] = conn
+	out := conn.LlmConnection
+	return &out, nil
+}
+
+// GetPrompt implements langfuse.LangfuseAPI.
+func (c *Client) GetPrompt(ctx context.Context, projectID, name string,
+	opts langfuse.GetPromptOptions) (*langfuse.Prompt, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.begin(ctx, "GetPrompt"); err != nil {
+		return nil, err
+	}
+	endpoint := "/api/public/v2/prompts/" + name
+	label := opts.Label
+	if opts.Version == 0 && label == "" {
+		label = "production"
+	}
+	versions := c.prompts[projectID][name]
+	if opts.Version == 0 && label == "latest" && len(versions) > 0 {
+		// Langfuse keeps the "latest" label on the newest version.
+		out := versions[len(versions)-1]
+		return &out, nil
+	}
+	for _, p := range versions {
+		if (opts.Version > 0 && p.Version == opts.Version) || (opts.Version == 0 && contains(p.Labels, label)) {
+			out := p
+			return &out, nil
+		}
+	}
+	return nil, apiError(http.StatusNotFound, http.MethodGet, endpoint, "prompt %q not found", name)
+}
+
+// ListPrompts implements langfuse.LangfuseAPI.
+func (c *Client) ListPrompts(ctx context.Context, projectID string,
+	_ langfuse.ListOptions) iter.Seq2[langfuse.PromptMeta, error] {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.begin(ctx, "ListPrompts"); err != nil {
+		return failed[langfuse.PromptMeta](err)
+	}
+	metas := make([]langfuse.PromptMeta, 0, len(c.prompts[projectID]))
+	for name, versions := range c.prompts[projectID] {
+		meta := langfuse.PromptMeta{Name: name}
+		for _, p := range versions {
+			meta.Type = p.Type
+			meta.Versions = append(meta.Versions, p.Version)
+			meta.Labels = append(meta.Labels, p.Labels...)
+			meta.Tags = p.Tags
+		}
+		metas = append(metas, meta)
+	}
+	sort.Slice(metas, func(i, j int) bool { return metas[i].Name < metas[j].Name })
+	return items(metas)
+}
+
+// UpdatePromptLabels implements langfuse.LangfuseAPI.
+func (c *Client) UpdatePromptLabels(ctx context.Context, projectID, name string, version int,
+	labels []string) (*langfuse.Prompt, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.begin(ctx, "UpdatePromptLabels"); err != nil {
+		return nil, err
+	}
+	versions := c.prompts[projectID][name]
+	for i := range versions {
+		if versions[i].Version != version {
+			continue
+		}
+		for j := range versions {
+			if j != i {
+				versions[j].Labels = without(versions[j].Labels, labels)
+			}
+		}
+		versions[i].Labels = append([]string(nil), labels...)
+		out := versions[i]
+		return &out, nil
+	}
+	return nil, apiError(http.StatusNotFound, http.MethodPatch,
+		fmt.Sprintf("/api/public/v2/prompts/%s/versions/%d", name, version),
+		"prompt %q version %d not found", name, version)
+}
+
+// DeletePrompt implements langfuse.LangfuseAPI.
+func (c *Client) DeletePrompt(ctx context.Context, projectID, name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.begin(ctx, "DeletePrompt"); err != nil {
+		return err
+	}
+	if _, ok := c.prompts[projectID][name]; !ok {
+		return apiError(http.StatusNotFound, http.MethodDelete,
+			"/api/public/v2/prompts/"+name, "prompt %q not found", name)
+	}
+	delete(c.prompts[projectID], name)
+	return nil
+}
+
+// GetScoreConfig implements langfuse.LangfuseAPI.
+func (c *Client) GetScoreConfig(ctx context.Context, projectID, id string) (*langfuse.ScoreConfig, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.begin(ctx, "GetScoreConfig"); err != nil {
+		return nil, err
+	}
+	sc, ok := c.scoreConfigs[projectID][id]
+	if !ok {
+		return nil, apiError(http.StatusNotFound, http.MethodGet,
+			"/api/public/score-configs/"+id, "score config %q not found", id)
+	}
+	out := *sc
+	return &out, nil
+}
+
+// ListScoreConfigs implements langfuse.LangfuseAPI.
+func (c *Client) ListScoreConfigs(ctx context.Context, projectID string,
+	_ langfuse.ListOptions) iter.Seq2[langfuse.ScoreConfig, error] {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.begin(ctx, "ListScoreConfigs"); err != nil {
+		return failed[langfuse.ScoreConfig](err)
+	}
+	return items(c.sortedScoreConfigs(projectID))
+}
+
+// UpdateScoreConfig implements langfuse.LangfuseAPI.
+func (c *Client) UpdateScoreConfig(ctx context.Context, projectID, id string,
+	req langfuse.UpdateScoreConfigRequest) (*langfuse.ScoreConfig, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.begin(ctx, "UpdateScoreConfig"); err != nil {
+		return nil, err
+	}
+	endpoint := "/api/public/score-configs/" + id
+	sc, ok := c.scoreConfigs[projectID][id]
+	if !ok {
+		return nil, apiError(http.StatusNotFound, http.MethodPatch, endpoint, "score config %q not found", id)
+	}
+	if req.Name != "" {
+		for _, other := range c.scoreConfigs[projectID] {
+			if other.ID != id && other.Name == req.Name {
+				return nil, apiError(http.StatusConflict, http.MethodPatch, endpoint,
+					"score config %q already exists", req.Name)
+			}
+		}
+		sc.Name = req.Name
+	}
+	if req.IsArchived != nil {
+		sc.IsArchived = *req.IsArchived
+	}
+	if req.MinValue != nil {
+		sc.MinValue = req.MinValue
+	}
+	if req.MaxValue != nil {
+		sc.MaxValue = req.MaxValue
+	}
+	if req.Categories != nil {
+		sc.Categories = req.Categories
+	}
+	if req.Description != "" {
+		sc.Description = req.Description
+	}
+	out := *sc
+	return &out, nil
+}
+
+// Projects returns all projects sorted by ID.
+func (c *Client) Projects() []langfuse.Project {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.sortedProjects()
+}
+
+// APIKeys returns the API keys created for a project.
+func (c *Client) APIKeys(projectID string) []langfuse.APIKey {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]langfuse.APIKey(nil), c.apiKeys[projectID]...)
+}
+
+// Models returns all models sorted by ID.
+func (c *Client) Models() []langfuse.Model {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.sortedModels()
+}
+
+// LlmConnection returns the last upsert request for provider in a project,
+// including the secret key, if any.
+func (c *Client) LlmConnection(projectID, provider string) (langfuse.UpsertLlmConnectionRequest, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	conn, ok := c.llmConnections[projectID][provider]
+	if !ok {
+		return langfuse.UpsertLlmConnectionRequest{}, false
+	}
+	return conn.request, true
+}
+
+// PromptVersions returns all versions of a prompt, oldest first.
+func (c *Client) PromptVersions(projectID, name string) []langfuse.Prompt {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]langfuse.Prompt(nil), c.prompts[projectID][name]...)
+}
+
+// ScoreConfigs returns the score configs of a project sorted by ID.
+func (c *Client) ScoreConfigs(projectID string) []langfuse.ScoreConfig {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.sortedScoreConfigs(projectID)
+}
+
+func (c *Client) sortedProjects() []langfuse.Project {
+	out := make([]langfuse.Project, 0, len(c.projects))
+	for _, p := range c.projects {
+		out = append(out, *p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+func (c *Client) sortedModels() []langfuse.Model {
+	out := make([]langfuse.Model, 0, len(c.models))
+	for _, m := range c.models {
+		out = append(out, *m)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+func (c *Client) sortedScoreConfigs(projectID string) []langfuse.ScoreConfig {
+	out := make([]langfuse.ScoreConfig, 0, len(c.scoreConfigs[projectID]))
+	for _, sc := range c.scoreConfigs[projectID] {
+		out = append(out, *sc)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// items returns an iterator over a snapshot of list.
+func items[T any](list []T) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for _, item := range list {
+			if !yield(item, nil) {
+				return
+			}
+		}
+	}
+}
+
+// failed returns an iterator that yields only err.
+func failed[T any](err error) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		yield(zero, err)
+	}
+}
+
+// mask hides all but the last four characters of a secret.
+func mask(secret string) string {
+	if len(secret) <= 4 {
+		return "..."
+	}
+	return "..." + secret[len(secret)-4:]
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// without returns labels minus every entry in remove.
+func without(labels, remove []string) []string {
+	var out []string
+	for _, l := range labels {
+		if !contains(remove, l) {
+			out = append(out, l)
+		}
+	}
+	return out
+}
@@ -0,0 +1,87 @@
+package fake
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/sqaisar/langfuse-controller/internal/langfuse"
+)
+
+func TestProjectNamesAreUnique(t *testing.T) {
+	ctx := context.Background()
+	c := NewClient()
+	if _, err := c.CreateProject(ctx, langfuse.CreateProjectRequest{Name: "demo"}); err != nil {
+		t.Fatalf("CreateProject: %v", err)
+	}
+	if _, err := c.CreateProject(ctx, langfuse.CreateProjectRequest{Name: "demo"}); !langfuse.IsConflict(err) {
+		t.Fatalf("expected conflict, got %v", err)
+	}
+}
+
+func TestUpdateProjectSettings(t *testing.T) {
+	ctx := context.Background()
+	c := NewClient()
+	p, _ := c.CreateProject(ctx, langfuse.CreateProjectRequest{
+		Name: "demo", Metadata: map[string]interface{}{"team": "a"}, Retention: 30,
+	})
+	if p.RetentionDays == nil || *p.RetentionDays != 30 {
+		t.Fatalf("retention not set on create: %+v", p)
+	}
+
+	renamed, err := c.UpdateProject(ctx, p.ID, langfuse.UpdateProjectRequest{Name: "renamed"})
+	if err != nil || renamed.Metadata["team"] != "a" || renamed.RetentionDays == nil {
+		t.Fatalf("rename changed other settings: %+v, %v", renamed, err)
+	}
+
+	days := 0
+	updated, err := c.UpdateProject(ctx, p.ID, langfuse.UpdateProjectRequest{
+		Name: "renamed", Metadata: map[string]interface{}{"team": "b"}, Retention: &days,
+	})
+	if err != nil || updated.Metadata["team"] != "b" || updated.RetentionDays != nil {
+		t.Fatalf("unexpected update: %+v, %v", updated, err)
+	}
+
+	days = 1
+	_, err = c.UpdateProject(ctx, p.ID, langfuse.UpdateProjectRequest{Name: "renamed", Retention: &days})
+	var apiErr *langfuse.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected bad request, got %v", err)
+	}
+}
+
+func TestPromptVersions(t *testing.T) {
+	ctx := context.Background()
+	c := NewClient()
+	p, _ := c.CreateProject(ctx, langfuse.CreateProjectRequest{Name: "demo"})
+
+	for _, text := range []string{"v1", "v2"} {
+		if _, err := c.CreatePrompt(ctx, p.ID, langfuse.CreatePromptRequest{
+			Name: "greeting", Type: "text", Prompt: text, Labels: []string{"production"},
+		}); err != nil {
+			t.Fatalf("CreatePrompt: %v", err)
+		}
+	}
+
+	versions := c.PromptVersions(p.ID, "greeting")
+	if len(versions) != 2 || versions[1].Version != 2 || versions[1].Prompt != "v2" {
+		t.Fatalf("unexpected versions %+v", versions)
+	}
+	if len(versions[0].Labels) != 0 {
+		t.Fatalf("label was not moved to the new version: %+v", versions[0].Labels)
+	}
+
+	latest, err := c.GetPrompt(ctx, p.ID, "greeting", langfuse.GetPromptOptions{Label: "latest"})
+	if err != nil || latest.Version != 2 {
+		t.Fatalf("latest: got %+v, %v", latest, err)
+	}
+}
+
+func TestChildRequiresProject(t *testing.T) {
+	c := NewClient()
+	_, err := c.CreateScoreConfig(context.Background(), "missing", langfuse.CreateScoreConfigRequest{Name: "x"})
+	if !langfuse.IsNotFound(err) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+}
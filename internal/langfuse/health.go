@@ -0,0 +1,9 @@
+package langfuse
+
+import "context"
+
+// Health reports the status and version of the Langfuse server. It does not
+// require valid credentials.
+func (c *Client) Health(ctx context.Context) (*HealthResponse, error) {
+	return c.healthHealth(ctx)
+}
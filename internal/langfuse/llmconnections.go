@@ -0,0 +1,36 @@
+package langfuse
+
+import (
+	"context"
+	"iter"
+	"net/url"
+)
+
+// UpsertLlmConnection creates the LLM connection for connection.Provider or
+// replaces the existing one. Langfuse has no endpoint to delete a
+// connection.
+func (c *Client) UpsertLlmConnection(ctx context.Context, projectID string,
+	connection UpsertLlmConnectionRequest) (*LlmConnection, error) {
+	ctx, err := c.forProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	return c.llmConnectionsUpsert(ctx, connection)
+}
+
+// ListLlmConnections lists the LLM connections of a project. Secret keys are
+// not returned.
+func (c *Client) ListLlmConnections(ctx context.Context, projectID string,
+	opts ListOptions) iter.Seq2[LlmConnection, error] {
+	return paginate(ctx, opts, func(ctx context.Context, query url.Values) ([]LlmConnection, PageMeta, error) {
+		ctx, err := c.forProject(ctx, projectID)
+		if err != nil {
+			return nil, PageMeta{}, err
+		}
+		page, err := c.llmConnectionsList(ctx, query)
+		if err != nil {
+			return nil, PageMeta{}, err
+		}
+		return page.Data, page.Meta, nil
+	})
+}
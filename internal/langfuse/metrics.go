@@ -0,0 +1,146 @@
+package langfuse
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// Metrics are labelled with the endpoint's path template (for example
+// "/api/public/models/{id}") rather than the request path to keep their
+// cardinality bounded.
+var (
+	requestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "langfuse",
+		Subsystem: "client",
+		Name:      "requests_total",
+		Help: "Langfuse API requests by endpoint, method and status code. Every retry attempt is counted; " +
+			`requests that failed without a response have code="error".`,
+	}, []string{"endpoint", "method", "code"})
+
+	requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "langfuse",
+		Subsystem: "client",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of single Langfuse API request attempts, excluding rate limiting and backoff.",
+		Buckets:   []float64{0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+	}, []string{"endpoint", "method"})
+
+	requestRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "langfuse",
+		Subsystem: "client",
+		Name:      "retries_total",
+		Help:      "Langfuse API requests retried after a transient failure.",
+	}, []string{"endpoint", "method"})
+
+	requestsInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "langfuse",
+		Subsystem: "client",
+		Name:      "requests_in_flight",
+		Help:      "Langfuse API requests currently waiting for a response.",
+	})
+
+	rateLimiterWait = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "langfuse",
+		Subsystem: "client",
+		Name:      "rate_limiter_wait_seconds",
+		Help:      "Time Langfuse API requests spent waiting for the client-side rate limiter, by priority.",
+		Buckets:   []float64{0.001, 0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"priority"})
+
+	authFailures = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "langfuse",
+		Subsystem: "client",
+		Name:      "auth_failures_total",
+		Help:      "Langfuse API responses with status 401 to the organization key, i.e. rejected credentials.",
+	})
+
+	authenticated = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "langfuse",
+		Subsystem: "client",
+		Name:      "authenticated",
+		Help: "1 if the last Langfuse API response to the organization key accepted it, 0 if it was a 401. " +
+			"Alert on 0 to catch expired or revoked keys.",
+	})
+
+	serverInfo = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "langfuse",
+		Subsystem: "server",
+		Name:      "info",
+		Help:      "Always 1, labelled with the version of the Langfuse server as last detected.",
+	}, []string{"version"})
+
+	serverCapability = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "langfuse",
+		Subsystem: "server",
+		Name:      "capability",
+		Help:      "1 if the Langfuse server supports the capability, 0 if its endpoints are missing.",
+	}, []string{"capability"})
+
+	breakerOpen = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "langfuse",
+		Subsystem: "client",
+		Name:      "circuit_breaker_open",
+		Help:      "1 while the circuit breaker is open and Langfuse API requests are not sent, 0 otherwise.",
+	})
+
+	breakerTrips = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "langfuse",
+		Subsystem: "client",
+		Name:      "circuit_breaker_trips_total",
+		Help:      "Times the circuit breaker opened after consecutive failed Langfuse API requests.",
+	})
+
+	credentialReloads = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "langfuse",
+		Subsystem: "client",
+		Name:      "credential_reloads_total",
+		Help:      `Reloads of the Langfuse credentials from files, by result ("success" or "error").`,
+	}, []string{"result"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(requestsTotal, requestDuration, requestRetries, requestsInFlight, rateLimiterWait,
+		authFailures, authenticated, credentialReloads, serverInfo, serverCapability, breakerOpen, breakerTrips)
+}
+
+// observeRequest records the outcome and latency of one request attempt.
+func observeRequest(endpoint, method string, resp *http.Response, err error, d time.Duration) {
+	code := "error"
+	if err == nil {
+		code = strconv.Itoa(resp.StatusCode)
+	}
+	requestsTotal.WithLabelValues(endpoint, method, code).Inc()
+	requestDuration.WithLabelValues(endpoint, method).Observe(d.Seconds())
+}
+
+// observeAuth records whether a response accepted the client's credentials.
+// 403s are not counted: they mean the key lacks access to one resource, not
+// that it is invalid. Responses to project keys are not observed either;
+// a revoked project key says nothing about the organization key.
+func observeAuth(resp *http.Response) {
+	switch {
+	case resp == nil:
+	case resp.StatusCode == http.StatusUnauthorized:
+		authFailures.Inc()
+		authenticated.Set(0)
+	case resp.StatusCode < 400:
+		authenticated.Set(1)
+	}
+}
+
+// observeCapabilities exports the result of DetectCapabilities.
+func observeCapabilities(caps *Capabilities) {
+	serverInfo.Reset()
+	serverInfo.WithLabelValues(caps.Version).Set(1)
+	for capability, supported := range caps.Supported {
+		value := 0.0
+		if supported {
+			value = 1
+		}
+		serverCapability.WithLabelValues(string(capability)).Set(value)
+	}
+}
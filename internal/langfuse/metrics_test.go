@@ -0,0 +1,44 @@
+package langfuse
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestClientRecordsMetrics(t *testing.T) {
+	const endpoint = "/api/public/models/{id}"
+	ok := requestsTotal.WithLabelValues(endpoint, http.MethodGet, "200")
+	unavailable := requestsTotal.WithLabelValues(endpoint, http.MethodGet, "503")
+	retries := requestRetries.WithLabelValues(endpoint, http.MethodGet)
+	okBefore, unavailableBefore, retriesBefore :=
+		testutil.ToFloat64(ok), testutil.ToFloat64(unavailable), testutil.ToFloat64(retries)
+
+	var calls int
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"m1"}`))
+	}, fastRetries())
+
+	if _, err := c.GetModel(context.Background(), "m1"); err != nil {
+		t.Fatalf("GetModel: %v", err)
+	}
+	if got := testutil.ToFloat64(ok) - okBefore; got != 1 {
+		t.Errorf("200 responses: got %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(unavailable) - unavailableBefore; got != 1 {
+		t.Errorf("503 responses: got %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(retries) - retriesBefore; got != 1 {
+		t.Errorf("retries: got %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(requestsInFlight); got != 0 {
+		t.Errorf("in-flight requests: got %v, want 0", got)
+	}
+}
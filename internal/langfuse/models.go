@@ -0,0 +1,34 @@
+package langfuse
+
+import (
+	"context"
+	"iter"
+	"net/url"
+)
+
+// CreateModel creates a new model definition
+func (c *Client) CreateModel(ctx context.Context, model CreateModelRequest) (*Model, error) {
+	return c.modelsCreate(ctx, model)
+}
+
+// GetModel returns a model definition by ID.
+func (c *Client) GetModel(ctx context.Context, id string) (*Model, error) {
+	return c.modelsGet(ctx, id)
+}
+
+// ListModels lists all model definitions, including Langfuse-managed ones.
+func (c *Client) ListModels(ctx context.Context, opts ListOptions) iter.Seq2[Model, error] {
+	return paginate(ctx, opts, func(ctx context.Context, query url.Values) ([]Model, PageMeta, error) {
+		page, err := c.modelsList(ctx, query)
+		if err != nil {
+			return nil, PageMeta{}, err
+		}
+		return page.Data, page.Meta, nil
+	})
+}
+
+// DeleteModel deletes a model definition. Langfuse models cannot be
+// modified in place; to change one, delete it and create a new one.
+func (c *Client) DeleteModel(ctx context.Context, id string) error {
+	return c.modelsDelete(ctx, id)
+}
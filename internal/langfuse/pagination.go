@@ -0,0 +1,86 @@
+package langfuse
+
+import (
+	"context"
+	"iter"
+	"net/url"
+	"strconv"
+)
+
+// DefaultPageSize is the page size requested when ListOptions.Limit is unset.
+const DefaultPageSize = 50
+
+// ListOptions configures a paginated list call.
+type ListOptions struct {
+	// Limit is the page size. Defaults to DefaultPageSize.
+	Limit int
+}
+
+func (o ListOptions) limit() int {
+	if o.Limit <= 0 {
+		return DefaultPageSize
+	}
+	return o.Limit
+}
+
+// paginate returns an iterator over every item of a list endpoint. fetch
+// returns the items and pagination metadata of the page selected by query.
+// Pages are fetched lazily, starting at page 1, until meta.totalPages is
+// reached or a page comes back empty. Endpoints that return no meta are
+// treated as a single page. Iteration stops at the first error, which is
+// yielded with a zero item.
+func paginate[T any](ctx context.Context, opts ListOptions,
+	fetch func(ctx context.Context, query url.Values) ([]T, PageMeta, error)) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		limit := opts.limit()
+		for page := 1; ; page++ {
+			query := url.Values{}
+			query.Set("page", strconv.Itoa(page))
+			query.Set("limit", strconv.Itoa(limit))
+			data, meta, err := fetch(ctx, query)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			for _, item := range data {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			if len(data) == 0 || meta.TotalPages == 0 || page >= meta.TotalPages {
+				return
+			}
+		}
+	}
+}
+
+// unpaginated returns an iterator over the items of a list endpoint that
+// returns everything in a single response.
+func unpaginated[T any](ctx context.Context, fetch func(ctx context.Context) ([]T, error)) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		data, err := fetch(ctx)
+		if err != nil {
+			var zero T
+			yield(zero, err)
+			return
+		}
+		for _, item := range data {
+			if !yield(item, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Collect drains a list iterator into a slice, returning the first error.
+func Collect[T any](seq iter.Seq2[T, error]) ([]T, error) {
+	var out []T
+	for item, err := range seq {
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, item)
+	}
+	return out, nil
+}
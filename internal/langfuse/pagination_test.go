@@ -0,0 +1,60 @@
+package langfuse
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+func TestListFollowsTotalPages(t *testing.T) {
+	var pages []string
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		pages = append(pages, r.URL.Query().Get("page")+"/"+r.URL.Query().Get("limit"))
+		_, _ = fmt.Fprintf(w, `{"data":[{"id":"m%d"}],"meta":{"page":%d,"limit":1,"totalItems":3,"totalPages":3}}`,
+			page, page)
+	}, Options{})
+
+	models, err := Collect(c.ListModels(context.Background(), ListOptions{Limit: 1}))
+	if err != nil {
+		t.Fatalf("ListModels: %v", err)
+	}
+	if len(models) != 3 || models[2].ID != "m3" {
+		t.Fatalf("unexpected models %+v", models)
+	}
+	if want := []string{"1/1", "2/1", "3/1"}; fmt.Sprint(pages) != fmt.Sprint(want) {
+		t.Fatalf("requested pages %v, want %v", pages, want)
+	}
+}
+
+func TestListStopsEarly(t *testing.T) {
+	var calls int
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		_, _ = w.Write([]byte(`{"data":[{"id":"a"},{"id":"b"}],"meta":{"page":1,"limit":2,"totalItems":10,"totalPages":5}}`))
+	}, Options{})
+
+	for model, err := range c.ListModels(context.Background(), ListOptions{Limit: 2}) {
+		if err != nil {
+			t.Fatalf("ListModels: %v", err)
+		}
+		if model.ID == "a" {
+			break
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("expected a single page request, got %d", calls)
+	}
+}
+
+func TestListYieldsError(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}, Options{})
+
+	if _, err := Collect(c.ListPrompts(context.Background(), "p1", ListOptions{})); !IsNotFound(err) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+package langfuse
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"net/http"
+)
+
+// CreateProject creates a project. Like all project management calls it
+// requires an organization-scoped API key.
+func (c *Client) CreateProject(ctx context.Context, project CreateProjectRequest) (*Project, error) {
+	return c.projectsCreate(ctx, project)
+}
+
+// GetProject returns a project by ID. Langfuse has no endpoint for a single
+// project, so it is looked up in the organization's project list. That list
+// does not include the retention, which is read with the key of the project
+// instead; it stays unset if the project has no key yet.
+func (c *Client) GetProject(ctx context.Context, id string) (*Project, error) {
+	for project, err := range c.ListProjects(ctx) {
+		if err != nil {
+			return nil, err
+		}
+		if project.ID == id {
+			retention, err := c.projectRetention(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			project.RetentionDays = retention
+			return &project, nil
+		}
+	}
+	return nil, &APIError{
+		StatusCode: http.StatusNotFound,
+		Method:     http.MethodGet,
+		Endpoint:   "/api/public/organizations/projects",
+		Message:    fmt.Sprintf("project %q not found", id),
+	}
+}
+
+// projectRetention returns the retention of project id, 0 if it has none,
+// or nil if there is no key to read it with.
+func (c *Client) projectRetention(ctx context.Context, id string) (*int, error) {
+	ctx, err := c.forProject(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !usesProjectKey(ctx) {
+		return nil, nil
+	}
+	resp, err := c.projectsGet(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range resp.Data {
+		if p.ID == id {
+			days := 0
+			if p.RetentionDays != nil {
+				days = *p.RetentionDays
+			}
+			return &days, nil
+		}
+	}
+	return nil, nil
+}
+
+// ListProjects lists the projects of the organization.
+func (c *Client) ListProjects(ctx context.Context) iter.Seq2[Project, error] {
+	return unpaginated(ctx, func(ctx context.Context) ([]Project, error) {
+		resp, err := c.organizationsGetOrganizationProjects(ctx)
+		if err != nil {
+			return nil, err
+		}
+		projects := make([]Project, 0, len(resp.Projects))
+		for _, p := range resp.Projects {
+			projects = append(projects, Project{ID: p.ID, Name: p.Name, Metadata: p.Metadata})
+		}
+		return projects, nil
+	})
+}
+
+// UpdateProject updates the name of a project and, if set, its metadata and
+// data retention.
+func (c *Client) UpdateProject(ctx context.Context, id string, project UpdateProjectRequest) (*Project, error) {
+	return c.projectsUpdate(ctx, id, project)
+}
+
+// DeleteProject deletes a project and all of its data. Langfuse processes
+// the deletion asynchronously.
+func (c *Client) DeleteProject(ctx context.Context, id string) error {
+	_, err := c.projectsDelete(ctx, id)
+	return err
+}
@@ -0,0 +1,76 @@
+package langfuse
+
+import (
+	"context"
+	"iter"
+	"net/url"
+	"strconv"
+)
+
+// GetPromptOptions selects the prompt version returned by GetPrompt.
+type GetPromptOptions struct {
+	Version int
+	Label   string
+}
+
+// CreatePrompt creates a new prompt, or a new version if a prompt with the
+// same name exists.
+func (c *Client) CreatePrompt(ctx context.Context, projectID string, prompt CreatePromptRequest) (*Prompt, error) {
+	ctx, err := c.forProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	return c.promptsCreate(ctx, prompt)
+}
+
+// GetPrompt returns one version of a prompt. Without a version or label
+// Langfuse returns the version labelled "production".
+func (c *Client) GetPrompt(ctx context.Context, projectID, name string, opts GetPromptOptions) (*Prompt, error) {
+	ctx, err := c.forProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	query := url.Values{}
+	if opts.Version > 0 {
+		query.Set("version", strconv.Itoa(opts.Version))
+	}
+	if opts.Label != "" {
+		query.Set("label", opts.Label)
+	}
+	return c.promptsGet(ctx, name, query)
+}
+
+// ListPrompts lists the prompts of a project, one entry per prompt name.
+func (c *Client) ListPrompts(ctx context.Context, projectID string, opts ListOptions) iter.Seq2[PromptMeta, error] {
+	return paginate(ctx, opts, func(ctx context.Context, query url.Values) ([]PromptMeta, PageMeta, error) {
+		ctx, err := c.forProject(ctx, projectID)
+		if err != nil {
+			return nil, PageMeta{}, err
+		}
+		page, err := c.promptsList(ctx, query)
+		if err != nil {
+			return nil, PageMeta{}, err
+		}
+		return page.Data, page.Meta, nil
+	})
+}
+
+// UpdatePromptLabels sets the labels of a prompt version. Labels are unique
+// per prompt, so they are removed from any other version.
+func (c *Client) UpdatePromptLabels(ctx context.Context, projectID, name string, version int,
+	labels []string) (*Prompt, error) {
+	ctx, err := c.forProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	return c.promptVersionUpdate(ctx, name, version, UpdatePromptVersionRequest{NewLabels: labels})
+}
+
+// DeletePrompt deletes all versions of a prompt.
+func (c *Client) DeletePrompt(ctx context.Context, projectID, name string) error {
+	ctx, err := c.forProject(ctx, projectID)
+	if err != nil {
+		return err
+	}
+	return c.promptsDelete(ctx, name, nil)
+}
@@ -0,0 +1,193 @@
+package langfuse
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultRateLimit is the sustained request rate, in requests per second,
+	// used when Options.RateLimit is not set.
+	DefaultRateLimit = 10
+	// DefaultRateBurst is the bucket size used when Options.RateBurst is not set.
+	DefaultRateBurst = 20
+)
+
+// Priority orders requests that wait for the rate limiter. Waiting requests
+// of a higher priority are always sent first.
+type Priority int
+
+const (
+	// PriorityLow is for bulk work such as syncing prompts.
+	PriorityLow Priority = iota
+	// PriorityNormal is the default for reads and writes.
+	PriorityNormal
+	// PriorityHigh is for deletes and calls whose result the status of
+	// other resources depends on.
+	PriorityHigh
+
+	numPriorities = int(PriorityHigh) + 1
+)
+
+// String returns the priority as used in metric labels.
+func (p Priority) String() string {
+	switch p {
+	case PriorityLow:
+		return "low"
+	case PriorityHigh:
+		return "high"
+	default:
+		return "normal"
+	}
+}
+
+type priorityKey struct{}
+
+// WithPriority returns a context whose Langfuse requests are rate limited
+// with priority p.
+func WithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+// requestPriority returns the priority set on ctx. Without one, deletes are
+// high priority and everything else is normal.
+func requestPriority(ctx context.Context, method string) Priority {
+	if p, ok := ctx.Value(priorityKey{}).(Priority); ok && p >= PriorityLow && p <= PriorityHigh {
+		return p
+	}
+	if method == http.MethodDelete {
+		return PriorityHigh
+	}
+	return PriorityNormal
+}
+
+// RateLimiter is a token bucket shared by all requests of a Client. When
+// requests have to wait, tokens are handed out by priority and then in
+// arrival order, so a backlog of low-priority calls cannot delay deletes.
+type RateLimiter struct {
+	rate  float64
+	burst float64
+
+	mu      sync.Mutex
+	tokens  float64
+	last    time.Time
+	waiters [numPriorities][]*waiter
+	timer   *time.Timer
+}
+
+type waiter struct {
+	ready   chan struct{}
+	granted bool
+}
+
+// NewRateLimiter returns a limiter that allows rate requests per second
+// with bursts of up to burst requests. The bucket starts full.
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Wait blocks until a request of priority p may be sent or ctx is done, and
+// returns how long it waited.
+func (l *RateLimiter) Wait(ctx context.Context, p Priority) (time.Duration, error) {
+	p = max(PriorityLow, min(p, PriorityHigh))
+	start := time.Now()
+	l.mu.Lock()
+	l.dispatch()
+	// dispatch leaves tokens only when nobody is queued, so taking one here
+	// cannot overtake an earlier request.
+	if l.tokens >= 1 {
+		l.tokens--
+		l.mu.Unlock()
+		return 0, nil
+	}
+	w := &waiter{ready: make(chan struct{})}
+	l.waiters[p] = append(l.waiters[p], w)
+	l.schedule()
+	l.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return time.Since(start), nil
+	case <-ctx.Done():
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if w.granted {
+		// The token arrived together with the cancellation; pass it on.
+		l.tokens = min(l.tokens+1, l.burst)
+		l.dispatch()
+	} else {
+		l.remove(p, w)
+	}
+	return time.Since(start), ctx.Err()
+}
+
+// refill adds the tokens accrued since the last call. Callers must hold l.mu.
+func (l *RateLimiter) refill() {
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+}
+
+// dispatch refills the bucket and hands tokens to queued requests, highest
+// priority first. Callers must hold l.mu.
+func (l *RateLimiter) dispatch() {
+	l.refill()
+	for p := numPriorities - 1; p >= 0; p-- {
+		for len(l.waiters[p]) > 0 && l.tokens >= 1 {
+			w := l.waiters[p][0]
+			l.waiters[p] = l.waiters[p][1:]
+			l.tokens--
+			w.granted = true
+			close(w.ready)
+		}
+	}
+}
+
+// schedule arms a timer for when the next token is due, if requests are
+// queued and no timer is pending. Callers must hold l.mu.
+func (l *RateLimiter) schedule() {
+	if l.timer != nil || l.queued() == 0 {
+		return
+	}
+	delay := time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+	l.timer = time.AfterFunc(delay, func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		l.timer = nil
+		l.dispatch()
+		l.schedule()
+	})
+}
+
+func (l *RateLimiter) queued() int {
+	n := 0
+	for _, q := range l.waiters {
+		n += len(q)
+	}
+	return n
+}
+
+func (l *RateLimiter) remove(p Priority, w *waiter) {
+	q := l.waiters[p]
+	for i := range q {
+		if q[i] == w {
+			l.waiters[p] = append(q[:i:i], q[i+1:]...)
+			return
+		}
+	}
+}
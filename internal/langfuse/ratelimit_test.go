@@ -0,0 +1,98 @@
+package langfuse
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterServesHigherPriorityFirst(t *testing.T) {
+	l := NewRateLimiter(20, 1)
+	if _, err := l.Wait(context.Background(), PriorityNormal); err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+
+	var (
+		mu    sync.Mutex
+		order []Priority
+		wg    sync.WaitGroup
+	)
+	queued := func(p Priority) int {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		return len(l.waiters[p])
+	}
+	start := func(p Priority) {
+		before := queued(p)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := l.Wait(context.Background(), p); err != nil {
+				t.Errorf("Wait: %v", err)
+			}
+			mu.Lock()
+			order = append(order, p)
+			mu.Unlock()
+		}()
+		// Wait until the request is queued so arrival order is deterministic.
+		for queued(p) == before {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	start(PriorityLow)
+	start(PriorityLow)
+	start(PriorityHigh)
+	wg.Wait()
+
+	if len(order) != 3 || order[0] != PriorityHigh {
+		t.Fatalf("expected the high priority request first, got %v", order)
+	}
+}
+
+func TestRateLimiterHonoursContext(t *testing.T) {
+	l := NewRateLimiter(0.001, 1)
+	if _, err := l.Wait(context.Background(), PriorityNormal); err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := l.Wait(ctx, PriorityHigh); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if n := l.queued(); n != 0 {
+		t.Fatalf("cancelled request is still queued (%d)", n)
+	}
+}
+
+func TestClientRateLimitsRequests(t *testing.T) {
+	var calls int
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusNoContent)
+	}, Options{RateLimit: 0.001, RateBurst: 2})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	for i := 0; i < 3; i++ {
+		_ = c.DeleteModel(ctx, "m1")
+	}
+	if calls != 2 {
+		t.Fatalf("expected the burst of 2 requests to pass, got %d", calls)
+	}
+}
+
+func TestRequestPriority(t *testing.T) {
+	ctx := context.Background()
+	if p := requestPriority(ctx, http.MethodDelete); p != PriorityHigh {
+		t.Fatalf("DELETE: got %v", p)
+	}
+	if p := requestPriority(ctx, http.MethodPost); p != PriorityNormal {
+		t.Fatalf("POST: got %v", p)
+	}
+	if p := requestPriority(WithPriority(ctx, PriorityLow), http.MethodDelete); p != PriorityLow {
+		t.Fatalf("explicit priority: got %v", p)
+	}
+}
@@ -0,0 +1,190 @@
+package langfuse
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// DefaultReadinessCacheTTL is how long a ReadinessChecker reuses the
+	// result of its last request when CacheTTL is not set.
+	DefaultReadinessCacheTTL = 30 * time.Second
+	// DefaultReadinessTimeout bounds a readiness request when Timeout is
+	// not set.
+	DefaultReadinessTimeout = 5 * time.Second
+)
+
+// readinessEndpoint is a cheap endpoint that needs the organization key.
+const readinessEndpoint = "/api/public/organizations/projects"
+
+// fallbackReadinessEndpoint checks the credentials on servers without the
+// organization API, which answer readinessEndpoint with 404.
+const fallbackReadinessEndpoint = "/api/public/projects"
+
+// errReadinessPending is returned while the first readiness check waits for
+// Langfuse.
+var errReadinessPending = errors.New("langfuse readiness check in progress")
+
+// ReadinessFailure classifies why Langfuse was found not ready.
+type ReadinessFailure string
+
+const (
+	// FailureDNS means the Langfuse host name could not be resolved.
+	FailureDNS ReadinessFailure = "DNSFailure"
+	// FailureTLS means the TLS handshake failed, e.g. on an untrusted or
+	// expired certificate.
+	FailureTLS ReadinessFailure = "TLSFailure"
+	// FailureConnection means the connection could not be established or
+	// broke, or timed out.
+	FailureConnection ReadinessFailure = "ConnectionFailure"
+	// FailureAuthentication means Langfuse rejected the credentials.
+	FailureAuthentication ReadinessFailure = "AuthenticationFailed"
+	// FailureServer means Langfuse answered with an unexpected status, such
+	// as a 5xx.
+	FailureServer ReadinessFailure = "ServerError"
+)
+
+// ReadinessError is returned by ReadinessChecker.Check when Langfuse is
+// not usable.
+type ReadinessError struct {
+	Failure ReadinessFailure
+	Err     error
+}
+
+func (e *ReadinessError) Error() string {
+	return fmt.Sprintf("langfuse not ready (%s): %v", e.Failure, e.Err)
+}
+
+func (e *ReadinessError) Unwrap() error {
+	return e.Err
+}
+
+// ReadinessChecker is a controller-runtime healthz.Checker that fails
+// unless Langfuse can be reached and accepts the client's credentials. It
+// sends a single request, without retries, rate limiting or the circuit
+// breaker, at most once per CacheTTL; probes in between, and probes while
+// the request is in flight, get the cached result.
+type ReadinessChecker struct {
+	Client *Client
+	// CacheTTL defaults to DefaultReadinessCacheTTL.
+	CacheTTL time.Duration
+	// Timeout defaults to DefaultReadinessTimeout.
+	Timeout time.Duration
+
+	mu        sync.Mutex
+	checking  bool
+	checkedAt time.Time
+	last      error
+}
+
+// Check implements healthz.Checker.
+func (r *ReadinessChecker) Check(req *http.Request) error {
+	ttl := r.CacheTTL
+	if ttl <= 0 {
+		ttl = DefaultReadinessCacheTTL
+	}
+	r.mu.Lock()
+	if r.checking || !r.checkedAt.IsZero() && time.Since(r.checkedAt) < ttl {
+		last := r.last
+		if r.checkedAt.IsZero() {
+			last = errReadinessPending
+		}
+		r.mu.Unlock()
+		return last
+	}
+	r.checking = true
+	r.mu.Unlock()
+
+	ctx := context.Background()
+	if req != nil {
+		ctx = req.Context()
+	}
+	err := r.check(ctx)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checking = false
+	if (err == nil) != (r.last == nil) || r.checkedAt.IsZero() {
+		log := logf.Log.WithName("langfuse-readiness")
+		if err != nil {
+			log.Error(err, "Langfuse is not ready", "host", r.Client.BaseURL)
+		} else {
+			log.Info("Langfuse is ready", "host", r.Client.BaseURL)
+		}
+	}
+	r.checkedAt, r.last = time.Now(), err
+	return err
+}
+
+func (r *ReadinessChecker) check(ctx context.Context) error {
+	timeout := r.Timeout
+	if timeout <= 0 {
+		timeout = DefaultReadinessTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return r.Client.Ready(ctx)
+}
+
+// Ready sends a single request, without retries, rate limiting or the
+// circuit breaker, and returns a *ReadinessError unless Langfuse can be
+// reached and accepts the client's credentials. Servers without the
+// organization API get a second request to fallbackReadinessEndpoint.
+func (c *Client) Ready(ctx context.Context) error {
+	err := c.readyAt(ctx, readinessEndpoint)
+	if IsNotFound(err) {
+		err = c.readyAt(ctx, fallbackReadinessEndpoint)
+	}
+	return err
+}
+
+// readyAt checks the answer of endpoint to the client's credentials.
+func (c *Client) readyAt(ctx context.Context, endpoint string) error {
+	resp, body, err := c.send(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return &ReadinessError{Failure: classifyTransportError(err), Err: err}
+	}
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return &ReadinessError{
+			Failure: FailureAuthentication,
+			Err:     newAPIError(http.MethodGet, endpoint, resp.StatusCode, body),
+		}
+	case resp.StatusCode < 400:
+		return nil
+	default:
+		return &ReadinessError{
+			Failure: FailureServer,
+			Err:     newAPIError(http.MethodGet, endpoint, resp.StatusCode, body),
+		}
+	}
+}
+
+// classifyTransportError tells DNS and TLS failures apart from other
+// failures to get a response.
+func classifyTransportError(err error) ReadinessFailure {
+	var dnsErr *net.DNSError
+	var recordErr tls.RecordHeaderError
+	var verifyErr *tls.CertificateVerificationError
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var invalidErr x509.CertificateInvalidError
+	switch {
+	case errors.As(err, &dnsErr):
+		return FailureDNS
+	case errors.As(err, &recordErr), errors.As(err, &verifyErr), errors.As(err, &unknownAuthority),
+		errors.As(err, &hostnameErr), errors.As(err, &invalidErr):
+		return FailureTLS
+	default:
+		return FailureConnection
+	}
+}
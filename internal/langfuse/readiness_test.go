@@ -0,0 +1,128 @@
+package langfuse
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func readinessFailure(err error) ReadinessFailure {
+	var readinessErr *ReadinessError
+	if errors.As(err, &readinessErr) {
+		return readinessErr.Failure
+	}
+	return ""
+}
+
+func TestReadinessCheckerClassifiesFailures(t *testing.T) {
+	var status, fallbackStatus atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case readinessEndpoint:
+			w.WriteHeader(int(status.Load()))
+		case fallbackReadinessEndpoint:
+			w.WriteHeader(int(fallbackStatus.Load()))
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+	}, Options{})
+
+	for _, tc := range []struct {
+		status, fallbackStatus int
+		want                   ReadinessFailure
+	}{
+		{http.StatusOK, 0, ""},
+		{http.StatusUnauthorized, 0, FailureAuthentication},
+		{http.StatusForbidden, 0, FailureAuthentication},
+		{http.StatusBadGateway, 0, FailureServer},
+		// Servers without the organization API are checked with the
+		// projects endpoint.
+		{http.StatusNotFound, http.StatusOK, ""},
+		{http.StatusNotFound, http.StatusUnauthorized, FailureAuthentication},
+		{http.StatusNotFound, http.StatusNotFound, FailureServer},
+	} {
+		status.Store(int32(tc.status))
+		fallbackStatus.Store(int32(tc.fallbackStatus))
+		err := (&ReadinessChecker{Client: c}).Check(nil)
+		if got := readinessFailure(err); got != tc.want || (tc.want == "") != (err == nil) {
+			t.Errorf("status %d, fallback %d: got %v, want %q", tc.status, tc.fallbackStatus, err, tc.want)
+		}
+	}
+}
+
+func TestReadinessCheckerTransportFailures(t *testing.T) {
+	c := NewClient(Options{BaseURL: "http://langfuse.invalid", PublicKey: "pk", SecretKey: "sk"})
+	if got := readinessFailure((&ReadinessChecker{Client: c}).Check(nil)); got != FailureDNS {
+		t.Errorf("unresolvable host: got %q, want %q", got, FailureDNS)
+	}
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	c = NewClient(Options{BaseURL: srv.URL, PublicKey: "pk", SecretKey: "sk"})
+	if got := readinessFailure((&ReadinessChecker{Client: c}).Check(nil)); got != FailureTLS {
+		t.Errorf("untrusted certificate: got %q, want %q", got, FailureTLS)
+	}
+
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer slow.Close()
+	c = NewClient(Options{BaseURL: slow.URL, PublicKey: "pk", SecretKey: "sk"})
+	checker := &ReadinessChecker{Client: c, Timeout: 10 * time.Millisecond}
+	if got := readinessFailure(checker.Check(nil)); got != FailureConnection {
+		t.Errorf("timeout: got %q, want %q", got, FailureConnection)
+	}
+}
+
+func TestReadinessCheckerCachesResults(t *testing.T) {
+	var calls atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusUnauthorized)
+	}, Options{})
+	checker := &ReadinessChecker{Client: c, CacheTTL: time.Hour}
+
+	for range 3 {
+		if err := checker.Check(httptest.NewRequest(http.MethodGet, "/readyz", nil).WithContext(context.Background())); err == nil {
+			t.Fatal("expected an error")
+		}
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("requests: got %d, want 1", n)
+	}
+}
+
+func TestReadinessCheckerChecksOutsideTheLock(t *testing.T) {
+	release := make(chan struct{})
+	var calls atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		<-release
+	}, Options{})
+	checker := &ReadinessChecker{Client: c, CacheTTL: time.Hour}
+
+	done := make(chan error)
+	go func() { done <- checker.Check(nil) }()
+	for calls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	// A probe arriving while the first check waits for Langfuse gets an
+	// answer right away and sends no request of its own.
+	if err := checker.Check(nil); err == nil {
+		t.Error("expected an error before the first check finished")
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Errorf("first check: %v", err)
+	}
+	if err := checker.Check(nil); err != nil {
+		t.Errorf("cached check: %v", err)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("requests: got %d, want 1", n)
+	}
+}
@@ -0,0 +1,129 @@
+package langfuse
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// DefaultMaxRetries is the number of retries after the first attempt.
+	DefaultMaxRetries = 4
+	// DefaultRetryWaitMin is the base delay of the exponential backoff.
+	DefaultRetryWaitMin = 500 * time.Millisecond
+	// DefaultRetryWaitMax caps a single backoff delay.
+	DefaultRetryWaitMax = 30 * time.Second
+)
+
+// retryableStatus reports whether a response status is transient.
+func retryableStatus(code int) bool {
+	switch code {
+	case http.StatusRequestTimeout,
+		http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// idempotent reports whether repeating a request with this method is safe
+// even if the server may already have processed it.
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// shouldRetry decides whether an attempt that ended with status code (or
+// err, when the request did not complete) may be repeated. Non-idempotent
+// requests are only retried when the server has certainly not acted on
+// them: the connection was never established, or the request was rejected
+// by rate limiting.
+func shouldRetry(ctx context.Context, method string, code int, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			// The per-request timeout fired; the server may still be working on it.
+			return idempotent(method)
+		}
+		var opErr *net.OpError
+		if errors.As(err, &opErr) && opErr.Op == "dial" {
+			return true
+		}
+		return idempotent(method)
+	}
+	if code == http.StatusTooManyRequests {
+		return true
+	}
+	return retryableStatus(code) && idempotent(method)
+}
+
+// backoff returns the delay before retry number attempt (starting at 0).
+// A Retry-After header on the previous response takes precedence over the
+// computed exponential delay.
+func (c *Client) backoff(attempt int, header http.Header) time.Duration {
+	if d, ok := parseRetryAfter(header, time.Now()); ok {
+		return d
+	}
+	d := c.RetryWaitMax
+	if attempt < 32 {
+		if exp := c.RetryWaitMin << uint(attempt); exp > 0 && exp < c.RetryWaitMax {
+			d = exp
+		}
+	}
+	// Equal jitter: keep half of the delay and randomise the rest so that
+	// reconcilers hitting the same limit do not retry in lockstep.
+	half := d / 2
+	return half + rand.N(half+1)
+}
+
+// parseRetryAfter reads a Retry-After header in either delay-seconds or
+// HTTP-date form.
+func parseRetryAfter(header http.Header, now time.Time) (time.Duration, bool) {
+	v := header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		d := t.Sub(now)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+// sleep waits for d or until ctx is done. It returns false if the wait
+// would outlive the context's deadline, so callers can fail fast instead
+// of sleeping into a timeout.
+func sleep(ctx context.Context, d time.Duration) bool {
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < d {
+		return false
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
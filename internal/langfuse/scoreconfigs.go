@@ -0,0 +1,52 @@
+package langfuse
+
+import (
+	"context"
+	"iter"
+	"net/url"
+)
+
+// CreateScoreConfig creates a new score configuration
+func (c *Client) CreateScoreConfig(ctx context.Context, projectID string,
+	config CreateScoreConfigRequest) (*ScoreConfig, error) {
+	ctx, err := c.forProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	return c.scoreConfigsCreate(ctx, config)
+}
+
+// GetScoreConfig returns a score configuration by ID.
+func (c *Client) GetScoreConfig(ctx context.Context, projectID, id string) (*ScoreConfig, error) {
+	ctx, err := c.forProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	return c.scoreConfigsGetByID(ctx, id)
+}
+
+// ListScoreConfigs lists the score configurations of a project.
+func (c *Client) ListScoreConfigs(ctx context.Context, projectID string, opts ListOptions) iter.Seq2[ScoreConfig, error] {
+	return paginate(ctx, opts, func(ctx context.Context, query url.Values) ([]ScoreConfig, PageMeta, error) {
+		ctx, err := c.forProject(ctx, projectID)
+		if err != nil {
+			return nil, PageMeta{}, err
+		}
+		page, err := c.scoreConfigsGet(ctx, query)
+		if err != nil {
+			return nil, PageMeta{}, err
+		}
+		return page.Data, page.Meta, nil
+	})
+}
+
+// UpdateScoreConfig updates a score configuration. Langfuse does not delete
+// score configs; set IsArchived to retire one.
+func (c *Client) UpdateScoreConfig(ctx context.Context, projectID, id string,
+	config UpdateScoreConfigRequest) (*ScoreConfig, error) {
+	ctx, err := c.forProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	return c.scoreConfigsUpdate(ctx, id, config)
+}
@@ -0,0 +1,39 @@
+package langfuse
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/sqaisar/langfuse-controller/internal/langfuse")
+
+// startSpan starts a client span for one Client.do call, covering rate
+// limiting and all retries.
+func (c *Client) startSpan(ctx context.Context, method, endpoint string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, method+" "+endpoint,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", method),
+			attribute.String("url.template", endpoint),
+			attribute.String("server.address", c.BaseURL),
+		))
+}
+
+// endSpan records the final status code and error of a Client.do call.
+func endSpan(span trace.Span, attempts, status int, err error) {
+	if attempts > 1 {
+		span.SetAttributes(attribute.Int("http.request.resend_count", attempts-1))
+	}
+	if status != 0 {
+		span.SetAttributes(attribute.Int("http.response.status_code", status))
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
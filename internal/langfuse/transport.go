@@ -0,0 +1,118 @@
+package langfuse
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// TransportOptions configures how the client connects to Langfuse. Files
+// are typically mounted from Secrets.
+type TransportOptions struct {
+	// CAFile is a PEM bundle of CAs trusted in addition to the system pool,
+	// for Langfuse instances behind a private PKI.
+	CAFile string
+	// CertFile and KeyFile are a PEM client certificate and key presented
+	// for mutual TLS. They are re-read on every TLS handshake, so a rotated
+	// Secret is picked up without a restart.
+	CertFile string
+	KeyFile  string
+	// CAData, CertData and KeyData are like CAFile, CertFile and KeyFile
+	// but hold the PEM data itself, e.g. read from a Secret through the
+	// API. They are used as given; build a new transport to rotate them.
+	CAData   []byte
+	CertData []byte
+	KeyData  []byte
+	// InsecureSkipVerify disables server certificate verification. Only
+	// use it in development.
+	InsecureSkipVerify bool
+	// ProxyURL is the proxy used for Langfuse requests. Defaults to the
+	// HTTPS_PROXY and HTTP_PROXY environment variables.
+	ProxyURL string
+	// NoProxy is a comma-separated list of hosts, domains and CIDRs that
+	// bypass the proxy. Defaults to the NO_PROXY environment variable.
+	NoProxy string
+}
+
+// NewTransport returns an HTTP transport configured by opts.
+func NewTransport(opts TransportOptions) (*http.Transport, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: opts.InsecureSkipVerify, //nolint:gosec // opt-in for development
+	}
+
+	if opts.CAFile != "" || len(opts.CAData) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if opts.CAFile != "" {
+			pem, err := os.ReadFile(opts.CAFile)
+			if err != nil {
+				return nil, fmt.Errorf("reading CA bundle: %w", err)
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("no certificates found in CA bundle %s", opts.CAFile)
+			}
+		}
+		if len(opts.CAData) > 0 && !pool.AppendCertsFromPEM(opts.CAData) {
+			return nil, errors.New("no certificates found in CA data")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if (opts.CertFile == "") != (opts.KeyFile == "") || (len(opts.CertData) == 0) != (len(opts.KeyData) == 0) {
+		return nil, errors.New("client certificate and key must be set together")
+	}
+	if opts.CertFile != "" && len(opts.CertData) > 0 {
+		return nil, errors.New("client certificate must be set either as files or as data")
+	}
+	if len(opts.CertData) > 0 {
+		cert, err := tls.X509KeyPair(opts.CertData, opts.KeyData)
+		if err != nil {
+			return nil, fmt.Errorf("loading client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	if opts.CertFile != "" {
+		// Fail fast on a bad key pair instead of at the first request.
+		if _, err := tls.LoadX509KeyPair(opts.CertFile, opts.KeyFile); err != nil {
+			return nil, fmt.Errorf("loading client certificate: %w", err)
+		}
+		tlsConfig.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			cert, err := tls.LoadX509KeyPair(opts.CertFile, opts.KeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("loading client certificate: %w", err)
+			}
+			return &cert, nil
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	transport.Proxy = proxyFunc(opts)
+	return transport, nil
+}
+
+// proxyFunc returns the proxy selector for opts, falling back to the
+// environment for unset fields.
+func proxyFunc(opts TransportOptions) func(*http.Request) (*url.URL, error) {
+	cfg := httpproxy.FromEnvironment()
+	if opts.ProxyURL != "" {
+		cfg.HTTPSProxy = opts.ProxyURL
+		cfg.HTTPProxy = opts.ProxyURL
+	}
+	if opts.NoProxy != "" {
+		cfg.NoProxy = opts.NoProxy
+	}
+	proxy := cfg.ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return proxy(req.URL)
+	}
+}
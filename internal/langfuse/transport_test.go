@@ -0,0 +1,98 @@
+package langfuse
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTransportTrustsCABundle(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"OK","version":"3.0.0"}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	caFile := filepath.Join(t.TempDir(), "ca.crt")
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(caFile, ca, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	untrusted := NewClient(Options{BaseURL: srv.URL, MaxRetries: -1})
+	if _, err := untrusted.Health(context.Background()); err == nil {
+		t.Fatal("expected a certificate error without the CA bundle")
+	}
+
+	transport, err := NewTransport(TransportOptions{CAFile: caFile})
+	if err != nil {
+		t.Fatalf("NewTransport: %v", err)
+	}
+	trusted := NewClient(Options{BaseURL: srv.URL, Transport: transport})
+	if _, err := trusted.Health(context.Background()); err != nil {
+		t.Fatalf("Health: %v", err)
+	}
+}
+
+func TestTransportTrustsCAData(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"OK","version":"3.0.0"}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	transport, err := NewTransport(TransportOptions{CAData: ca})
+	if err != nil {
+		t.Fatalf("NewTransport: %v", err)
+	}
+	c := NewClient(Options{BaseURL: srv.URL, Transport: transport})
+	if _, err := c.Health(context.Background()); err != nil {
+		t.Fatalf("Health: %v", err)
+	}
+
+	if _, err := NewTransport(TransportOptions{CAData: []byte("not a certificate")}); err == nil {
+		t.Fatal("expected an error for CA data without certificates")
+	}
+}
+
+func TestTransportRejectsIncompleteClientCertificate(t *testing.T) {
+	if _, err := NewTransport(TransportOptions{CertFile: "tls.crt"}); err == nil {
+		t.Fatal("expected an error for a certificate without key")
+	}
+	if _, err := NewTransport(TransportOptions{KeyData: []byte("key")}); err == nil {
+		t.Fatal("expected an error for a key without certificate")
+	}
+}
+
+func TestTransportProxy(t *testing.T) {
+	proxy := proxyFunc(TransportOptions{
+		ProxyURL: "http://proxy.internal:3128",
+		NoProxy:  "langfuse.svc.cluster.local",
+	})
+	for target, want := range map[string]string{
+		"https://cloud.langfuse.com/api/public/health":         "http://proxy.internal:3128",
+		"https://langfuse.svc.cluster.local/api/public/health": "",
+	} {
+		req := &http.Request{URL: mustParse(t, target)}
+		got, err := proxy(req)
+		if err != nil {
+			t.Fatalf("proxy(%s): %v", target, err)
+		}
+		if (got == nil && want != "") || (got != nil && got.String() != want) {
+			t.Errorf("proxy(%s) = %v, want %q", target, got, want)
+		}
+	}
+}
+
+func mustParse(t *testing.T, raw string) *url.URL {
+	t.Helper()
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
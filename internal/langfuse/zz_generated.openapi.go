@@ -0,0 +1,699 @@
+// Code generated by hack/langfuse-gen from openapi/langfuse.yml. DO NOT EDIT.
+
+package langfuse
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// APIKey is the Langfuse ApiKeyResponse schema.
+//
+// Response for API key creation.
+type APIKey struct {
+	CreatedAt        string `json:"createdAt"`
+	DisplaySecretKey string `json:"displaySecretKey"`
+	ID               string `json:"id"`
+	Note             string `json:"note,omitempty"`
+	PublicKey        string `json:"publicKey"`
+	SecretKey        string `json:"secretKey"`
+}
+
+// APIKeyDeletionResponse is the Langfuse ApiKeyDeletionResponse schema.
+//
+// Response for API key deletion.
+type APIKeyDeletionResponse struct {
+	Success bool `json:"success"`
+}
+
+// APIKeyList is the Langfuse ApiKeyList schema.
+//
+// List of API keys for a project.
+type APIKeyList struct {
+	APIKeys []APIKeySummary `json:"apiKeys"`
+}
+
+// APIKeySummary is the Langfuse ApiKeySummary schema.
+//
+// Summary of an API key.
+type APIKeySummary struct {
+	CreatedAt        string `json:"createdAt"`
+	DisplaySecretKey string `json:"displaySecretKey"`
+	ExpiresAt        string `json:"expiresAt,omitempty"`
+	ID               string `json:"id"`
+	LastUsedAt       string `json:"lastUsedAt,omitempty"`
+	Note             string `json:"note,omitempty"`
+	PublicKey        string `json:"publicKey"`
+}
+
+// CreateAPIKeyRequest is the Langfuse CreateApiKeyRequest schema.
+type CreateAPIKeyRequest struct {
+	// Optional note for the API key.
+	Note string `json:"note,omitempty"`
+}
+
+// CreateModelRequest is the Langfuse CreateModelRequest schema.
+type CreateModelRequest struct {
+	// Price (USD) per input unit.
+	InputPrice *float64 `json:"inputPrice,omitempty"`
+	// Regex pattern which matches this model definition to generation.model.
+	// Useful in case of fine-tuned models.
+	MatchPattern string `json:"matchPattern"`
+	// Name of the model definition. If multiple with the same name exist, they are
+	// applied in the following order: (1) custom over built-in, (2) newest
+	// according to startTime where model.startTime<observation.startTime.
+	ModelName string `json:"modelName"`
+	// Price (USD) per output unit.
+	OutputPrice *float64 `json:"outputPrice,omitempty"`
+	// Apply only to generations which are newer than this ISO date.
+	StartDate string `json:"startDate,omitempty"`
+	// Optional. Configuration for the selected tokenizer. Needs to be JSON. See
+	// docs for more details.
+	TokenizerConfig interface{} `json:"tokenizerConfig,omitempty"`
+	// Optional. Tokenizer to be applied to observations which match to this model.
+	// See docs for more details.
+	TokenizerID string `json:"tokenizerId,omitempty"`
+	// Price (USD) per total unit. Cannot be set if input or output price is set.
+	TotalPrice *float64 `json:"totalPrice,omitempty"`
+	// Unit used by this model.
+	Unit string `json:"unit,omitempty"`
+}
+
+// CreateProjectRequest is the Langfuse CreateProjectRequest schema.
+type CreateProjectRequest struct {
+	// Optional metadata for the project.
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+	Name     string                 `json:"name"`
+	// Number of days to retain data. Must be 0 or at least 3 days. Requires
+	// data-retention entitlement for non-zero values. Optional.
+	Retention int `json:"retention"`
+}
+
+// CreatePromptRequest is the Langfuse CreatePromptRequest schema.
+type CreatePromptRequest struct {
+	// Commit message for this prompt version.
+	CommitMessage string      `json:"commitMessage,omitempty"`
+	Config        interface{} `json:"config,omitempty"`
+	// List of deployment labels of this prompt version.
+	Labels []string `json:"labels,omitempty"`
+	Name   string   `json:"name"`
+	// The prompt text or chat messages.
+	Prompt interface{} `json:"prompt"`
+	// List of tags to apply to all versions of this prompt.
+	Tags []string `json:"tags,omitempty"`
+	Type string   `json:"type"`
+}
+
+// CreateScoreConfigRequest is the Langfuse CreateScoreConfigRequest schema.
+type CreateScoreConfigRequest struct {
+	// Configure custom categories for categorical scores. Pass a list of objects
+	// with `label` and `value` properties. Categories are autogenerated for
+	// boolean configs and cannot be passed.
+	Categories []ScoreConfigCategory `json:"categories,omitempty"`
+	DataType   string                `json:"dataType"`
+	// Description is shown across the Langfuse UI and can be used to e.g. explain
+	// the config categories in detail, why a numeric range was set, or provide
+	// additional context on config name or usage.
+	Description string `json:"description,omitempty"`
+	// Configure a maximum value for numerical scores. If not set, the maximum
+	// value defaults to +∞.
+	MaxValue *float64 `json:"maxValue,omitempty"`
+	// Configure a minimum value for numerical scores. If not set, the minimum
+	// value defaults to -∞.
+	MinValue *float64 `json:"minValue,omitempty"`
+	Name     string   `json:"name"`
+}
+
+// HealthResponse is the Langfuse HealthResponse schema.
+type HealthResponse struct {
+	Status string `json:"status"`
+	// Langfuse server version.
+	Version string `json:"version"`
+}
+
+// LlmConnection is the Langfuse LlmConnection schema.
+//
+// LLM API connection configuration (secrets excluded).
+type LlmConnection struct {
+	// The adapter used to interface with the LLM.
+	Adapter string `json:"adapter"`
+	// Custom base URL for the LLM API.
+	BaseURL   string `json:"baseURL,omitempty"`
+	CreatedAt string `json:"createdAt"`
+	// List of custom model names available for this connection.
+	CustomModels []string `json:"customModels"`
+	// Masked version of the secret key for display purposes.
+	DisplaySecretKey string `json:"displaySecretKey"`
+	// Keys of extra headers sent with requests (values excluded for security).
+	ExtraHeaderKeys []string `json:"extraHeaderKeys"`
+	ID              string   `json:"id"`
+	// Provider name (e.g., 'openai', 'my-gateway'). Must be unique in project,
+	// used for upserting.
+	Provider  string `json:"provider"`
+	UpdatedAt string `json:"updatedAt"`
+	// Whether to include default models for this adapter.
+	WithDefaultModels bool `json:"withDefaultModels"`
+}
+
+// Model is the Langfuse Model schema.
+//
+// Model definition used for transforming usage into USD cost and/or
+// tokenization.
+type Model struct {
+	ID string `json:"id"`
+	// Price (USD) per input unit.
+	InputPrice        *float64 `json:"inputPrice,omitempty"`
+	IsLangfuseManaged bool     `json:"isLangfuseManaged"`
+	// Regex pattern which matches this model definition to generation.model.
+	// Useful in case of fine-tuned models.
+	MatchPattern string `json:"matchPattern"`
+	// Name of the model definition. If multiple with the same name exist, they are
+	// applied in the following order: (1) custom over built-in, (2) newest
+	// according to startTime where model.startTime<observation.startTime.
+	ModelName string `json:"modelName"`
+	// Price (USD) per output unit.
+	OutputPrice *float64 `json:"outputPrice,omitempty"`
+	// Apply only to generations which are newer than this ISO date.
+	StartDate string `json:"startDate,omitempty"`
+	// Optional. Configuration for the selected tokenizer. Needs to be JSON. See
+	// docs for more details.
+	TokenizerConfig interface{} `json:"tokenizerConfig,omitempty"`
+	// Optional. Tokenizer to be applied to observations which match to this model.
+	// See docs for more details.
+	TokenizerID string `json:"tokenizerId,omitempty"`
+	// Price (USD) per total unit. Cannot be set if input or output price is set.
+	TotalPrice *float64 `json:"totalPrice,omitempty"`
+	// Unit used by this model.
+	Unit string `json:"unit,omitempty"`
+}
+
+// OrganizationProject is the Langfuse OrganizationProject schema.
+type OrganizationProject struct {
+	CreatedAt string                 `json:"createdAt"`
+	ID        string                 `json:"id"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+	Name      string                 `json:"name"`
+	UpdatedAt string                 `json:"updatedAt"`
+}
+
+// OrganizationProjectsResponse is the Langfuse OrganizationProjectsResponse schema.
+type OrganizationProjectsResponse struct {
+	Projects []OrganizationProject `json:"projects"`
+}
+
+// PageMeta is the Langfuse utilsMetaResponse schema.
+type PageMeta struct {
+	// number of items per page.
+	Limit int `json:"limit"`
+	// current page number.
+	Page int `json:"page"`
+	// number of total items given the current filters/selection (if any).
+	TotalItems int `json:"totalItems"`
+	// number of total pages given the current limit.
+	TotalPages int `json:"totalPages"`
+}
+
+// PaginatedLlmConnections is the Langfuse PaginatedLlmConnections schema.
+type PaginatedLlmConnections struct {
+	Data []LlmConnection `json:"data"`
+	Meta PageMeta        `json:"meta"`
+}
+
+// PaginatedModels is the Langfuse PaginatedModels schema.
+type PaginatedModels struct {
+	Data []Model  `json:"data"`
+	Meta PageMeta `json:"meta"`
+}
+
+// Project is the Langfuse Project schema.
+type Project struct {
+	ID string `json:"id"`
+	// Metadata for the project.
+	Metadata map[string]interface{} `json:"metadata"`
+	Name     string                 `json:"name"`
+	// Number of days to retain data. Null or 0 means no retention. Omitted if no
+	// retention is configured.
+	RetentionDays *int `json:"retentionDays,omitempty"`
+}
+
+// ProjectDeletionResponse is the Langfuse ProjectDeletionResponse schema.
+type ProjectDeletionResponse struct {
+	Message string `json:"message"`
+	Success bool   `json:"success"`
+}
+
+// Projects is the Langfuse Projects schema.
+type Projects struct {
+	Data []Project `json:"data"`
+}
+
+// Prompt is the Langfuse Prompt schema.
+//
+// A prompt version. `prompt` is a string for text prompts and a list of chat
+// messages for chat prompts.
+type Prompt struct {
+	// Commit message for this prompt version.
+	CommitMessage string      `json:"commitMessage,omitempty"`
+	Config        interface{} `json:"config"`
+	// List of deployment labels of this prompt version.
+	Labels []string `json:"labels"`
+	Name   string   `json:"name"`
+	// The prompt text or chat messages.
+	Prompt interface{} `json:"prompt"`
+	// List of tags. Used to filter via UI and API. The same across versions of a
+	// prompt.
+	Tags    []string `json:"tags"`
+	Type    string   `json:"type"`
+	Version int      `json:"version"`
+}
+
+// PromptMeta is the Langfuse PromptMeta schema.
+type PromptMeta struct {
+	Labels []string `json:"labels"`
+	// Config object of the most recent prompt version that matches the filters (if
+	// any are provided).
+	LastConfig    interface{} `json:"lastConfig"`
+	LastUpdatedAt string      `json:"lastUpdatedAt"`
+	Name          string      `json:"name"`
+	Tags          []string    `json:"tags"`
+	Type          string      `json:"type,omitempty"`
+	Versions      []int       `json:"versions"`
+}
+
+// PromptMetaListResponse is the Langfuse PromptMetaListResponse schema.
+type PromptMetaListResponse struct {
+	Data []PromptMeta `json:"data"`
+	Meta PageMeta     `json:"meta"`
+}
+
+// ScoreConfig is the Langfuse ScoreConfig schema.
+//
+// Configuration for a score.
+type ScoreConfig struct {
+	// Configures custom categories for categorical scores.
+	Categories []ScoreConfigCategory `json:"categories,omitempty"`
+	CreatedAt  string                `json:"createdAt"`
+	DataType   string                `json:"dataType"`
+	// Description of the score config.
+	Description string `json:"description,omitempty"`
+	ID          string `json:"id"`
+	// Whether the score config is archived. Defaults to false.
+	IsArchived bool `json:"isArchived"`
+	// Sets maximum value for numerical scores. If not set, the maximum value
+	// defaults to +∞.
+	MaxValue *float64 `json:"maxValue,omitempty"`
+	// Sets minimum value for numerical scores. If not set, the minimum value
+	// defaults to -∞.
+	MinValue  *float64 `json:"minValue,omitempty"`
+	Name      string   `json:"name"`
+	ProjectID string   `json:"projectId"`
+	UpdatedAt string   `json:"updatedAt"`
+}
+
+// ScoreConfigCategory is the Langfuse ConfigCategory schema.
+type ScoreConfigCategory struct {
+	Label string  `json:"label"`
+	Value float64 `json:"value"`
+}
+
+// ScoreConfigs is the Langfuse ScoreConfigs schema.
+type ScoreConfigs struct {
+	Data []ScoreConfig `json:"data"`
+	Meta PageMeta      `json:"meta"`
+}
+
+// UpdateProjectRequest is the Langfuse UpdateProjectRequest schema.
+type UpdateProjectRequest struct {
+	// Optional metadata for the project.
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+	Name     string                 `json:"name"`
+	// Number of days to retain data. Must be 0 or at least 3 days. Requires
+	// data-retention entitlement for non-zero values. Optional.
+	Retention *int `json:"retention,omitempty"`
+}
+
+// UpdatePromptVersionRequest is the Langfuse UpdatePromptVersionRequest schema.
+type UpdatePromptVersionRequest struct {
+	// New labels for the prompt version. Labels are unique across versions. The
+	// "latest" label is reserved and managed by Langfuse.
+	NewLabels []string `json:"newLabels"`
+}
+
+// UpdateScoreConfigRequest is the Langfuse UpdateScoreConfigRequest schema.
+type UpdateScoreConfigRequest struct {
+	// Configure custom categories for categorical scores. Pass a list of objects
+	// with `label` and `value` properties. Categories are autogenerated for
+	// boolean configs and cannot be passed.
+	Categories []ScoreConfigCategory `json:"categories,omitempty"`
+	// Description is shown across the Langfuse UI and can be used to e.g. explain
+	// the config categories in detail, why a numeric range was set, or provide
+	// additional context on config name or usage.
+	Description string `json:"description,omitempty"`
+	// The status of the score config showing if it is archived or not.
+	IsArchived *bool `json:"isArchived,omitempty"`
+	// Configure a maximum value for numerical scores. If not set, the maximum
+	// value defaults to +∞.
+	MaxValue *float64 `json:"maxValue,omitempty"`
+	// Configure a minimum value for numerical scores. If not set, the minimum
+	// value defaults to -∞.
+	MinValue *float64 `json:"minValue,omitempty"`
+	// The name of the score config.
+	Name string `json:"name,omitempty"`
+}
+
+// UpsertLlmConnectionRequest is the Langfuse UpsertLlmConnectionRequest schema.
+//
+// Request to create or update an LLM connection (upsert).
+type UpsertLlmConnectionRequest struct {
+	// The adapter used to interface with the LLM.
+	Adapter string `json:"adapter"`
+	// Custom base URL for the LLM API.
+	BaseURL string `json:"baseURL,omitempty"`
+	// List of custom model names.
+	CustomModels []string `json:"customModels,omitempty"`
+	// Extra headers to send with requests.
+	ExtraHeaders map[string]string `json:"extraHeaders,omitempty"`
+	// Provider name (e.g., 'openai', 'my-gateway'). Must be unique in project,
+	// used for upserting.
+	Provider string `json:"provider"`
+	// Secret key for the LLM API.
+	SecretKey string `json:"secretKey"`
+	// Whether to include default models. Default is true.
+	WithDefaultModels *bool `json:"withDefaultModels,omitempty"`
+}
+
+// healthHealth calls GET /api/public/health.
+func (c *Client) healthHealth(ctx context.Context) (*HealthResponse, error) {
+	path := "/api/public/health"
+	var out HealthResponse
+	if err := c.do(ctx, http.MethodGet, "/api/public/health", path, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// llmConnectionsList calls GET /api/public/llm-connections.
+//
+// Get all LLM connections in a project.
+//
+// Supported query parameters: page, limit.
+func (c *Client) llmConnectionsList(ctx context.Context, query url.Values) (*PaginatedLlmConnections, error) {
+	path := "/api/public/llm-connections"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	var out PaginatedLlmConnections
+	if err := c.do(ctx, http.MethodGet, "/api/public/llm-connections", path, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// llmConnectionsUpsert calls PUT /api/public/llm-connections.
+//
+// Create or update an LLM connection. The connection is upserted on provider.
+func (c *Client) llmConnectionsUpsert(ctx context.Context, body UpsertLlmConnectionRequest) (*LlmConnection, error) {
+	path := "/api/public/llm-connections"
+	var out LlmConnection
+	if err := c.do(ctx, http.MethodPut, "/api/public/llm-connections", path, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// modelsList calls GET /api/public/models.
+//
+// Get all models.
+//
+// Supported query parameters: page, limit.
+func (c *Client) modelsList(ctx context.Context, query url.Values) (*PaginatedModels, error) {
+	path := "/api/public/models"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	var out PaginatedModels
+	if err := c.do(ctx, http.MethodGet, "/api/public/models", path, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// modelsCreate calls POST /api/public/models.
+//
+// Create a model.
+func (c *Client) modelsCreate(ctx context.Context, body CreateModelRequest) (*Model, error) {
+	path := "/api/public/models"
+	var out Model
+	if err := c.do(ctx, http.MethodPost, "/api/public/models", path, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// modelsGet calls GET /api/public/models/{id}.
+//
+// Get a model.
+func (c *Client) modelsGet(ctx context.Context, id string) (*Model, error) {
+	path := "/api/public/models/" + url.PathEscape(id)
+	var out Model
+	if err := c.do(ctx, http.MethodGet, "/api/public/models/{id}", path, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// modelsDelete calls DELETE /api/public/models/{id}.
+//
+// Delete a model. Cannot delete models managed by Langfuse. You can create
+// your own definition with the same modelName to override the definition
+// though.
+func (c *Client) modelsDelete(ctx context.Context, id string) error {
+	path := "/api/public/models/" + url.PathEscape(id)
+	return c.do(ctx, http.MethodDelete, "/api/public/models/{id}", path, nil, nil)
+}
+
+// organizationsGetOrganizationProjects calls GET /api/public/organizations/projects.
+//
+// Get all projects for the organization (requires organization-scoped API
+// key).
+func (c *Client) organizationsGetOrganizationProjects(ctx context.Context) (*OrganizationProjectsResponse, error) {
+	path := "/api/public/organizations/projects"
+	var out OrganizationProjectsResponse
+	if err := c.do(ctx, http.MethodGet, "/api/public/organizations/projects", path, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// projectsGet calls GET /api/public/projects.
+//
+// Get Project associated with API key.
+func (c *Client) projectsGet(ctx context.Context) (*Projects, error) {
+	path := "/api/public/projects"
+	var out Projects
+	if err := c.do(ctx, http.MethodGet, "/api/public/projects", path, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// projectsCreate calls POST /api/public/projects.
+//
+// Create a new project (requires organization-scoped API key).
+func (c *Client) projectsCreate(ctx context.Context, body CreateProjectRequest) (*Project, error) {
+	path := "/api/public/projects"
+	var out Project
+	if err := c.do(ctx, http.MethodPost, "/api/public/projects", path, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// projectsUpdate calls PUT /api/public/projects/{projectId}.
+//
+// Update a project by ID (requires organization-scoped API key).
+func (c *Client) projectsUpdate(ctx context.Context, projectID string, body UpdateProjectRequest) (*Project, error) {
+	path := "/api/public/projects/" + url.PathEscape(projectID)
+	var out Project
+	if err := c.do(ctx, http.MethodPut, "/api/public/projects/{projectId}", path, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// projectsDelete calls DELETE /api/public/projects/{projectId}.
+//
+// Delete a project by ID (requires organization-scoped API key). Project
+// deletion is processed asynchronously.
+func (c *Client) projectsDelete(ctx context.Context, projectID string) (*ProjectDeletionResponse, error) {
+	path := "/api/public/projects/" + url.PathEscape(projectID)
+	var out ProjectDeletionResponse
+	if err := c.do(ctx, http.MethodDelete, "/api/public/projects/{projectId}", path, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// projectsGetAPIKeys calls GET /api/public/projects/{projectId}/apiKeys.
+//
+// Get all API keys for a project (requires organization-scoped API key).
+func (c *Client) projectsGetAPIKeys(ctx context.Context, projectID string) (*APIKeyList, error) {
+	path := "/api/public/projects/" + url.PathEscape(projectID) + "/apiKeys"
+	var out APIKeyList
+	if err := c.do(ctx, http.MethodGet, "/api/public/projects/{projectId}/apiKeys", path, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// projectsCreateAPIKey calls POST /api/public/projects/{projectId}/apiKeys.
+//
+// Create a new API key for a project (requires organization-scoped API key).
+func (c *Client) projectsCreateAPIKey(ctx context.Context, projectID string, body CreateAPIKeyRequest) (*APIKey, error) {
+	path := "/api/public/projects/" + url.PathEscape(projectID) + "/apiKeys"
+	var out APIKey
+	if err := c.do(ctx, http.MethodPost, "/api/public/projects/{projectId}/apiKeys", path, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// projectsDeleteAPIKey calls DELETE /api/public/projects/{projectId}/apiKeys/{apiKeyId}.
+//
+// Delete an API key for a project (requires organization-scoped API key).
+func (c *Client) projectsDeleteAPIKey(ctx context.Context, projectID string, apiKeyID string) (*APIKeyDeletionResponse, error) {
+	path := "/api/public/projects/" + url.PathEscape(projectID) + "/apiKeys/" + url.PathEscape(apiKeyID)
+	var out APIKeyDeletionResponse
+	if err := c.do(ctx, http.MethodDelete, "/api/public/projects/{projectId}/apiKeys/{apiKeyId}", path, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// scoreConfigsGet calls GET /api/public/score-configs.
+//
+// Get all score configs.
+//
+// Supported query parameters: page, limit.
+func (c *Client) scoreConfigsGet(ctx context.Context, query url.Values) (*ScoreConfigs, error) {
+	path := "/api/public/score-configs"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	var out ScoreConfigs
+	if err := c.do(ctx, http.MethodGet, "/api/public/score-configs", path, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// scoreConfigsCreate calls POST /api/public/score-configs.
+//
+// Create a score configuration (config). Score configs are used to define the
+// structure of scores.
+func (c *Client) scoreConfigsCreate(ctx context.Context, body CreateScoreConfigRequest) (*ScoreConfig, error) {
+	path := "/api/public/score-configs"
+	var out ScoreConfig
+	if err := c.do(ctx, http.MethodPost, "/api/public/score-configs", path, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// scoreConfigsGetByID calls GET /api/public/score-configs/{configId}.
+//
+// Get a score config.
+func (c *Client) scoreConfigsGetByID(ctx context.Context, configID string) (*ScoreConfig, error) {
+	path := "/api/public/score-configs/" + url.PathEscape(configID)
+	var out ScoreConfig
+	if err := c.do(ctx, http.MethodGet, "/api/public/score-configs/{configId}", path, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// scoreConfigsUpdate calls PATCH /api/public/score-configs/{configId}.
+//
+// Update a score config.
+func (c *Client) scoreConfigsUpdate(ctx context.Context, configID string, body UpdateScoreConfigRequest) (*ScoreConfig, error) {
+	path := "/api/public/score-configs/" + url.PathEscape(configID)
+	var out ScoreConfig
+	if err := c.do(ctx, http.MethodPatch, "/api/public/score-configs/{configId}", path, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// promptsList calls GET /api/public/v2/prompts.
+//
+// Get a list of prompt names with versions and labels.
+//
+// Supported query parameters: name, label, tag, page, limit.
+func (c *Client) promptsList(ctx context.Context, query url.Values) (*PromptMetaListResponse, error) {
+	path := "/api/public/v2/prompts"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	var out PromptMetaListResponse
+	if err := c.do(ctx, http.MethodGet, "/api/public/v2/prompts", path, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// promptsCreate calls POST /api/public/v2/prompts.
+//
+// Create a new version for the prompt with the given `name`.
+func (c *Client) promptsCreate(ctx context.Context, body CreatePromptRequest) (*Prompt, error) {
+	path := "/api/public/v2/prompts"
+	var out Prompt
+	if err := c.do(ctx, http.MethodPost, "/api/public/v2/prompts", path, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// promptVersionUpdate calls PATCH /api/public/v2/prompts/{name}/versions/{version}.
+//
+// Update labels for a specific prompt version.
+func (c *Client) promptVersionUpdate(ctx context.Context, name string, version int, body UpdatePromptVersionRequest) (*Prompt, error) {
+	path := "/api/public/v2/prompts/" + url.PathEscape(name) + "/versions/" + strconv.Itoa(version)
+	var out Prompt
+	if err := c.do(ctx, http.MethodPatch, "/api/public/v2/prompts/{name}/versions/{version}", path, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// promptsGet calls GET /api/public/v2/prompts/{promptName}.
+//
+// Get a prompt.
+//
+// Supported query parameters: version, label.
+func (c *Client) promptsGet(ctx context.Context, promptName string, query url.Values) (*Prompt, error) {
+	path := "/api/public/v2/prompts/" + url.PathEscape(promptName)
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	var out Prompt
+	if err := c.do(ctx, http.MethodGet, "/api/public/v2/prompts/{promptName}", path, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// promptsDelete calls DELETE /api/public/v2/prompts/{promptName}.
+//
+// Delete prompt versions. If neither version nor label is specified, all
+// versions of the prompt are deleted.
+//
+// Supported query parameters: label, version.
+func (c *Client) promptsDelete(ctx context.Context, promptName string, query url.Values) error {
+	path := "/api/public/v2/prompts/" + url.PathEscape(promptName)
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	return c.do(ctx, http.MethodDelete, "/api/public/v2/prompts/{promptName}", path, nil, nil)
+}
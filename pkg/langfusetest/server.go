@@ -0,0 +1,650 @@
+// Package langfusetest provides a fake Langfuse HTTP server for tests.
+//
+// Server speaks the subset of the Langfuse public API that the operator
+// uses, over real HTTP with Basic authentication, so that test suites can
+// run the reconcile lifecycle against it instead of a live Langfuse. State
+// is kept in memory, can be seeded and inspected through the methods of
+// Server, and faults such as latency, 429s and 500s can be injected per
+// endpoint. The package only exposes its own types, so that it can be used
+// outside this module.
+//
+// Like Langfuse, the server accepts two kinds of keys: the organization key
+// it is created with, which manages projects, their API keys and models,
+// and project keys created through the API keys endpoint, which the
+// project-level endpoints (LLM connections, prompts and score configs)
+// derive their project from.
+package langfusetest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sqaisar/langfuse-controller/internal/langfuse"
+	"github.com/sqaisar/langfuse-controller/internal/langfuse/fake"
+)
+
+const (
+	// DefaultPublicKey and DefaultSecretKey are the organization key of a
+	// server created by NewServer.
+	DefaultPublicKey = "pk-lf-test"
+	DefaultSecretKey = "sk-lf-test"
+	// DefaultVersion is the version reported by the health endpoint.
+	DefaultVersion = "3.0.0"
+)
+
+// Fault changes how the server answers matching requests.
+type Fault struct {
+	// Method and Path select the requests the fault applies to. An empty
+	// Method matches every method; Path is a prefix of the request path,
+	// such as "/api/public/projects", and empty matches every path.
+	Method string
+	Path   string
+	// Latency delays the response.
+	Latency time.Duration
+	// StatusCode, if set, is returned instead of handling the request.
+	StatusCode int
+	// RetryAfter is sent as the Retry-After header of fault responses.
+	RetryAfter time.Duration
+	// Times is how many requests the fault applies to before it is
+	// removed. Zero applies it until ClearFaults is called.
+	Times int
+}
+
+func (f *Fault) matches(r *http.Request) bool {
+	return (f.Method == "" || f.Method == r.Method) && strings.HasPrefix(r.URL.Path, f.Path)
+}
+
+// Request is a request received by the server.
+type Request struct {
+	Method string
+	Path   string
+	// PublicKey is the public key the request authenticated with, if any.
+	PublicKey string
+	// StatusCode is the status of the response.
+	StatusCode int
+}
+
+// Server is a fake Langfuse listening on a local port. The zero value is
+// not usable; call NewServer.
+type Server struct {
+	*httptest.Server
+
+	// PublicKey and SecretKey are the organization key the server accepts.
+	PublicKey string
+	SecretKey string
+	// Version is reported by the health endpoint.
+	Version string
+
+	store *fake.Client
+
+	mu       sync.Mutex
+	latency  time.Duration
+	faults   []*Fault
+	disabled map[Capability]bool
+	requests []Request
+}
+
+// NewServer starts a fake Langfuse with an empty organization. The caller
+// must call Close when done.
+func NewServer() *Server {
+	s := &Server{
+		PublicKey: DefaultPublicKey,
+		SecretKey: DefaultSecretKey,
+		Version:   DefaultVersion,
+		store:     fake.NewClient(),
+		disabled:  map[Capability]bool{},
+	}
+	s.Server = httptest.NewServer(s.routes())
+	s.store.BaseURL = s.URL
+	return s
+}
+
+// SetLatency delays every response by d.
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = d
+}
+
+// InjectFault adds a fault. Faults are evaluated in the order they were
+// added and the first match applies.
+func (s *Server) InjectFault(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &f)
+}
+
+// ClearFaults removes all faults and the latency set by SetLatency.
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+	s.latency = 0
+}
+
+// Disable makes the endpoints of capability answer 404, like a Langfuse
+// version that does not have them.
+func (s *Server) Disable(capability Capability) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.disabled[capability] = true
+}
+
+// Requests returns the requests received so far, oldest first.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// RequestCount returns how many requests with method were received for
+// paths starting with path.
+func (s *Server) RequestCount(method, path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for _, r := range s.requests {
+		if r.Method == method && strings.HasPrefix(r.Path, path) {
+			n++
+		}
+	}
+	return n
+}
+
+// scope is what a request's key gives access to: the organization, or a
+// single project.
+type scope struct {
+	publicKey string
+	projectID string
+}
+
+func (sc scope) organization() bool {
+	return sc.projectID == ""
+}
+
+// access says which keys an endpoint accepts.
+type access int
+
+const (
+	public access = iota
+	organizationKey
+	projectKey
+	anyKey
+)
+
+type handlerFunc func(w http.ResponseWriter, r *http.Request, sc scope)
+
+func (s *Server) routes() http.Handler {
+	mux := http.NewServeMux()
+	handle := func(pattern string, capability Capability, acc access, h handlerFunc) {
+		mux.Handle(pattern, s.wrap(capability, acc, h))
+	}
+
+	handle("GET /api/public/health", "", public, s.health)
+
+	handle("GET /api/public/projects", "", projectKey, s.getProjects)
+	handle("POST /api/public/projects", CapabilityOrganizations, organizationKey, s.createProject)
+	handle("PUT /api/public/projects/{projectId}", CapabilityOrganizations, organizationKey, s.updateProject)
+	handle("DELETE /api/public/projects/{projectId}", CapabilityOrganizations, organizationKey, s.deleteProject)
+	handle("GET /api/public/organizations/projects", CapabilityOrganizations, organizationKey, s.listProjects)
+	handle("GET /api/public/projects/{projectId}/apiKeys", CapabilityOrganizations, organizationKey, s.listAPIKeys)
+	handle("POST /api/public/projects/{projectId}/apiKeys", CapabilityOrganizations, organizationKey, s.createAPIKey)
+	handle("DELETE /api/public/projects/{projectId}/apiKeys/{apiKeyId}", CapabilityOrganizations,
+		organizationKey, s.deleteAPIKey)
+
+	// Models are global in the fake, so either kind of key may manage them.
+	handle("GET /api/public/models", CapabilityModels, anyKey, s.listModels)
+	handle("POST /api/public/models", CapabilityModels, anyKey, s.createModel)
+	handle("GET /api/public/models/{id}", CapabilityModels, anyKey, s.getModel)
+	handle("DELETE /api/public/models/{id}", CapabilityModels, anyKey, s.deleteModel)
+
+	handle("GET /api/public/llm-connections", CapabilityLlmConnections, projectKey, s.listLlmConnections)
+	handle("PUT /api/public/llm-connections", CapabilityLlmConnections, projectKey, s.upsertLlmConnection)
+
+	handle("GET /api/public/v2/prompts", CapabilityPrompts, projectKey, s.listPrompts)
+	handle("POST /api/public/v2/prompts", CapabilityPrompts, projectKey, s.createPrompt)
+	handle("GET /api/public/v2/prompts/{promptName}", CapabilityPrompts, projectKey, s.getPrompt)
+	handle("DELETE /api/public/v2/prompts/{promptName}", CapabilityPrompts, projectKey, s.deletePrompt)
+	handle("PATCH /api/public/v2/prompts/{promptName}/versions/{version}", CapabilityPrompts,
+		projectKey, s.updatePromptLabels)
+
+	handle("GET /api/public/score-configs", CapabilityScoreConfigs, projectKey, s.listScoreConfigs)
+	handle("POST /api/public/score-configs", CapabilityScoreConfigs, projectKey, s.createScoreConfig)
+	handle("GET /api/public/score-configs/{configId}", CapabilityScoreConfigs, projectKey, s.getScoreConfig)
+	handle("PATCH /api/public/score-configs/{configId}", CapabilityScoreConfigs,
+		projectKey, s.updateScoreConfig)
+	return mux
+}
+
+// wrap applies latency, faults, capabilities and authentication before
+// calling h, and records the request.
+func (s *Server) wrap(capability Capability, acc access, h handlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		sc := s.authenticate(r)
+		defer func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			s.requests = append(s.requests, Request{
+				Method: r.Method, Path: r.URL.Path, PublicKey: sc.publicKey, StatusCode: rec.status,
+			})
+		}()
+
+		fault, latency, disabled := s.plan(r, capability)
+		select {
+		case <-time.After(latency):
+		case <-r.Context().Done():
+			return
+		}
+		switch {
+		case fault != nil:
+			if fault.RetryAfter > 0 {
+				rec.Header().Set("Retry-After", strconv.Itoa(int((fault.RetryAfter+time.Second-1)/time.Second)))
+			}
+			writeMessage(rec, fault.StatusCode, http.StatusText(fault.StatusCode))
+		case disabled:
+			writeMessage(rec, http.StatusNotFound, "Not Found")
+		case acc == public:
+			h(rec, r, sc)
+		case sc.publicKey == "":
+			writeMessage(rec, http.StatusUnauthorized, "Invalid credentials")
+		case acc == organizationKey && !sc.organization():
+			writeMessage(rec, http.StatusForbidden, "This endpoint requires an organization API key")
+		case acc == projectKey && sc.organization():
+			writeMessage(rec, http.StatusForbidden, "This endpoint requires a project API key")
+		default:
+			h(rec, r, sc)
+		}
+	})
+}
+
+// plan returns the fault that applies to r, if any, the latency to add
+// and whether capability is disabled.
+func (s *Server) plan(r *http.Request, capability Capability) (*Fault, time.Duration, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	latency := s.latency
+	for i, f := range s.faults {
+		if !f.matches(r) {
+			continue
+		}
+		latency += f.Latency
+		if f.Times > 0 {
+			if f.Times--; f.Times == 0 {
+				s.faults = append(s.faults[:i:i], s.faults[i+1:]...)
+			}
+		}
+		if f.StatusCode == 0 {
+			// A latency-only fault lets the request through.
+			return nil, latency, s.disabled[capability]
+		}
+		return f, latency, false
+	}
+	return nil, latency, s.disabled[capability]
+}
+
+// authenticate returns the scope of the request's Basic credentials, or a
+// zero scope if they are missing or unknown.
+func (s *Server) authenticate(r *http.Request) scope {
+	user, password, ok := r.BasicAuth()
+	if !ok {
+		return scope{}
+	}
+	if user == s.PublicKey && password == s.SecretKey {
+		return scope{publicKey: user}
+	}
+	for _, p := range s.store.Projects() {
+		for _, key := range s.store.APIKeys(p.ID) {
+			if key.PublicKey == user && key.SecretKey == password {
+				return scope{publicKey: user, projectID: p.ID}
+			}
+		}
+	}
+	return scope{}
+}
+
+func (s *Server) health(w http.ResponseWriter, _ *http.Request, _ scope) {
+	writeJSON(w, http.StatusOK, langfuse.HealthResponse{Status: "OK", Version: s.Version})
+}
+
+func (s *Server) getProjects(w http.ResponseWriter, r *http.Request, sc scope) {
+	p, err := s.store.GetProject(r.Context(), sc.projectID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, langfuse.Projects{Data: []langfuse.Project{*p}})
+}
+
+func (s *Server) createProject(w http.ResponseWriter, r *http.Request, _ scope) {
+	var req langfuse.CreateProjectRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	if req.Name == "" {
+		writeMessage(w, http.StatusBadRequest, "name is required")
+		return
+	}
+	p, err := s.store.CreateProject(r.Context(), req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, p)
+}
+
+func (s *Server) updateProject(w http.ResponseWriter, r *http.Request, _ scope) {
+	var req langfuse.UpdateProjectRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	p, err := s.store.UpdateProject(r.Context(), r.PathValue("projectId"), req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, p)
+}
+
+func (s *Server) deleteProject(w http.ResponseWriter, r *http.Request, _ scope) {
+	if err := s.store.DeleteProject(r.Context(), r.PathValue("projectId")); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, langfuse.ProjectDeletionResponse{Success: true, Message: "Project deleted"})
+}
+
+func (s *Server) listProjects(w http.ResponseWriter, r *http.Request, _ scope) {
+	projects, err := langfuse.Collect(s.store.ListProjects(r.Context()))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	resp := langfuse.OrganizationProjectsResponse{Projects: []langfuse.OrganizationProject{}}
+	for _, p := range projects {
+		resp.Projects = append(resp.Projects, langfuse.OrganizationProject{ID: p.ID, Name: p.Name, Metadata: p.Metadata})
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) listAPIKeys(w http.ResponseWriter, r *http.Request, _ scope) {
+	keys, err := langfuse.Collect(s.store.ListAPIKeys(r.Context(), r.PathValue("projectId")))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, langfuse.APIKeyList{APIKeys: nonNil(keys)})
+}
+
+func (s *Server) createAPIKey(w http.ResponseWriter, r *http.Request, _ scope) {
+	var req langfuse.CreateAPIKeyRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	key, err := s.store.CreateAPIKey(r.Context(), r.PathValue("projectId"), req.Note)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, key)
+}
+
+func (s *Server) deleteAPIKey(w http.ResponseWriter, r *http.Request, _ scope) {
+	if err := s.store.DeleteAPIKey(r.Context(), r.PathValue("projectId"), r.PathValue("apiKeyId")); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, langfuse.APIKeyDeletionResponse{Success: true})
+}
+
+func (s *Server) listModels(w http.ResponseWriter, r *http.Request, _ scope) {
+	models, err := langfuse.Collect(s.store.ListModels(r.Context(), langfuse.ListOptions{}))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	data, meta := paginate(r, models)
+	writeJSON(w, http.StatusOK, langfuse.PaginatedModels{Data: data, Meta: meta})
+}
+
+func (s *Server) createModel(w http.ResponseWriter, r *http.Request, _ scope) {
+	var req langfuse.CreateModelRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	m, err := s.store.CreateModel(r.Context(), req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, m)
+}
+
+func (s *Server) getModel(w http.ResponseWriter, r *http.Request, _ scope) {
+	m, err := s.store.GetModel(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, m)
+}
+
+func (s *Server) deleteModel(w http.ResponseWriter, r *http.Request, _ scope) {
+	if err := s.store.DeleteModel(r.Context(), r.PathValue("id")); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) listLlmConnections(w http.ResponseWriter, r *http.Request, sc scope) {
+	conns, err := langfuse.Collect(s.store.ListLlmConnections(r.Context(), sc.projectID, langfuse.ListOptions{}))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	data, meta := paginate(r, conns)
+	writeJSON(w, http.StatusOK, langfuse.PaginatedLlmConnections{Data: data, Meta: meta})
+}
+
+func (s *Server) upsertLlmConnection(w http.ResponseWriter, r *http.Request, sc scope) {
+	var req langfuse.UpsertLlmConnectionRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	conn, err := s.store.UpsertLlmConnection(r.Context(), sc.projectID, req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, conn)
+}
+
+func (s *Server) listPrompts(w http.ResponseWriter, r *http.Request, sc scope) {
+	prompts, err := langfuse.Collect(s.store.ListPrompts(r.Context(), sc.projectID, langfuse.ListOptions{}))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	data, meta := paginate(r, prompts)
+	writeJSON(w, http.StatusOK, langfuse.PromptMetaListResponse{Data: data, Meta: meta})
+}
+
+func (s *Server) createPrompt(w http.ResponseWriter, r *http.Request, sc scope) {
+	var req langfuse.CreatePromptRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	p, err := s.store.CreatePrompt(r.Context(), sc.projectID, req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, p)
+}
+
+func (s *Server) getPrompt(w http.ResponseWriter, r *http.Request, sc scope) {
+	opts := langfuse.GetPromptOptions{Label: r.URL.Query().Get("label")}
+	if v := r.URL.Query().Get("version"); v != "" {
+		version, err := strconv.Atoi(v)
+		if err != nil {
+			writeMessage(w, http.StatusBadRequest, "invalid version")
+			return
+		}
+		opts.Version = version
+	}
+	p, err := s.store.GetPrompt(r.Context(), sc.projectID, r.PathValue("promptName"), opts)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, p)
+}
+
+func (s *Server) deletePrompt(w http.ResponseWriter, r *http.Request, sc scope) {
+	if err := s.store.DeletePrompt(r.Context(), sc.projectID, r.PathValue("promptName")); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) updatePromptLabels(w http.ResponseWriter, r *http.Request, sc scope) {
+	version, err := strconv.Atoi(r.PathValue("version"))
+	if err != nil {
+		writeMessage(w, http.StatusBadRequest, "invalid version")
+		return
+	}
+	var req langfuse.UpdatePromptVersionRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	p, err := s.store.UpdatePromptLabels(r.Context(), sc.projectID, r.PathValue("promptName"), version, req.NewLabels)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, p)
+}
+
+func (s *Server) listScoreConfigs(w http.ResponseWriter, r *http.Request, sc scope) {
+	configs, err := langfuse.Collect(s.store.ListScoreConfigs(r.Context(), sc.projectID, langfuse.ListOptions{}))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	data, meta := paginate(r, configs)
+	writeJSON(w, http.StatusOK, langfuse.ScoreConfigs{Data: data, Meta: meta})
+}
+
+func (s *Server) createScoreConfig(w http.ResponseWriter, r *http.Request, sc scope) {
+	var req langfuse.CreateScoreConfigRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	config, err := s.store.CreateScoreConfig(r.Context(), sc.projectID, req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, config)
+}
+
+func (s *Server) getScoreConfig(w http.ResponseWriter, r *http.Request, sc scope) {
+	config, err := s.store.GetScoreConfig(r.Context(), sc.projectID, r.PathValue("configId"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, config)
+}
+
+func (s *Server) updateScoreConfig(w http.ResponseWriter, r *http.Request, sc scope) {
+	var req langfuse.UpdateScoreConfigRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	config, err := s.store.UpdateScoreConfig(r.Context(), sc.projectID, r.PathValue("configId"), req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, config)
+}
+
+// paginate returns the page of items selected by the page and limit query
+// parameters, which default to 1 and langfuse.DefaultPageSize.
+func paginate[T any](r *http.Request, items []T) ([]T, langfuse.PageMeta) {
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit < 1 {
+		limit = langfuse.DefaultPageSize
+	}
+	meta := langfuse.PageMeta{
+		Page:       page,
+		Limit:      limit,
+		TotalItems: len(items),
+		TotalPages: (len(items) + limit - 1) / limit,
+	}
+	start := min((page-1)*limit, len(items))
+	end := min(start+limit, len(items))
+	return nonNil(items[start:end]), meta
+}
+
+// nonNil returns an empty slice for nil, so that lists encode as [].
+func nonNil[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
+
+func readJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeMessage(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeMessage(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"message": message})
+}
+
+// writeError answers with the status and message of an error returned by
+// the store.
+func writeError(w http.ResponseWriter, err error) {
+	var apiErr *langfuse.APIError
+	if errors.As(err, &apiErr) {
+		writeMessage(w, apiErr.StatusCode, apiErr.Message)
+		return
+	}
+	writeMessage(w, http.StatusInternalServerError, err.Error())
+}
+
+// statusRecorder remembers the status code written to a response.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
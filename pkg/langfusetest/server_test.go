@@ -0,0 +1,226 @@
+package langfusetest
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/sqaisar/langfuse-controller/internal/langfuse"
+)
+
+// projectKeys hands the client the project keys created during a test.
+type projectKeys map[string]langfuse.Credentials
+
+func (k projectKeys) ProjectCredentials(_ context.Context, projectID string) (langfuse.Credentials, bool, error) {
+	creds, ok := k[projectID]
+	return creds, ok, nil
+}
+
+func (k projectKeys) ProjectCredentialsRejected(string) {}
+
+func newClient(s *Server, keys projectKeys) *langfuse.Client {
+	return langfuse.NewClient(langfuse.Options{
+		BaseURL:            s.URL,
+		PublicKey:          s.PublicKey,
+		SecretKey:          s.SecretKey,
+		RetryWaitMin:       time.Millisecond,
+		RetryWaitMax:       5 * time.Millisecond,
+		ProjectCredentials: keys,
+	})
+}
+
+func TestLifecycle(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	keys := projectKeys{}
+	c := newClient(s, keys)
+	ctx := context.Background()
+
+	project, err := c.CreateProject(ctx, langfuse.CreateProjectRequest{Name: "demo"})
+	if err != nil {
+		t.Fatalf("CreateProject: %v", err)
+	}
+	if _, err := c.CreateProject(ctx, langfuse.CreateProjectRequest{Name: "demo"}); !langfuse.IsConflict(err) {
+		t.Fatalf("duplicate CreateProject: got %v, want 409", err)
+	}
+
+	// Project endpoints refuse the organization key.
+	if _, err := c.CreatePrompt(ctx, project.ID, langfuse.CreatePromptRequest{Name: "greeting"}); langfuse.StatusCodeOf(err) != http.StatusForbidden {
+		t.Fatalf("CreatePrompt with organization key: got %v, want 403", err)
+	}
+
+	key, err := c.CreateAPIKey(ctx, project.ID, "ci")
+	if err != nil {
+		t.Fatalf("CreateAPIKey: %v", err)
+	}
+	keys[project.ID] = langfuse.Credentials{PublicKey: key.PublicKey, SecretKey: key.SecretKey}
+
+	for _, text := range []string{"Hello", "Hi"} {
+		if _, err := c.CreatePrompt(ctx, project.ID, langfuse.CreatePromptRequest{
+			Name: "greeting", Type: "text", Prompt: text, Labels: []string{"production"},
+		}); err != nil {
+			t.Fatalf("CreatePrompt: %v", err)
+		}
+	}
+	prompt, err := c.GetPrompt(ctx, project.ID, "greeting", langfuse.GetPromptOptions{Label: "production"})
+	if err != nil || prompt.Version != 2 || prompt.Prompt != "Hi" {
+		t.Fatalf("GetPrompt: got %+v, %v", prompt, err)
+	}
+	if _, err := c.UpdatePromptLabels(ctx, project.ID, "greeting", 1, []string{"production"}); err != nil {
+		t.Fatalf("UpdatePromptLabels: %v", err)
+	}
+	if versions := s.PromptVersions(project.ID, "greeting"); len(versions[0].Labels) != 1 {
+		t.Errorf("label not moved back to version 1: %+v", versions)
+	}
+
+	config, err := c.CreateScoreConfig(ctx, project.ID, langfuse.CreateScoreConfigRequest{Name: "accuracy", DataType: "NUMERIC"})
+	if err != nil {
+		t.Fatalf("CreateScoreConfig: %v", err)
+	}
+	if got, err := c.GetScoreConfig(ctx, project.ID, config.ID); err != nil || got.Name != "accuracy" {
+		t.Fatalf("GetScoreConfig: got %+v, %v", got, err)
+	}
+
+	if _, err := c.UpsertLlmConnection(ctx, project.ID, langfuse.UpsertLlmConnectionRequest{
+		Provider: "openai", Adapter: "openai", SecretKey: "sk-openai",
+	}); err != nil {
+		t.Fatalf("UpsertLlmConnection: %v", err)
+	}
+	conns, err := langfuse.Collect(c.ListLlmConnections(ctx, project.ID, langfuse.ListOptions{}))
+	if err != nil || len(conns) != 1 {
+		t.Fatalf("ListLlmConnections: got %+v, %v", conns, err)
+	}
+
+	if err := c.DeleteProject(ctx, project.ID); err != nil {
+		t.Fatalf("DeleteProject: %v", err)
+	}
+	if _, err := c.GetProject(ctx, project.ID); !langfuse.IsNotFound(err) {
+		t.Fatalf("GetProject after delete: got %v, want 404", err)
+	}
+}
+
+func TestPagination(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	c := newClient(s, nil)
+	ctx := context.Background()
+
+	for _, name := range []string{"a", "b", "c"} {
+		if _, err := c.CreateModel(ctx, langfuse.CreateModelRequest{ModelName: name, MatchPattern: name}); err != nil {
+			t.Fatalf("CreateModel: %v", err)
+		}
+	}
+	models, err := langfuse.Collect(c.ListModels(ctx, langfuse.ListOptions{Limit: 2}))
+	if err != nil || len(models) != 3 {
+		t.Fatalf("ListModels: got %d models, %v", len(models), err)
+	}
+	if n := s.RequestCount(http.MethodGet, "/api/public/models"); n != 2 {
+		t.Errorf("pages fetched: got %d, want 2", n)
+	}
+}
+
+func TestAuthentication(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	c := langfuse.NewClient(langfuse.Options{BaseURL: s.URL, PublicKey: "pk-wrong", SecretKey: "sk-wrong"})
+	ctx := context.Background()
+
+	if _, err := langfuse.Collect(c.ListModels(ctx, langfuse.ListOptions{})); !langfuse.IsUnauthorized(err) {
+		t.Fatalf("ListModels with wrong key: got %v, want 401", err)
+	}
+	if _, err := c.Health(ctx); err != nil {
+		t.Fatalf("Health needs no key: %v", err)
+	}
+	if reqs := s.Requests(); reqs[0].StatusCode != http.StatusUnauthorized {
+		t.Errorf("recorded status: got %d, want 401", reqs[0].StatusCode)
+	}
+}
+
+func TestFaults(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	c := newClient(s, nil)
+	ctx := context.Background()
+
+	s.InjectFault(Fault{Path: "/api/public/models", StatusCode: http.StatusTooManyRequests, Times: 2})
+	if _, err := langfuse.Collect(c.ListModels(ctx, langfuse.ListOptions{})); err != nil {
+		t.Fatalf("ListModels after two 429s: %v", err)
+	}
+	if n := s.RequestCount(http.MethodGet, "/api/public/models"); n != 3 {
+		t.Errorf("attempts: got %d, want 3", n)
+	}
+
+	s.InjectFault(Fault{Method: http.MethodPost, StatusCode: http.StatusInternalServerError})
+	if _, err := c.CreateModel(ctx, langfuse.CreateModelRequest{ModelName: "m", MatchPattern: "m"}); langfuse.StatusCodeOf(err) != http.StatusInternalServerError {
+		t.Fatalf("CreateModel: got %v, want 500", err)
+	}
+	if len(s.Models()) != 0 {
+		t.Error("a failed request changed the state")
+	}
+	s.ClearFaults()
+
+	s.SetLatency(50 * time.Millisecond)
+	shortCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if _, err := c.Health(shortCtx); err == nil {
+		t.Fatal("Health: expected a timeout")
+	}
+}
+
+func TestDisabledCapability(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.Disable(CapabilityLlmConnections)
+	c := newClient(s, nil)
+
+	caps, err := c.DetectCapabilities(context.Background())
+	if err != nil {
+		t.Fatalf("DetectCapabilities: %v", err)
+	}
+	if caps.Version != DefaultVersion {
+		t.Errorf("version: got %q, want %q", caps.Version, DefaultVersion)
+	}
+	if caps.Has(langfuse.CapabilityLlmConnections) || !caps.Has(langfuse.CapabilityPrompts) {
+		t.Errorf("unexpected capabilities %+v", caps.Supported)
+	}
+}
+
+func TestSeedAndInspect(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	project, err := s.AddProject("seeded")
+	if err != nil {
+		t.Fatalf("AddProject: %v", err)
+	}
+	key, err := s.AddAPIKey(project.ID, "ci")
+	if err != nil || key.SecretKey == "" {
+		t.Fatalf("AddAPIKey: got %+v, %v", key, err)
+	}
+	c := newClient(s, projectKeys{project.ID: {PublicKey: key.PublicKey, SecretKey: key.SecretKey}})
+	ctx := context.Background()
+
+	if _, err := c.UpsertLlmConnection(ctx, project.ID, langfuse.UpsertLlmConnectionRequest{
+		Provider: "openai", Adapter: "openai", SecretKey: "sk-openai",
+	}); err != nil {
+		t.Fatalf("UpsertLlmConnection: %v", err)
+	}
+	if conns := s.LlmConnections(project.ID); len(conns) != 1 || conns[0].SecretKey != "sk-openai" {
+		t.Errorf("LlmConnections: got %+v", conns)
+	}
+	if _, err := c.CreateScoreConfig(ctx, project.ID, langfuse.CreateScoreConfigRequest{
+		Name: "correctness", DataType: "CATEGORICAL",
+		Categories: []langfuse.ScoreConfigCategory{{Label: "wrong", Value: 0}, {Label: "right", Value: 1}},
+	}); err != nil {
+		t.Fatalf("CreateScoreConfig: %v", err)
+	}
+	if configs := s.ScoreConfigs(project.ID); len(configs) != 1 || len(configs[0].Categories) != 2 {
+		t.Errorf("ScoreConfigs: got %+v", configs)
+	}
+	if keys := s.APIKeys(project.ID); len(keys) != 1 || keys[0].PublicKey != key.PublicKey || keys[0].SecretKey != "" {
+		t.Errorf("APIKeys: got %+v", keys)
+	}
+	if projects := s.Projects(); len(projects) != 1 || projects[0].Name != "seeded" {
+		t.Errorf("Projects: got %+v", projects)
+	}
+}
@@ -0,0 +1,183 @@
+package langfusetest
+
+import (
+	"context"
+
+	"github.com/sqaisar/langfuse-controller/internal/langfuse"
+)
+
+// Capability is a group of endpoints that Disable can turn off.
+type Capability string
+
+// The capabilities of the server. They match the endpoints the operator
+// probes to find out what a Langfuse version supports.
+const (
+	CapabilityOrganizations  Capability = Capability(langfuse.CapabilityOrganizations)
+	CapabilityModels         Capability = Capability(langfuse.CapabilityModels)
+	CapabilityLlmConnections Capability = Capability(langfuse.CapabilityLlmConnections)
+	CapabilityPrompts        Capability = Capability(langfuse.CapabilityPrompts)
+	CapabilityScoreConfigs   Capability = Capability(langfuse.CapabilityScoreConfigs)
+)
+
+// The types below are snapshots of the objects the server keeps. Changing
+// them does not change the server.
+
+// Project is a project of the organization.
+type Project struct {
+	ID       string
+	Name     string
+	Metadata map[string]interface{}
+	// RetentionDays is nil if the project has no data retention.
+	RetentionDays *int
+}
+
+// APIKey is an API key of a project. SecretKey is only set on keys
+// returned by AddAPIKey, as Langfuse only shows it once.
+type APIKey struct {
+	ID        string
+	Note      string
+	PublicKey string
+	SecretKey string
+}
+
+// Model is a custom model definition of the organization.
+type Model struct {
+	ID           string
+	ModelName    string
+	MatchPattern string
+	Unit         string
+	InputPrice   *float64
+	OutputPrice  *float64
+	TotalPrice   *float64
+}
+
+// LlmConnection is the LLM connection of a provider in a project. SecretKey
+// is the key it was last upserted with, which the API never returns.
+type LlmConnection struct {
+	ID        string
+	Provider  string
+	Adapter   string
+	SecretKey string
+}
+
+// PromptVersion is a version of a prompt.
+type PromptVersion struct {
+	Name          string
+	Version       int
+	Labels        []string
+	CommitMessage string
+}
+
+// ScoreConfig is a score config of a project.
+type ScoreConfig struct {
+	ID          string
+	Name        string
+	DataType    string
+	Description string
+	IsArchived  bool
+	Categories  []string
+}
+
+// AddProject creates a project as if through the UI, for seeding.
+func (s *Server) AddProject(name string) (Project, error) {
+	p, err := s.store.CreateProject(context.Background(), langfuse.CreateProjectRequest{Name: name})
+	if err != nil {
+		return Project{}, err
+	}
+	return project(*p), nil
+}
+
+// AddAPIKey creates an API key of projectID as if through the UI. Its key
+// pair authenticates requests to the project-level endpoints.
+func (s *Server) AddAPIKey(projectID, note string) (APIKey, error) {
+	k, err := s.store.CreateAPIKey(context.Background(), projectID, note)
+	if err != nil {
+		return APIKey{}, err
+	}
+	return APIKey{ID: k.ID, Note: k.Note, PublicKey: k.PublicKey, SecretKey: k.SecretKey}, nil
+}
+
+// Projects returns all projects sorted by ID.
+func (s *Server) Projects() []Project {
+	var out []Project
+	for _, p := range s.store.Projects() {
+		out = append(out, project(p))
+	}
+	return out
+}
+
+// APIKeys returns the API keys of a project, oldest first.
+func (s *Server) APIKeys(projectID string) []APIKey {
+	var out []APIKey
+	for _, k := range s.store.APIKeys(projectID) {
+		out = append(out, APIKey{ID: k.ID, Note: k.Note, PublicKey: k.PublicKey})
+	}
+	return out
+}
+
+// Models returns all custom models sorted by ID.
+func (s *Server) Models() []Model {
+	var out []Model
+	for _, m := range s.store.Models() {
+		out = append(out, Model{
+			ID:           m.ID,
+			ModelName:    m.ModelName,
+			MatchPattern: m.MatchPattern,
+			Unit:         m.Unit,
+			InputPrice:   m.InputPrice,
+			OutputPrice:  m.OutputPrice,
+			TotalPrice:   m.TotalPrice,
+		})
+	}
+	return out
+}
+
+// LlmConnections returns the LLM connections of a project.
+func (s *Server) LlmConnections(projectID string) []LlmConnection {
+	var out []LlmConnection
+	for c, err := range s.store.ListLlmConnections(context.Background(), projectID, langfuse.ListOptions{}) {
+		if err != nil {
+			return nil
+		}
+		req, _ := s.store.LlmConnection(projectID, c.Provider)
+		out = append(out, LlmConnection{ID: c.ID, Provider: c.Provider, Adapter: c.Adapter, SecretKey: req.SecretKey})
+	}
+	return out
+}
+
+// PromptVersions returns all versions of a prompt, oldest first.
+func (s *Server) PromptVersions(projectID, name string) []PromptVersion {
+	var out []PromptVersion
+	for _, p := range s.store.PromptVersions(projectID, name) {
+		out = append(out, PromptVersion{
+			Name:          p.Name,
+			Version:       p.Version,
+			Labels:        p.Labels,
+			CommitMessage: p.CommitMessage,
+		})
+	}
+	return out
+}
+
+// ScoreConfigs returns the score configs of a project sorted by ID.
+func (s *Server) ScoreConfigs(projectID string) []ScoreConfig {
+	var out []ScoreConfig
+	for _, sc := range s.store.ScoreConfigs(projectID) {
+		config := ScoreConfig{
+			ID:          sc.ID,
+			Name:        sc.Name,
+			DataType:    sc.DataType,
+			Description: sc.Description,
+			IsArchived:  sc.IsArchived,
+		}
+		for _, c := range sc.Categories {
+			config.Categories = append(config.Categories, c.Label)
+		}
+		out = append(out, config)
+	}
+	return out
+}
+
+func project(p langfuse.Project) Project {
+	return Project{ID: p.ID, Name: p.Name, Metadata: p.Metadata, RetentionDays: p.RetentionDays}
+}